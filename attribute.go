@@ -0,0 +1,22 @@
+package loafergo
+
+import "encoding/base64"
+
+// MessageAttribute is a typed attribute attached to a message.
+type MessageAttribute struct {
+	// DataType is String, Number or Binary, optionally followed by a custom type label such as Number.float
+	DataType string
+	// StringValue holds the value of String and Number attributes
+	StringValue string
+	// BinaryValue holds the value of Binary attributes
+	BinaryValue []byte
+}
+
+// String returns the attribute value as a string.
+// Binary values are base64 encoded.
+func (a MessageAttribute) String() string {
+	if a.BinaryValue != nil {
+		return base64.StdEncoding.EncodeToString(a.BinaryValue)
+	}
+	return a.StringValue
+}
@@ -0,0 +1,14 @@
+package loafergo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+func TestMessageAttribute_String(t *testing.T) {
+	assert.Equal(t, "123", loafergo.MessageAttribute{DataType: "Number", StringValue: "123"}.String())
+	assert.Equal(t, "dHJhY2UtaWQ=", loafergo.MessageAttribute{DataType: "Binary", BinaryValue: []byte("trace-id")}.String())
+}
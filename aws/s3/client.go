@@ -0,0 +1,48 @@
+package s3
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	loaferAWS "github.com/justcodes/loafer-go/v2/aws"
+)
+
+// S3Client represents the aws s3 client methods used by the blob store
+type S3Client interface {
+	PutObject(ctx context.Context, params *awsS3.PutObjectInput, optFns ...func(*awsS3.Options)) (*awsS3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *awsS3.GetObjectInput, optFns ...func(*awsS3.Options)) (*awsS3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *awsS3.DeleteObjectInput, optFns ...func(*awsS3.Options)) (*awsS3.DeleteObjectOutput, error)
+}
+
+// NewClient instantiates a new s3 client to be used by the blob store.
+// When a hostname is set, path-style addressing is used, as expected by most S3-compatible stand-ins.
+func NewClient(ctx context.Context, cfg *loaferAWS.ClientConfig) (client S3Client, err error) {
+	cfg, err = loaferAWS.ValidateConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var c *aws.CredentialsCache
+	// Check if static credentials are provided
+	if cfg.Config.Key != "" && cfg.Config.Secret != "" {
+		// Use static credentials if provided
+		c = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(cfg.Config.Key, cfg.Config.Secret, ""))
+		_, err = c.Retrieve(ctx)
+		if err != nil {
+			return client, loafergo.ErrInvalidCreds.Context(err)
+		}
+	}
+
+	aCfg, err := loaferAWS.LoadAWSConfig(ctx, cfg, c)
+	if err != nil {
+		return nil, err
+	}
+	client = awsS3.NewFromConfig(aCfg, func(o *awsS3.Options) {
+		o.UsePathStyle = cfg.Config.Hostname != ""
+	})
+	return
+}
@@ -0,0 +1,88 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+// StoreConfig provides service configuration for an S3 blob store.
+type StoreConfig struct {
+	S3Client S3Client
+	Bucket   string
+	// Prefix is prepended to the blob keys, e.g. "loafer/claim-check"
+	Prefix string
+}
+
+// Store is a loafergo.BlobStore keeping the payloads as objects of an S3 bucket
+type Store struct {
+	s3     S3Client
+	bucket string
+	prefix string
+}
+
+var _ loafergo.BlobStore = (*Store)(nil)
+
+// NewStore creates a new S3 blob store, to be used as the BlobStore of the producers and routes
+func NewStore(config *StoreConfig) (*Store, error) {
+	if config == nil {
+		return nil, loafergo.ErrEmptyParam
+	}
+
+	if config.S3Client == nil || config.Bucket == "" {
+		return nil, loafergo.ErrEmptyRequiredField
+	}
+
+	return &Store{
+		s3:     config.S3Client,
+		bucket: config.Bucket,
+		prefix: config.Prefix,
+	}, nil
+}
+
+// Put uploads the payload as the object named key
+func (s *Store) Put(ctx context.Context, key string, data []byte) error {
+	_, err := s.s3.PutObject(ctx, &awsS3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(s.key(key)),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	return err
+}
+
+// Get downloads the payload of the object named key
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	output, err := s.s3.GetObject(ctx, &awsS3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = output.Body.Close() }()
+
+	return io.ReadAll(output.Body)
+}
+
+// Delete removes the object named key. S3 does not report deleting a missing object as an error.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	_, err := s.s3.DeleteObject(ctx, &awsS3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+	})
+	return err
+}
+
+func (s *Store) key(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return path.Join(s.prefix, key)
+}
@@ -0,0 +1,105 @@
+package s3_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	loaferAWS "github.com/justcodes/loafer-go/v2/aws"
+	"github.com/justcodes/loafer-go/v2/aws/s3"
+)
+
+// objectServer is a minimal S3-compatible stand-in, keeping the objects in memory by path
+type objectServer struct {
+	objects map[string][]byte
+	mu      sync.Mutex
+}
+
+func (s *objectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		s.objects[r.URL.Path] = body
+	case http.MethodGet:
+		body, ok := s.objects[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+			return
+		}
+		_, _ = w.Write(body)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	t.Run("With Config nil", func(t *testing.T) {
+		s, err := s3.NewStore(nil)
+		assert.Nil(t, s)
+		assert.ErrorIs(t, err, loafergo.ErrEmptyParam)
+	})
+
+	t.Run("Without bucket", func(t *testing.T) {
+		client, err := s3.NewClient(context.Background(), &loaferAWS.ClientConfig{
+			Config: &loaferAWS.Config{Region: "us-east-1"},
+		})
+		assert.NoError(t, err)
+
+		s, err := s3.NewStore(&s3.StoreConfig{S3Client: client})
+		assert.Nil(t, s)
+		assert.ErrorIs(t, err, loafergo.ErrEmptyRequiredField)
+	})
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	server := &objectServer{objects: map[string][]byte{}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := s3.NewClient(ctx, &loaferAWS.ClientConfig{
+		Config: &loaferAWS.Config{
+			Key:      "dummy",
+			Secret:   "dummy",
+			Region:   "us-east-1",
+			Hostname: ts.URL,
+		},
+		RetryCount: 1,
+	})
+	assert.NoError(t, err)
+
+	store, err := s3.NewStore(&s3.StoreConfig{S3Client: client, Bucket: "bucket", Prefix: "claim-check"})
+	assert.NoError(t, err)
+
+	t.Run("Should put, get and delete a payload", func(t *testing.T) {
+		assert.NoError(t, store.Put(ctx, "key-1", []byte("payload")))
+		assert.Equal(t, []byte("payload"), server.objects["/bucket/claim-check/key-1"])
+
+		got, err := store.Get(ctx, "key-1")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("payload"), got)
+
+		assert.NoError(t, store.Delete(ctx, "key-1"))
+		assert.Empty(t, server.objects)
+	})
+
+	t.Run("Should return error for a missing payload", func(t *testing.T) {
+		got, err := store.Get(ctx, "missing")
+		assert.Nil(t, got)
+		assert.ErrorContains(t, err, "NoSuchKey")
+	})
+}
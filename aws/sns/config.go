@@ -7,6 +7,18 @@ import (
 // A Config provides service configuration for an SNS producer.
 type Config struct {
 	SNSClient loafergo.SNSClient
+	// BlobStore enables the claim-check pattern: the messages larger than OffloadThreshold
+	// are stored in it and a pointer is published instead, see the claimcheck package.
+	// Every queue subscribed to the topic receives the same pointer, so the payloads must outlive
+	// the slowest subscriber: let them expire through a TTL or the lifecycle rules of the bucket,
+	// and do not configure the routes with claimcheck.DeleteOnCommit.
+	BlobStore loafergo.BlobStore
+	// OffloadThreshold is the message size, attributes included, above which the message is offloaded.
+	// Defaults to claimcheck.DefaultThreshold.
+	OffloadThreshold int
+	// Codec marshals the Payload of the inputs, the content-type and content-encoding attributes are sent along.
+	// Defaults to codec.JSON.
+	Codec loafergo.Codec
 }
 
 func validateConfig(c *Config) error {
@@ -105,6 +105,95 @@ func (_c *Producer_Produce_Call) RunAndReturn(run func(ctx context.Context, inpu
 	return _c
 }
 
+// ProduceAll provides a mock function for the type Producer
+func (_mock *Producer) ProduceAll(ctx context.Context, topicARN string, entries []*sns.PublishBatchEntry, optFns ...sns.LoadProduceAllConfigFunc) (*sns.PublishBatchOutput, error) {
+	var tmpRet mock.Arguments
+	if len(optFns) > 0 {
+		tmpRet = _mock.Called(ctx, topicARN, entries, optFns)
+	} else {
+		tmpRet = _mock.Called(ctx, topicARN, entries)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProduceAll")
+	}
+
+	var r0 *sns.PublishBatchOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []*sns.PublishBatchEntry, ...sns.LoadProduceAllConfigFunc) (*sns.PublishBatchOutput, error)); ok {
+		return returnFunc(ctx, topicARN, entries, optFns...)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []*sns.PublishBatchEntry, ...sns.LoadProduceAllConfigFunc) *sns.PublishBatchOutput); ok {
+		r0 = returnFunc(ctx, topicARN, entries, optFns...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sns.PublishBatchOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, []*sns.PublishBatchEntry, ...sns.LoadProduceAllConfigFunc) error); ok {
+		r1 = returnFunc(ctx, topicARN, entries, optFns...)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// Producer_ProduceAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProduceAll'
+type Producer_ProduceAll_Call struct {
+	*mock.Call
+}
+
+// ProduceAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - topicARN string
+//   - entries []*sns.PublishBatchEntry
+//   - optFns ...sns.LoadProduceAllConfigFunc
+func (_e *Producer_Expecter) ProduceAll(ctx interface{}, topicARN interface{}, entries interface{}, optFns ...interface{}) *Producer_ProduceAll_Call {
+	return &Producer_ProduceAll_Call{Call: _e.mock.On("ProduceAll",
+		append([]interface{}{ctx, topicARN, entries}, optFns...)...)}
+}
+
+func (_c *Producer_ProduceAll_Call) Run(run func(ctx context.Context, topicARN string, entries []*sns.PublishBatchEntry, optFns ...sns.LoadProduceAllConfigFunc)) *Producer_ProduceAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 []*sns.PublishBatchEntry
+		if args[2] != nil {
+			arg2 = args[2].([]*sns.PublishBatchEntry)
+		}
+		var arg3 []sns.LoadProduceAllConfigFunc
+		var variadicArgs []sns.LoadProduceAllConfigFunc
+		if len(args) > 3 {
+			variadicArgs = args[3].([]sns.LoadProduceAllConfigFunc)
+		}
+		arg3 = variadicArgs
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3...,
+		)
+	})
+	return _c
+}
+
+func (_c *Producer_ProduceAll_Call) Return(publishBatchOutput *sns.PublishBatchOutput, err error) *Producer_ProduceAll_Call {
+	_c.Call.Return(publishBatchOutput, err)
+	return _c
+}
+
+func (_c *Producer_ProduceAll_Call) RunAndReturn(run func(ctx context.Context, topicARN string, entries []*sns.PublishBatchEntry, optFns ...sns.LoadProduceAllConfigFunc) (*sns.PublishBatchOutput, error)) *Producer_ProduceAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ProduceBatch provides a mock function for the type Producer
 func (_mock *Producer) ProduceBatch(ctx context.Context, input *sns.PublishBatchInput) (*sns.PublishBatchOutput, error) {
 	ret := _mock.Called(ctx, input)
@@ -172,3 +261,69 @@ func (_c *Producer_ProduceBatch_Call) RunAndReturn(run func(ctx context.Context,
 	_c.Call.Return(run)
 	return _c
 }
+
+// ProduceEvent provides a mock function for the type Producer
+func (_mock *Producer) ProduceEvent(ctx context.Context, input *sns.PublishEventInput) (string, error) {
+	ret := _mock.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProduceEvent")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sns.PublishEventInput) (string, error)); ok {
+		return returnFunc(ctx, input)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sns.PublishEventInput) string); ok {
+		r0 = returnFunc(ctx, input)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sns.PublishEventInput) error); ok {
+		r1 = returnFunc(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// Producer_ProduceEvent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProduceEvent'
+type Producer_ProduceEvent_Call struct {
+	*mock.Call
+}
+
+// ProduceEvent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - input *sns.PublishEventInput
+func (_e *Producer_Expecter) ProduceEvent(ctx interface{}, input interface{}) *Producer_ProduceEvent_Call {
+	return &Producer_ProduceEvent_Call{Call: _e.mock.On("ProduceEvent", ctx, input)}
+}
+
+func (_c *Producer_ProduceEvent_Call) Run(run func(ctx context.Context, input *sns.PublishEventInput)) *Producer_ProduceEvent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sns.PublishEventInput
+		if args[1] != nil {
+			arg1 = args[1].(*sns.PublishEventInput)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Producer_ProduceEvent_Call) Return(s string, err error) *Producer_ProduceEvent_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *Producer_ProduceEvent_Call) RunAndReturn(run func(ctx context.Context, input *sns.PublishEventInput) (string, error)) *Producer_ProduceEvent_Call {
+	_c.Call.Return(run)
+	return _c
+}
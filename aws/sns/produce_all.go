@@ -0,0 +1,284 @@
+package sns
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+)
+
+const (
+	// MaxBatchPayloadSize is the maximum aggregate payload size of a batch publish, 256 KiB
+	MaxBatchPayloadSize = 256 * 1024
+
+	defaultProduceAllConcurrency = 4
+	defaultProduceAllMaxAttempts = 3
+	defaultProduceAllBackoffBase = 100 * time.Millisecond
+	defaultProduceAllBackoffMax  = 5 * time.Second
+)
+
+// ProduceAllConfig are a discrete set of options that are valid for ProduceAll
+type ProduceAllConfig struct {
+	concurrency int
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+}
+
+func loadDefaultProduceAllConfig() *ProduceAllConfig {
+	return &ProduceAllConfig{
+		concurrency: defaultProduceAllConcurrency,
+		maxAttempts: defaultProduceAllMaxAttempts,
+		backoffBase: defaultProduceAllBackoffBase,
+		backoffMax:  defaultProduceAllBackoffMax,
+	}
+}
+
+// LoadProduceAllConfigFunc is a type alias for ProduceAllConfig functional config
+type LoadProduceAllConfigFunc func(config *ProduceAllConfig)
+
+// ProduceAllWithConcurrency sets the maximum number of batches published at the same time.
+// The default is 4, values lower than 1 are ignored.
+func ProduceAllWithConcurrency(n int) LoadProduceAllConfigFunc {
+	return func(c *ProduceAllConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// ProduceAllWithMaxAttempts sets how many times an entry is published before it is reported as failed.
+// The default is 3, values lower than 1 are ignored.
+func ProduceAllWithMaxAttempts(n int) LoadProduceAllConfigFunc {
+	return func(c *ProduceAllConfig) {
+		if n > 0 {
+			c.maxAttempts = n
+		}
+	}
+}
+
+// ProduceAllWithBackoff sets the delay before retrying the failed entries of a batch.
+// It doubles at each attempt, starting from base and capped at maxDelay, with jitter.
+// The default is 100ms up to 5s.
+func ProduceAllWithBackoff(base, maxDelay time.Duration) LoadProduceAllConfigFunc {
+	return func(c *ProduceAllConfig) {
+		c.backoffBase = base
+		c.backoffMax = maxDelay
+	}
+}
+
+func (c *ProduceAllConfig) backoff(attempt int) time.Duration {
+	d := c.backoffBase << (attempt - 1)
+	if d <= 0 || d > c.backoffMax {
+		d = c.backoffMax
+	}
+	if d <= 0 {
+		return 0
+	}
+	// equal jitter, so the retried batches do not hit the topic at the same time
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// ProduceAll publishes any number of entries to an Amazon SNS topic.
+//
+// The entries are split into batches respecting both the 10 entries and the 256 KiB payload limits,
+// and the batches are published concurrently. The entries that failed for a reason other than
+// the request itself (SenderFault) are retried with backoff, as are the batches whose request failed
+// with an error the SDK retryer deems retryable, e.g. throttled. The result of every entry is
+// combined in the output, in the order of the entries.
+//
+// Entries without an ID are given their index as ID, the IDs must be unique.
+// An entry larger than 256 KiB on its own fails without being published,
+// unless the producer has a BlobStore to offload it.
+//
+// Batches published concurrently and retried entries can be delivered out of order,
+// use ProduceAllWithConcurrency(1) and ProduceAllWithMaxAttempts(1) when the order of a FIFO topic matters.
+func (p *producer) ProduceAll(
+	ctx context.Context,
+	topicARN string,
+	entries []*PublishBatchEntry,
+	optFns ...LoadProduceAllConfigFunc,
+) (*PublishBatchOutput, error) {
+	if topicARN == "" || len(entries) == 0 {
+		return nil, loafergo.ErrEmptyInput
+	}
+
+	cfg := loadDefaultProduceAllConfig()
+	for _, optFn := range optFns {
+		optFn(cfg)
+	}
+
+	entries, order, err := indexEntries(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	// encode and offload the payloads first, so the batches are split by their actual size
+	for _, entry := range entries {
+		entry.Message, entry.Attributes, err = p.encode(ctx, entry.Message, entry.Payload, entry.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		entry.Payload = nil
+	}
+
+	batches, oversized := chunkEntries(entries)
+	output := &PublishBatchOutput{Failed: oversized}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, cfg.concurrency)
+publish:
+	for i, batch := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			// the batches left are not published, their entries fail with the ctx error
+			mu.Lock()
+			for _, entry := range slices.Concat(batches[i:]...) {
+				output.Failed = append(output.Failed, &PublishBatchEntryFailed{EntryID: entry.ID, Err: ctx.Err()})
+			}
+			mu.Unlock()
+			break publish
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+			result := p.publishWithRetry(ctx, topicARN, batch, cfg)
+
+			mu.Lock()
+			defer mu.Unlock()
+			output.Successful = append(output.Successful, result.Successful...)
+			output.Failed = append(output.Failed, result.Failed...)
+		})
+	}
+	wg.Wait()
+
+	slices.SortFunc(output.Successful, func(a, b *PublishBatchEntrySuccessful) int {
+		return order[a.EntryID] - order[b.EntryID]
+	})
+	slices.SortFunc(output.Failed, func(a, b *PublishBatchEntryFailed) int {
+		return order[a.EntryID] - order[b.EntryID]
+	})
+	return output, nil
+}
+
+// publishWithRetry publishes the batch and retries its retryable failed entries
+func (p *producer) publishWithRetry(
+	ctx context.Context,
+	topicARN string,
+	batch []*PublishBatchEntry,
+	cfg *ProduceAllConfig,
+) *PublishBatchOutput {
+	output := &PublishBatchOutput{}
+	pending := batch
+
+	for attempt := 1; ; attempt++ {
+		var retryEntries []*PublishBatchEntry
+		var retryFailed []*PublishBatchEntryFailed
+
+		result, err := p.ProduceBatch(ctx, &PublishBatchInput{TopicARN: topicARN, Messages: pending})
+		if err != nil {
+			// the whole request failed, every entry is retried when the error is transient, e.g. throttled
+			if retryable(err) {
+				retryEntries = pending
+			}
+			for _, entry := range pending {
+				retryFailed = append(retryFailed, &PublishBatchEntryFailed{EntryID: entry.ID, Err: err})
+			}
+		} else {
+			output.Successful = append(output.Successful, result.Successful...)
+			byID := make(map[string]*PublishBatchEntry, len(pending))
+			for _, entry := range pending {
+				byID[entry.ID] = entry
+			}
+			for _, failed := range result.Failed {
+				if failed.SenderFault {
+					output.Failed = append(output.Failed, failed)
+					continue
+				}
+				retryEntries = append(retryEntries, byID[failed.EntryID])
+				retryFailed = append(retryFailed, failed)
+			}
+		}
+
+		if len(retryEntries) == 0 || attempt >= cfg.maxAttempts {
+			output.Failed = append(output.Failed, retryFailed...)
+			return output
+		}
+
+		select {
+		case <-ctx.Done():
+			output.Failed = append(output.Failed, retryFailed...)
+			return output
+		case <-time.After(cfg.backoff(attempt)):
+		}
+		pending = retryEntries
+	}
+}
+
+// retryable reports whether the SDK retryer would retry the request error
+func retryable(err error) bool {
+	return retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// indexEntries copies the entries, giving their index as ID to the ones without,
+// and returns the position of each ID
+func indexEntries(entries []*PublishBatchEntry) ([]*PublishBatchEntry, map[string]int, error) {
+	indexed := make([]*PublishBatchEntry, len(entries))
+	order := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		e := *entry
+		if e.ID == "" {
+			e.ID = strconv.Itoa(i)
+		}
+		if _, ok := order[e.ID]; ok {
+			return nil, nil, fmt.Errorf("duplicate entry id: %s", e.ID)
+		}
+		order[e.ID] = i
+		indexed[i] = &e
+	}
+	return indexed, order, nil
+}
+
+// chunkEntries splits the entries into valid batches, keeping their order.
+// The entries too large to be published are returned as failed.
+func chunkEntries(entries []*PublishBatchEntry) ([][]*PublishBatchEntry, []*PublishBatchEntryFailed) {
+	var batches [][]*PublishBatchEntry
+	var oversized []*PublishBatchEntryFailed
+	var batch []*PublishBatchEntry
+	var batchSize int
+
+	for _, entry := range entries {
+		size := claimcheck.Size(entry.Message, entry.Attributes)
+		if size > MaxBatchPayloadSize {
+			oversized = append(oversized, &PublishBatchEntryFailed{
+				EntryID:     entry.ID,
+				SenderFault: true,
+				Err:         fmt.Errorf("failed to publish message; error: payload of %d bytes exceeds %d bytes", size, MaxBatchPayloadSize),
+			})
+			continue
+		}
+
+		if len(batch) == DefaultMaxBatchSize || batchSize+size > MaxBatchPayloadSize {
+			batches = append(batches, batch)
+			batch, batchSize = nil, 0
+		}
+		batch = append(batch, entry)
+		batchSize += size
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches, oversized
+}
@@ -0,0 +1,264 @@
+package sns_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSNS "github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/aws/sns"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+const produceAllTopic = "arn:aws:sns:us-east-1:0000000:my_topic"
+
+// publishBatchRecorder fakes PublishBatch, failing the entries returned by fail for the given attempt
+type publishBatchRecorder struct {
+	fail     func(id string, attempt int) *types.BatchResultErrorEntry
+	attempts map[string]int
+	batches  [][]string
+	mu       sync.Mutex
+}
+
+func (r *publishBatchRecorder) publishBatch(
+	_ context.Context,
+	in *awsSNS.PublishBatchInput,
+	_ ...func(*awsSNS.Options),
+) (*awsSNS.PublishBatchOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.attempts == nil {
+		r.attempts = map[string]int{}
+	}
+
+	var ids []string
+	output := &awsSNS.PublishBatchOutput{}
+	for _, e := range in.PublishBatchRequestEntries {
+		id := aws.ToString(e.Id)
+		ids = append(ids, id)
+		r.attempts[id]++
+		if r.fail != nil {
+			if failed := r.fail(id, r.attempts[id]); failed != nil {
+				failed.Id = e.Id
+				output.Failed = append(output.Failed, *failed)
+				continue
+			}
+		}
+		output.Successful = append(output.Successful, types.PublishBatchResultEntry{Id: e.Id, MessageId: aws.String("message-" + id)})
+	}
+	r.batches = append(r.batches, ids)
+	return output, nil
+}
+
+func newEntries(n int) []*sns.PublishBatchEntry {
+	entries := make([]*sns.PublishBatchEntry, n)
+	for i := range entries {
+		entries[i] = &sns.PublishBatchEntry{ID: fmt.Sprintf("id%02d", i), Message: "my message"}
+	}
+	return entries
+}
+
+func TestProducer_ProduceAll(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should split the entries into batches of 10", func(t *testing.T) {
+		client := fake.NewSNSClient(t)
+		producer, _ := sns.NewProducer(&sns.Config{SNSClient: client})
+		recorder := &publishBatchRecorder{}
+		client.On("PublishBatch", ctx, mock.Anything).Return(recorder.publishBatch).Times(3)
+
+		got, err := producer.ProduceAll(ctx, produceAllTopic, newEntries(25))
+		assert.NoError(t, err)
+		assert.Empty(t, got.Failed)
+		assert.Len(t, got.Successful, 25)
+		for i, s := range got.Successful {
+			assert.Equal(t, fmt.Sprintf("id%02d", i), s.EntryID, "the output keeps the order of the entries")
+			assert.Equal(t, "message-"+s.EntryID, s.MessageID)
+		}
+
+		var sizes []int
+		for _, batch := range recorder.batches {
+			sizes = append(sizes, len(batch))
+		}
+		assert.ElementsMatch(t, []int{10, 10, 5}, sizes)
+	})
+
+	t.Run("Should split the entries by payload size", func(t *testing.T) {
+		client := fake.NewSNSClient(t)
+		producer, _ := sns.NewProducer(&sns.Config{SNSClient: client})
+		recorder := &publishBatchRecorder{}
+		client.On("PublishBatch", ctx, mock.Anything).Return(recorder.publishBatch).Times(3)
+
+		entries := newEntries(5)
+		for _, e := range entries {
+			e.Message = strings.Repeat("a", 100*1024)
+		}
+		entries = append(entries, &sns.PublishBatchEntry{ID: "too-large", Message: strings.Repeat("a", sns.MaxBatchPayloadSize+1)})
+
+		got, err := producer.ProduceAll(ctx, produceAllTopic, entries, sns.ProduceAllWithConcurrency(1))
+		assert.NoError(t, err)
+		assert.Len(t, got.Successful, 5)
+		assert.Equal(t, [][]string{{"id00", "id01"}, {"id02", "id03"}, {"id04"}}, recorder.batches)
+		assert.Len(t, got.Failed, 1)
+		assert.Equal(t, "too-large", got.Failed[0].EntryID)
+		assert.True(t, got.Failed[0].SenderFault)
+	})
+
+	t.Run("Should offload the oversized entries to the blob store", func(t *testing.T) {
+		client := fake.NewSNSClient(t)
+		store, err := claimcheck.NewFileStore(t.TempDir())
+		assert.NoError(t, err)
+		producer, _ := sns.NewProducer(&sns.Config{SNSClient: client, BlobStore: store})
+		recorder := &publishBatchRecorder{}
+		client.On("PublishBatch", ctx, mock.MatchedBy(func(in *awsSNS.PublishBatchInput) bool {
+			return len(in.PublishBatchRequestEntries) == 2 &&
+				in.PublishBatchRequestEntries[1].MessageAttributes[claimcheck.AttributeKey].StringValue != nil
+		})).Return(recorder.publishBatch).Once()
+
+		large := strings.Repeat("a", sns.MaxBatchPayloadSize+1)
+		entries := []*sns.PublishBatchEntry{{Message: "my message"}, {Message: large}}
+
+		got, err := producer.ProduceAll(ctx, produceAllTopic, entries)
+		assert.NoError(t, err)
+		assert.Empty(t, got.Failed)
+		assert.Len(t, got.Successful, 2)
+		assert.Equal(t, large, entries[1].Message, "the entries are not modified")
+	})
+
+	t.Run("Should retry the retryable failed entries only", func(t *testing.T) {
+		client := fake.NewSNSClient(t)
+		producer, _ := sns.NewProducer(&sns.Config{SNSClient: client})
+		recorder := &publishBatchRecorder{
+			fail: func(id string, attempt int) *types.BatchResultErrorEntry {
+				switch {
+				case id == "id01" && attempt == 1:
+					return &types.BatchResultErrorEntry{Code: aws.String("InternalError"), Message: aws.String("try again")}
+				case id == "id02":
+					return &types.BatchResultErrorEntry{Code: aws.String("InvalidParameter"), Message: aws.String("invalid"), SenderFault: true}
+				}
+				return nil
+			},
+		}
+		client.On("PublishBatch", ctx, mock.Anything).Return(recorder.publishBatch).Twice()
+
+		got, err := producer.ProduceAll(ctx, produceAllTopic, newEntries(3), sns.ProduceAllWithBackoff(time.Millisecond, time.Millisecond))
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"id00", "id01", "id02"}, {"id01"}}, recorder.batches)
+
+		assert.Len(t, got.Successful, 2)
+		assert.Equal(t, "id00", got.Successful[0].EntryID)
+		assert.Equal(t, "id01", got.Successful[1].EntryID)
+		assert.Len(t, got.Failed, 1)
+		assert.Equal(t, "id02", got.Failed[0].EntryID)
+		assert.Equal(t, "InvalidParameter", got.Failed[0].Code)
+		assert.ErrorContains(t, got.Failed[0].Err, "invalid")
+	})
+
+	t.Run("Should give up after the max attempts", func(t *testing.T) {
+		client := fake.NewSNSClient(t)
+		producer, _ := sns.NewProducer(&sns.Config{SNSClient: client})
+		throttled := &smithy.GenericAPIError{Code: "Throttling", Message: "throttled"}
+		client.On("PublishBatch", ctx, mock.Anything).Return(nil, throttled).Times(2)
+
+		got, err := producer.ProduceAll(ctx, produceAllTopic, newEntries(2),
+			sns.ProduceAllWithMaxAttempts(2),
+			sns.ProduceAllWithBackoff(time.Millisecond, time.Millisecond),
+		)
+		assert.NoError(t, err)
+		assert.Empty(t, got.Successful)
+		assert.Len(t, got.Failed, 2)
+		for _, f := range got.Failed {
+			assert.ErrorContains(t, f.Err, "throttled")
+			assert.False(t, f.SenderFault)
+		}
+	})
+
+	t.Run("Should not retry the request errors that are not retryable", func(t *testing.T) {
+		client := fake.NewSNSClient(t)
+		producer, _ := sns.NewProducer(&sns.Config{SNSClient: client})
+		denied := &smithy.GenericAPIError{Code: "AuthorizationError", Message: "not authorized"}
+		client.On("PublishBatch", ctx, mock.Anything).Return(nil, denied).Once()
+
+		got, err := producer.ProduceAll(ctx, produceAllTopic, newEntries(2), sns.ProduceAllWithBackoff(time.Millisecond, time.Millisecond))
+		assert.NoError(t, err)
+		assert.Empty(t, got.Successful)
+		assert.Len(t, got.Failed, 2)
+		for _, f := range got.Failed {
+			assert.ErrorIs(t, f.Err, denied)
+		}
+	})
+
+	t.Run("Should stop publishing when the context is cancelled", func(t *testing.T) {
+		client := fake.NewSNSClient(t)
+		producer, _ := sns.NewProducer(&sns.Config{SNSClient: client})
+		cancelCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// the first batch holds the only concurrency slot until the context is cancelled
+		client.On("PublishBatch", cancelCtx, mock.Anything).
+			Run(func(mock.Arguments) {
+				cancel()
+			}).
+			Return(nil, context.Canceled).Once()
+
+		done := make(chan struct{})
+		var got *sns.PublishBatchOutput
+		go func() {
+			defer close(done)
+			got, _ = producer.ProduceAll(cancelCtx, produceAllTopic, newEntries(25), sns.ProduceAllWithConcurrency(1))
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("ProduceAll must return once the context is cancelled")
+		}
+		assert.Empty(t, got.Successful)
+		assert.Len(t, got.Failed, 25)
+		for _, f := range got.Failed {
+			assert.ErrorIs(t, f.Err, context.Canceled)
+		}
+	})
+
+	t.Run("Should give the entries without ID their index", func(t *testing.T) {
+		client := fake.NewSNSClient(t)
+		producer, _ := sns.NewProducer(&sns.Config{SNSClient: client})
+		recorder := &publishBatchRecorder{}
+		client.On("PublishBatch", ctx, mock.Anything).Return(recorder.publishBatch).Once()
+
+		entries := []*sns.PublishBatchEntry{{Message: "first"}, {Message: "second"}}
+		got, err := producer.ProduceAll(ctx, produceAllTopic, entries)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"0", "1"}}, recorder.batches)
+		assert.Len(t, got.Successful, 2)
+		assert.Empty(t, entries[0].ID, "the entries are not modified")
+	})
+
+	t.Run("With invalid input", func(t *testing.T) {
+		producer, _ := sns.NewProducer(&sns.Config{SNSClient: fake.NewSNSClient(t)})
+
+		got, err := producer.ProduceAll(ctx, produceAllTopic, nil)
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, loafergo.ErrEmptyInput)
+
+		got, err = producer.ProduceAll(ctx, "", newEntries(1))
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, loafergo.ErrEmptyInput)
+
+		got, err = producer.ProduceAll(ctx, produceAllTopic, []*sns.PublishBatchEntry{{ID: "id"}, {ID: "id"}})
+		assert.Nil(t, got)
+		assert.EqualError(t, err, "duplicate entry id: id")
+	})
+}
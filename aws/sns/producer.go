@@ -10,6 +10,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sns/types"
 
 	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+	"github.com/justcodes/loafer-go/v2/cloudevents"
+	"github.com/justcodes/loafer-go/v2/codec"
 )
 
 const (
@@ -21,6 +24,13 @@ const (
 type Producer interface {
 	Produce(ctx context.Context, input *PublishInput) (string, error)
 	ProduceBatch(ctx context.Context, input *PublishBatchInput) (*PublishBatchOutput, error)
+	ProduceAll(
+		ctx context.Context,
+		topicARN string,
+		entries []*PublishBatchEntry,
+		optFns ...LoadProduceAllConfigFunc,
+	) (*PublishBatchOutput, error)
+	ProduceEvent(ctx context.Context, input *PublishEventInput) (string, error)
 }
 
 // PublishBatchInput holds the sns batch publish attributes
@@ -32,7 +42,9 @@ type PublishBatchInput struct {
 // PublishBatchEntry holds the sns batch publish attributes
 // Each entry must have a unique ID to identify it in the request and response
 type PublishBatchEntry struct {
-	Attributes      map[string]string
+	Attributes map[string]string
+	// Payload is marshaled with the producer codec and sent instead of Message when set
+	Payload         any
 	ID              string
 	Message         string
 	GroupID         string
@@ -55,19 +67,39 @@ type PublishBatchEntrySuccessful struct {
 type PublishBatchEntryFailed struct {
 	Err     error
 	EntryID string
+	Code    string
+	// SenderFault is true when the entry failed because of the request, so publishing it again will fail too
+	SenderFault bool
 }
 
 // PublishInput has the sns event attributes
 type PublishInput struct {
-	Attributes      map[string]string
+	Attributes map[string]string
+	// Payload is marshaled with the producer codec and sent instead of Message when set
+	Payload         any
 	Message         string
 	GroupID         string
 	DeduplicationID string
 	TopicARN        string
 }
 
+// PublishEventInput has the sns attributes of a CloudEvents event
+type PublishEventInput struct {
+	Event *cloudevents.Event
+	// Attributes are sent along with the event, the event attributes take precedence
+	Attributes map[string]string
+	TopicARN   string
+	GroupID    string
+	// DeduplicationID defaults to the event ID when GroupID is set
+	DeduplicationID string
+	Mode            cloudevents.Mode
+}
+
 type producer struct {
-	sns loafergo.SNSClient
+	sns              loafergo.SNSClient
+	blobStore        loafergo.BlobStore
+	codec            loafergo.Codec
+	offloadThreshold int
 }
 
 // NewProducer creates a new Producer
@@ -79,7 +111,10 @@ func NewProducer(config *Config) (Producer, error) {
 	}
 
 	return &producer{
-		sns: config.SNSClient,
+		sns:              config.SNSClient,
+		blobStore:        config.BlobStore,
+		codec:            config.Codec,
+		offloadThreshold: config.OffloadThreshold,
 	}, nil
 }
 
@@ -88,13 +123,21 @@ func NewProducer(config *Config) (Producer, error) {
 // and, when ID-based deduplication is used, a deduplication ID. An optional key-value
 // filter attribute can be specified so that the message can be filtered according to
 // a filter policy.
+//
+// When the producer has a BlobStore, a message larger than the offload threshold is stored
+// in it and a claim check pointer is published instead.
 func (p *producer) Produce(ctx context.Context, input *PublishInput) (string, error) {
 	if input == nil || reflect.DeepEqual(input, &PublishInput{}) {
 		return "", loafergo.ErrEmptyInput
 	}
 
+	message, attributes, err := p.encode(ctx, input.Message, input.Payload, input.Attributes)
+	if err != nil {
+		return "", err
+	}
+
 	pubInp := &sns.PublishInput{
-		Message:   &input.Message,
+		Message:   &message,
 		TargetArn: &input.TopicARN,
 	}
 
@@ -106,8 +149,8 @@ func (p *producer) Produce(ctx context.Context, input *PublishInput) (string, er
 		pubInp.MessageDeduplicationId = aws.String(input.DeduplicationID)
 	}
 
-	if len(input.Attributes) > 0 {
-		pubInp.MessageAttributes = p.messageAttributes(input.Attributes)
+	if len(attributes) > 0 {
+		pubInp.MessageAttributes = p.messageAttributes(attributes)
 	}
 
 	result, err := p.sns.Publish(ctx, pubInp)
@@ -138,9 +181,14 @@ func (p *producer) ProduceBatch(ctx context.Context, input *PublishBatchInput) (
 	}
 
 	for _, msg := range input.Messages {
+		body, attributes, err := p.encode(ctx, msg.Message, msg.Payload, msg.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
 		message := types.PublishBatchRequestEntry{
 			Id:      aws.String(msg.ID),
-			Message: aws.String(msg.Message),
+			Message: aws.String(body),
 		}
 
 		if msg.GroupID != "" {
@@ -151,8 +199,8 @@ func (p *producer) ProduceBatch(ctx context.Context, input *PublishBatchInput) (
 			message.MessageDeduplicationId = aws.String(msg.DeduplicationID)
 		}
 
-		if len(msg.Attributes) > 0 {
-			message.MessageAttributes = p.messageAttributes(msg.Attributes)
+		if len(attributes) > 0 {
+			message.MessageAttributes = p.messageAttributes(attributes)
 		}
 
 		pubInp.PublishBatchRequestEntries = append(pubInp.PublishBatchRequestEntries, message)
@@ -180,12 +228,45 @@ func (p *producer) getSuccessfulEntries(entries []types.PublishBatchResultEntry)
 	return successful
 }
 
+// ProduceEvent publishes a CloudEvents event to an Amazon SNS topic, in structured or binary mode.
+// The consumers read it back with cloudevents.FromMessage.
+func (p *producer) ProduceEvent(ctx context.Context, input *PublishEventInput) (string, error) {
+	if input == nil || input.Event == nil {
+		return "", loafergo.ErrEmptyInput
+	}
+
+	body, attrs, err := cloudevents.Encode(input.Event, input.Mode)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range input.Attributes {
+		if _, ok := attrs[k]; !ok {
+			attrs[k] = v
+		}
+	}
+
+	deduplicationID := input.DeduplicationID
+	if deduplicationID == "" && input.GroupID != "" {
+		deduplicationID = input.Event.ID
+	}
+
+	return p.Produce(ctx, &PublishInput{
+		Attributes:      attrs,
+		Message:         body,
+		GroupID:         input.GroupID,
+		DeduplicationID: deduplicationID,
+		TopicARN:        input.TopicARN,
+	})
+}
+
 func (p *producer) getFailedEntries(entries []types.BatchResultErrorEntry) []*PublishBatchEntryFailed {
 	failed := make([]*PublishBatchEntryFailed, len(entries))
 	for i, entry := range entries {
 		failed[i] = &PublishBatchEntryFailed{
-			EntryID: *entry.Id,
-			Err:     fmt.Errorf("failed to publish message; error: %s", *entry.Message),
+			EntryID:     *entry.Id,
+			Code:        aws.ToString(entry.Code),
+			SenderFault: entry.SenderFault,
+			Err:         fmt.Errorf("failed to publish message; error: %s", aws.ToString(entry.Message)),
 		}
 	}
 	return failed
@@ -201,3 +282,32 @@ func (p *producer) messageAttributes(attr map[string]string) map[string]types.Me
 	}
 	return ma
 }
+
+// encode marshals the payload with the producer codec, when set instead of the message,
+// and offloads the resulting body when it is too large
+func (p *producer) encode(
+	ctx context.Context,
+	message string,
+	payload any,
+	attributes map[string]string,
+) (string, map[string]string, error) {
+	if payload != nil {
+		c := p.codec
+		if c == nil {
+			c = codec.JSON
+		}
+
+		body, attrs, err := codec.Encode(c, payload)
+		if err != nil {
+			return "", nil, err
+		}
+		for k, v := range attributes {
+			if _, ok := attrs[k]; !ok {
+				attrs[k] = v
+			}
+		}
+		message, attributes = body, attrs
+	}
+
+	return claimcheck.Offload(ctx, p.blobStore, message, attributes, p.offloadThreshold)
+}
@@ -9,9 +9,12 @@ import (
 	awsSNS "github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/sns/types"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	loafergo "github.com/justcodes/loafer-go/v2"
 	"github.com/justcodes/loafer-go/v2/aws/sns"
+	"github.com/justcodes/loafer-go/v2/cloudevents"
+	"github.com/justcodes/loafer-go/v2/codec"
 	"github.com/justcodes/loafer-go/v2/fake"
 )
 
@@ -83,6 +86,36 @@ func (suite *producerSuite) TestPublish() {
 
 	})
 
+	suite.Run("Should marshal the payload with the producer codec", func() {
+		producer, err := sns.NewProducer(&sns.Config{
+			SNSClient: suite.snsCLient,
+			Codec:     codec.Protobuf,
+		})
+		suite.Require().NoError(err)
+
+		body, _, err := codec.Encode(codec.Protobuf, wrapperspb.String("hello"))
+		suite.Require().NoError(err)
+
+		param := &awsSNS.PublishInput{
+			Message:   aws.String(body),
+			TargetArn: aws.String("arn:aws:sns:us-east-1:0000000:my_topic"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				codec.ContentTypeAttribute: {DataType: aws.String("String"), StringValue: aws.String("application/x-protobuf")},
+			},
+		}
+
+		suite.snsCLient.On("Publish", ctx, param).
+			Return(&awsSNS.PublishOutput{MessageId: aws.String("id")}, nil).
+			Once()
+
+		got, err := producer.Produce(ctx, &sns.PublishInput{
+			Payload:  wrapperspb.String("hello"),
+			TopicARN: "arn:aws:sns:us-east-1:0000000:my_topic",
+		})
+		suite.NoError(err)
+		suite.Equal("id", got)
+	})
+
 	suite.Run("Should produce with message attributes", func() {
 		topicArn, err := sns.BuildTopicARN("us-east-1", "0000000", "my_topic")
 		suite.NoError(err)
@@ -235,6 +268,41 @@ func (suite *producerSuite) TestPublish() {
 	})
 }
 
+func (suite *producerSuite) TestPublishEvent() {
+	ctx := context.Background()
+
+	suite.Run("Should publish the event in structured mode", func() {
+		event := &cloudevents.Event{ID: "event-1", Source: "/orders", Type: "created", Data: []byte(`{"id":"1"}`)}
+		body, _, err := cloudevents.Encode(event, cloudevents.Structured)
+		suite.Require().NoError(err)
+
+		param := &awsSNS.PublishInput{
+			Message:   aws.String(body),
+			TargetArn: aws.String("arn:aws:sns:us-east-1:0000000:my_topic"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				codec.ContentTypeAttribute: {DataType: aws.String("String"), StringValue: aws.String(cloudevents.ContentType)},
+			},
+		}
+
+		suite.snsCLient.On("Publish", ctx, param).
+			Return(&awsSNS.PublishOutput{MessageId: aws.String("id")}, nil).
+			Once()
+
+		got, err := suite.producer.ProduceEvent(ctx, &sns.PublishEventInput{
+			Event:    event,
+			TopicARN: "arn:aws:sns:us-east-1:0000000:my_topic",
+		})
+		suite.NoError(err)
+		suite.Equal("id", got)
+	})
+
+	suite.Run("With Input nil", func() {
+		got, err := suite.producer.ProduceEvent(ctx, nil)
+		suite.Empty(got)
+		suite.ErrorIs(err, loafergo.ErrEmptyInput)
+	})
+}
+
 func (suite *producerSuite) TestPublishBatch() {
 	ctx := context.Background()
 	suite.Run("Should produce with Success", func() {
@@ -0,0 +1,149 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+// maxDeleteBatchSize is the maximum number of entries accepted by DeleteMessageBatch
+const maxDeleteBatchSize = 10
+
+type pendingDelete struct {
+	receiptHandle string
+	// deleted is called with the result of the deletion once the batch holding the receipt is flushed
+	deleted func(err error)
+}
+
+// commitBatcher buffers the receipts of the committed messages and deletes them
+// with DeleteMessageBatch, once maxBatch receipts are buffered or maxDelay elapsed
+// since the first one. The batches are flushed in the background, so the workers
+// committing the messages do not wait for them.
+type commitBatcher struct {
+	sqs      loafergo.SQSClient
+	queueURL func() string
+	timer    *time.Timer
+	pending  []*pendingDelete
+	maxBatch int
+	maxDelay time.Duration
+	mu       sync.Mutex
+	// flushes tracks the batches being flushed in the background
+	flushes sync.WaitGroup
+}
+
+func newCommitBatcher(client loafergo.SQSClient, queueURL func() string, maxBatch int, maxDelay time.Duration) *commitBatcher {
+	if maxBatch < 1 || maxBatch > maxDeleteBatchSize {
+		maxBatch = maxDeleteBatchSize
+	}
+
+	return &commitBatcher{
+		sqs:      client,
+		queueURL: queueURL,
+		maxBatch: maxBatch,
+		maxDelay: maxDelay,
+	}
+}
+
+// delete buffers the receipt handle and returns without waiting for the batch holding it to be flushed.
+// Once it is, deleted is called with the error concerning this receipt only, nil when it was deleted.
+func (b *commitBatcher) delete(ctx context.Context, receiptHandle string, deleted func(err error)) {
+	// the messages were handled, so their deletion must not be aborted by a cancelled context
+	ctx = context.WithoutCancel(ctx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending = append(b.pending, &pendingDelete{receiptHandle: receiptHandle, deleted: deleted})
+	if len(b.pending) >= b.maxBatch {
+		batch := b.take()
+		b.flushes.Go(func() {
+			b.flush(ctx, batch)
+		})
+		return
+	}
+
+	if b.timer == nil {
+		var timer *time.Timer
+		timer = time.AfterFunc(b.maxDelay, func() {
+			b.mu.Lock()
+			// the batch of this timer was already taken by a full batch or a drain
+			if b.timer != timer {
+				b.mu.Unlock()
+				return
+			}
+			batch := b.take()
+			b.flushes.Add(1)
+			b.mu.Unlock()
+
+			defer b.flushes.Done()
+			b.flush(ctx, batch)
+		})
+		b.timer = timer
+	}
+}
+
+// drain flushes the buffered receipts and waits for the batches being flushed in the background
+func (b *commitBatcher) drain(ctx context.Context) {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+
+	b.flush(ctx, batch)
+	b.flushes.Wait()
+}
+
+// take empties the buffer, it must be called with the lock held
+func (b *commitBatcher) take() []*pendingDelete {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	batch := b.pending
+	b.pending = nil
+	return batch
+}
+
+func (b *commitBatcher) flush(ctx context.Context, batch []*pendingDelete) {
+	if len(batch) == 0 {
+		return
+	}
+
+	entries := make([]types.DeleteMessageBatchRequestEntry, len(batch))
+	for i, p := range batch {
+		entries[i] = types.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: aws.String(p.receiptHandle),
+		}
+	}
+
+	output, err := b.sqs.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(b.queueURL()),
+		Entries:  entries,
+	})
+	if err != nil {
+		for _, p := range batch {
+			p.deleted(err)
+		}
+		return
+	}
+
+	failed := make(map[string]error, len(output.Failed))
+	for _, f := range output.Failed {
+		failed[aws.ToString(f.Id)] = loafergo.ErrDeleteMessage.Context(
+			fmt.Errorf("%s: %s", aws.ToString(f.Code), aws.ToString(f.Message)),
+		)
+	}
+
+	for i, p := range batch {
+		p.deleted(failed[strconv.Itoa(i)])
+	}
+}
@@ -0,0 +1,242 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+func deleteBatchInput(receipts ...string) *sqs.DeleteMessageBatchInput {
+	entries := make([]types.DeleteMessageBatchRequestEntry, len(receipts))
+	for i, receipt := range receipts {
+		entries[i] = types.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: aws.String(receipt),
+		}
+	}
+	return &sqs.DeleteMessageBatchInput{QueueUrl: aws.String("queue-url"), Entries: entries}
+}
+
+// deleteResults collects the results of the deletions of a commitBatcher
+type deleteResults struct {
+	errs map[string]error
+	done chan struct{}
+	mu   sync.Mutex
+}
+
+func newDeleteResults() *deleteResults {
+	return &deleteResults{errs: map[string]error{}, done: make(chan struct{}, 100)}
+}
+
+func (r *deleteResults) deleted(receipt string) func(err error) {
+	return func(err error) {
+		r.mu.Lock()
+		r.errs[receipt] = err
+		r.mu.Unlock()
+		r.done <- struct{}{}
+	}
+}
+
+// wait waits for n deletions and returns the results
+func (r *deleteResults) wait(t *testing.T, n int) map[string]error {
+	for range n {
+		select {
+		case <-r.done:
+		case <-time.After(time.Second):
+			t.Fatal("deletions not reported")
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.errs
+}
+
+func TestCommitBatcher(t *testing.T) {
+	queueURL := func() string { return "queue-url" }
+
+	t.Run("Should flush when the batch is full", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		b := newCommitBatcher(mockSQSClient, queueURL, 2, time.Hour)
+		results := newDeleteResults()
+
+		mockSQSClient.On("DeleteMessageBatch", mock.Anything, deleteBatchInput("receipt-1", "receipt-2")).
+			Return(&sqs.DeleteMessageBatchOutput{}, nil).Once()
+
+		b.delete(context.Background(), "receipt-1", results.deleted("receipt-1"))
+		b.delete(context.Background(), "receipt-2", results.deleted("receipt-2"))
+		assert.Equal(t, map[string]error{"receipt-1": nil, "receipt-2": nil}, results.wait(t, 2))
+	})
+
+	t.Run("Should flush after the max delay", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		b := newCommitBatcher(mockSQSClient, queueURL, 10, 10*time.Millisecond)
+		results := newDeleteResults()
+
+		mockSQSClient.On("DeleteMessageBatch", mock.Anything, deleteBatchInput("receipt-1")).
+			Return(&sqs.DeleteMessageBatchOutput{}, nil).Once()
+
+		b.delete(context.Background(), "receipt-1", results.deleted("receipt-1"))
+		assert.Equal(t, map[string]error{"receipt-1": nil}, results.wait(t, 1))
+	})
+
+	t.Run("Should flush the buffered receipts when drained", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		b := newCommitBatcher(mockSQSClient, queueURL, 10, time.Hour)
+		results := newDeleteResults()
+
+		mockSQSClient.On("DeleteMessageBatch", mock.Anything, deleteBatchInput("receipt-1")).
+			Return(&sqs.DeleteMessageBatchOutput{}, nil).Once()
+
+		b.delete(context.Background(), "receipt-1", results.deleted("receipt-1"))
+		b.drain(context.Background())
+		assert.Equal(t, map[string]error{"receipt-1": nil}, results.wait(t, 1))
+	})
+
+	t.Run("Should report the failed entries only", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		b := newCommitBatcher(mockSQSClient, queueURL, 2, time.Hour)
+		results := newDeleteResults()
+
+		mockSQSClient.On("DeleteMessageBatch", mock.Anything, mock.Anything).
+			Return(func(ctx context.Context, in *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+				output := &sqs.DeleteMessageBatchOutput{}
+				for _, e := range in.Entries {
+					if aws.ToString(e.ReceiptHandle) == "expired" {
+						output.Failed = append(output.Failed, types.BatchResultErrorEntry{
+							Id:      e.Id,
+							Code:    aws.String("ReceiptHandleIsInvalid"),
+							Message: aws.String("receipt handle expired"),
+						})
+						continue
+					}
+					output.Successful = append(output.Successful, types.DeleteMessageBatchResultEntry{Id: e.Id})
+				}
+				return output, nil
+			}).Once()
+
+		b.delete(context.Background(), "expired", results.deleted("expired"))
+		b.delete(context.Background(), "receipt-2", results.deleted("receipt-2"))
+		errs := results.wait(t, 2)
+
+		assert.NoError(t, errs["receipt-2"])
+		assert.ErrorIs(t, errs["expired"], loafergo.ErrDeleteMessage)
+		assert.EqualError(t, errs["expired"], "failed to delete message: ReceiptHandleIsInvalid: receipt handle expired")
+	})
+
+	t.Run("Should report the request error to every entry", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		b := newCommitBatcher(mockSQSClient, queueURL, 1, time.Hour)
+		results := newDeleteResults()
+
+		mockSQSClient.On("DeleteMessageBatch", mock.Anything, deleteBatchInput("receipt-1")).
+			Return(nil, errors.New("throttled")).Once()
+
+		b.delete(context.Background(), "receipt-1", results.deleted("receipt-1"))
+		assert.EqualError(t, results.wait(t, 1)["receipt-1"], "throttled")
+	})
+
+	t.Run("Should not make the committers wait for the batch", func(t *testing.T) {
+		const workers, commits = 5, 100
+		mockSQSClient := fake.NewSQSClient(t)
+		b := newCommitBatcher(mockSQSClient, queueURL, 10, time.Minute)
+
+		var deleted atomic.Int32
+		mockSQSClient.On("DeleteMessageBatch", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				deleted.Add(int32(len(args.Get(1).(*sqs.DeleteMessageBatchInput).Entries)))
+			}).
+			Return(&sqs.DeleteMessageBatchOutput{}, nil)
+
+		// fewer workers than the batch size, each committing its messages one after the other
+		start := time.Now()
+		var wg sync.WaitGroup
+		for w := range workers {
+			wg.Go(func() {
+				for i := range commits {
+					b.delete(context.Background(), fmt.Sprintf("receipt-%d-%d", w, i), func(err error) {
+						assert.NoError(t, err)
+					})
+				}
+			})
+		}
+		wg.Wait()
+		assert.Less(t, time.Since(start), time.Second, "the commits must not wait for the max delay")
+
+		b.drain(context.Background())
+		assert.Equal(t, int32(workers*commits), deleted.Load())
+		assert.Len(t, mockSQSClient.Calls, workers*commits/10)
+	})
+
+	t.Run("Should clamp the batch size to the SQS limit", func(t *testing.T) {
+		assert.Equal(t, maxDeleteBatchSize, newCommitBatcher(nil, queueURL, 0, time.Second).maxBatch)
+		assert.Equal(t, maxDeleteBatchSize, newCommitBatcher(nil, queueURL, 20, time.Second).maxBatch)
+	})
+}
+
+func TestRouteBatchCommit(t *testing.T) {
+	newBatchRoute := func(client loafergo.SQSClient) *route {
+		r := NewRoute(&Config{
+			SQSClient: client,
+			Handler:   stubHandler,
+			QueueName: "queue-name",
+		}, RouteWithBatchCommit(10, time.Hour)).(*route)
+		r.queueURL = "queue-url"
+		return r
+	}
+
+	t.Run("Should delete the committed messages on flush", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		r := newBatchRoute(mockSQSClient)
+
+		m := newMessage(types.Message{ReceiptHandle: aws.String("receipt-handler")})
+		assert.NoError(t, r.Commit(context.Background(), m))
+
+		select {
+		case <-m.dispatched:
+		default:
+			t.Fatal("message must be dispatched")
+		}
+
+		mockSQSClient.On("DeleteMessageBatch", mock.Anything, deleteBatchInput("receipt-handler")).
+			Return(&sqs.DeleteMessageBatchOutput{}, nil).Once()
+		r.Flush(context.Background())
+	})
+
+	t.Run("Should report the messages that cannot be deleted", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		r := newBatchRoute(mockSQSClient)
+		metrics := fake.NewMetrics(t)
+		ctx := loafergo.ContextWithMetrics(context.Background(), metrics)
+
+		var logged []string
+		m := newMessage(types.Message{ReceiptHandle: aws.String("receipt-handler"), MessageId: aws.String("message-id")})
+		m.logger = loafergo.LoggerFunc(func(args ...any) {
+			logged = append(logged, fmt.Sprint(args...))
+		})
+		assert.NoError(t, r.Commit(ctx, m))
+
+		mockSQSClient.On("DeleteMessageBatch", mock.Anything, deleteBatchInput("receipt-handler")).
+			Return(nil, errors.New("throttled")).Once()
+		metrics.On("CommitError", "queue-name", mock.MatchedBy(func(err error) bool {
+			return err.Error() == "throttled"
+		})).Return().Once()
+		r.Flush(ctx)
+
+		assert.Len(t, logged, 1)
+		assert.Contains(t, logged[0], "commit_message_error; queue: queue-name; error: throttled; message_id: message-id")
+	})
+}
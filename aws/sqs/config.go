@@ -1,16 +1,23 @@
 package sqs
 
 import (
+	"context"
 	"strconv"
+	"time"
 
 	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
 )
 
 // A Config provides service configuration for SQS routes.
 type Config struct {
 	SQSClient loafergo.SQSClient
 	Handler   loafergo.Handler
-	QueueName string
+	// BatchHandler receives all the messages of a receive call at once, up to the route max messages.
+	// When set, it is used instead of Handler and the handler middlewares do not apply.
+	// In PerGroupID run mode, it is called once per worker with the messages of its groups, in order.
+	BatchHandler loafergo.BatchHandler
+	QueueName    string
 }
 
 const (
@@ -19,10 +26,24 @@ const (
 	defaultMaxMessages       = int32(10)
 	defaultWaitTimeSeconds   = int32(10)
 	defaultWorkerPoolSize    = int32(5)
+	defaultBatchCommitDelay  = 100 * time.Millisecond
 )
 
+// DeadLetterFunc is called with a message to dead-letter and the reason why: the reason of the DeadLetter
+// outcome of the handler, or DeadLetterReasonMaxReceiveCount for a message that exceeded the route max receive count.
+// When it returns nil the message is deleted from the source queue.
+type DeadLetterFunc func(ctx context.Context, m loafergo.Message, reason string) error
+
 // RouteConfig are a discrete set of route options that are valid for loading the route configuration
 type RouteConfig struct {
+	deadLetterFunc    DeadLetterFunc
+	blobStore         loafergo.BlobStore
+	claimCheck        claimcheck.Config
+	codec             loafergo.Codec
+	retryPolicy       *loafergo.RetryPolicy
+	scalingPolicy     *loafergo.ScalingPolicy
+	deadLetterQueue   string
+	middlewares       []loafergo.Middleware
 	customGroupFields []string
 	extensionLimit    int
 	runMode           loafergo.Mode
@@ -30,6 +51,10 @@ type RouteConfig struct {
 	maxMessages       int32
 	waitTimeSeconds   int32
 	workerPoolSize    int32
+	maxReceiveCount   int32
+	batchCommitSize   int
+	batchCommitDelay  time.Duration
+	batchCommit       bool
 }
 
 func loadDefaultRouteConfig() *RouteConfig {
@@ -116,6 +141,8 @@ func RouteWithWaitTimeSeconds(v int32) LoadRouteConfigFunc {
 // RouteWithWorkerPoolSize is a helper function to construct functional options that sets Worker Pool Size value
 // on config's Route. If multiple RouteWithWorkerPoolSize calls are made,
 // the last call overrides the previous call values.
+//
+// With RouteWithAutoscaling, it is the initial size of the pool, within the policy bounds.
 func RouteWithWorkerPoolSize(v int32) LoadRouteConfigFunc {
 	return func(rc *RouteConfig) {
 		rc.workerPoolSize = v
@@ -137,7 +164,8 @@ func RouteWithRunMode(v loafergo.Mode) LoadRouteConfigFunc {
 
 // RouteWithCustomGroupFields sets the custom group fields used for message routing
 // when the run mode is set to PerGroupID.
-// These fields are extracted from the message body and appended to the MessageGroupId
+// These fields are read from the message attributes, either set on the SQS message
+// or carried by the SNS envelope, and appended to the MessageGroupId
 // to generate a unique group key.
 // This allows finer control over how messages are partitioned across workers.
 func RouteWithCustomGroupFields(v []string) LoadRouteConfigFunc {
@@ -146,6 +174,118 @@ func RouteWithCustomGroupFields(v []string) LoadRouteConfigFunc {
 	}
 }
 
+// RouteWithMaxReceiveCount sets the poison-message policy of the route.
+//
+// Before handling a message, the route reads its ApproximateReceiveCount system attribute.
+// Once it exceeds n, the handler is not called: the original body and message attributes
+// are forwarded to the dlq queue and the message is deleted from the source queue.
+// FIFO messages keep their MessageGroupId and MessageDeduplicationId.
+//
+// Use RouteWithDeadLetterFunc to handle these messages with a callback instead.
+// Configure fails when dlq is empty and no callback is set; to drop the messages on purpose,
+// set a callback returning nil.
+//
+// It works the same way as an SQS redrive policy, but is enforced by the consumer,
+// so it also works against local emulators.
+func RouteWithMaxReceiveCount(n int32, dlq string) LoadRouteConfigFunc {
+	return func(rc *RouteConfig) {
+		rc.maxReceiveCount = n
+		rc.deadLetterQueue = dlq
+	}
+}
+
+// RouteWithDeadLetterFunc sets the callback invoked with the messages that exceeded
+// the max receive count set with RouteWithMaxReceiveCount, and with the messages the handler
+// settled with a DeadLetter outcome. The reason passed to fn tells them apart.
+// It takes precedence over the dead-letter queue.
+func RouteWithDeadLetterFunc(fn DeadLetterFunc) LoadRouteConfigFunc {
+	return func(rc *RouteConfig) {
+		rc.deadLetterFunc = fn
+	}
+}
+
+// RouteWithMiddleware adds middlewares around the route handler.
+// The first middleware is the outermost one. If multiple RouteWithMiddleware calls are made,
+// the middlewares are appended in the order of the calls.
+//
+// Middlewares registered on loafergo.Config run before the route ones.
+func RouteWithMiddleware(mws ...loafergo.Middleware) LoadRouteConfigFunc {
+	return func(rc *RouteConfig) {
+		rc.middlewares = append(rc.middlewares, mws...)
+	}
+}
+
+// RouteWithBatchCommit buffers the receipts of the successfully handled messages and deletes them
+// with DeleteMessageBatch instead of one DeleteMessage call per message.
+//
+// A batch is flushed in the background once maxBatch receipts are buffered (at most 10, the SQS limit)
+// or maxDelay after its first receipt (100ms when maxDelay is not positive). Commit returns once the receipt
+// is buffered, so the workers take the next messages while the batch fills up. The failure of a single entry
+// is logged and reported to loafergo.Metrics CommitError for that message only, and the message is received
+// again after its visibility timeout. Manager.Shutdown returns once the buffered receipts are flushed.
+func RouteWithBatchCommit(maxBatch int, maxDelay time.Duration) LoadRouteConfigFunc {
+	return func(rc *RouteConfig) {
+		rc.batchCommit = true
+		rc.batchCommitSize = maxBatch
+		rc.batchCommitDelay = maxDelay
+		if maxDelay <= 0 {
+			rc.batchCommitDelay = defaultBatchCommitDelay
+		}
+	}
+}
+
+// RouteWithBlobStore sets the store holding the payloads offloaded by the producers, see the claimcheck package.
+//
+// Before a message carrying a claim check is handled, its payload is fetched from the store,
+// so Body, Decode, Message and DecodeMessage return the original payload.
+//
+// The payloads are kept in the store, so they should expire through a TTL or the lifecycle rules of the bucket.
+// With claimcheck.DeleteOnCommit, the payload is deleted once the message is committed; only use it when
+// the queue is the sole receiver of the claim checks: when a topic fans a message out to several queues,
+// or a standard queue delivers it twice, the other copies can no longer fetch the payload.
+// Dead-lettered messages are forwarded with their claim check, and their payload is kept.
+func RouteWithBlobStore(store loafergo.BlobStore, optFns ...func(*claimcheck.Config)) LoadRouteConfigFunc {
+	return func(rc *RouteConfig) {
+		rc.blobStore = store
+		for _, optFn := range optFns {
+			optFn(&rc.claimCheck)
+		}
+	}
+}
+
+// RouteWithCodec sets the codec used by Decode and DecodeMessage for the messages without a content-type attribute,
+// or naming a media type no codec is registered for. The messages naming their codec are always decoded with it,
+// see the codec package.
+//
+// The default codec is codec.JSON.
+func RouteWithCodec(c loafergo.Codec) LoadRouteConfigFunc {
+	return func(rc *RouteConfig) {
+		rc.codec = c
+	}
+}
+
+// RouteWithRetryPolicy spaces the retries of the failed messages.
+//
+// When the handler fails, the visibility of the message is changed to the delay computed by the policy
+// from its ApproximateReceiveCount, so it is received again after that delay instead of after the
+// visibility last set. A message the handler backed off itself keeps its own delay.
+// The retries are bounded by the route max receive count, see RouteWithMaxReceiveCount.
+func RouteWithRetryPolicy(p *loafergo.RetryPolicy) LoadRouteConfigFunc {
+	return func(rc *RouteConfig) {
+		rc.retryPolicy = p
+	}
+}
+
+// RouteWithAutoscaling grows and shrinks the worker pool of the route following the policy.
+//
+// The backlog the policy follows is the ApproximateNumberOfMessages attribute of the queue,
+// read with GetQueueAttributes at each evaluation of the policy.
+func RouteWithAutoscaling(p *loafergo.ScalingPolicy) LoadRouteConfigFunc {
+	return func(rc *RouteConfig) {
+		rc.scalingPolicy = p
+	}
+}
+
 // AWSConfig defines the loafer aws configuration
 type AWSConfig struct {
 	// private key to access aws
@@ -220,3 +360,6 @@ const DataTypeNumber = DataType("Number")
 
 // DataTypeString represents the String datatype, use it when creating custom attributes
 const DataTypeString = DataType("String")
+
+// DataTypeBinary represents the Binary datatype of message attributes
+const DataTypeBinary = DataType("Binary")
@@ -1,7 +1,9 @@
 package sqs
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -88,3 +90,45 @@ func TestRouteWithCustomGroupFields(t *testing.T) {
 		assert.Empty(t, cfg.customGroupFields)
 	})
 }
+
+func TestRouteWithMaxReceiveCount(t *testing.T) {
+	cfg := loadDefaultRouteConfig()
+	var optConfigFns func(config *RouteConfig)
+	optConfigFns = RouteWithMaxReceiveCount(3, "example-dlq")
+	optConfigFns(cfg)
+	assert.Equal(t, int32(3), cfg.maxReceiveCount)
+	assert.Equal(t, "example-dlq", cfg.deadLetterQueue)
+}
+
+func TestRouteWithDeadLetterFunc(t *testing.T) {
+	cfg := loadDefaultRouteConfig()
+	var optConfigFns func(config *RouteConfig)
+	optConfigFns = RouteWithDeadLetterFunc(func(ctx context.Context, m loafergo.Message, reason string) error { return nil })
+	optConfigFns(cfg)
+	assert.NotNil(t, cfg.deadLetterFunc)
+}
+
+func TestRouteWithMiddleware(t *testing.T) {
+	cfg := loadDefaultRouteConfig()
+	mw := func(next loafergo.Handler) loafergo.Handler { return next }
+	RouteWithMiddleware(mw)(cfg)
+	RouteWithMiddleware(mw, mw)(cfg)
+	assert.Len(t, cfg.middlewares, 3)
+}
+
+func TestRouteWithBatchCommit(t *testing.T) {
+	t.Run("With custom max delay", func(t *testing.T) {
+		cfg := loadDefaultRouteConfig()
+		RouteWithBatchCommit(5, time.Second)(cfg)
+		assert.True(t, cfg.batchCommit)
+		assert.Equal(t, 5, cfg.batchCommitSize)
+		assert.Equal(t, time.Second, cfg.batchCommitDelay)
+	})
+
+	t.Run("Without max delay", func(t *testing.T) {
+		cfg := loadDefaultRouteConfig()
+		RouteWithBatchCommit(5, 0)(cfg)
+		assert.True(t, cfg.batchCommit)
+		assert.Equal(t, defaultBatchCommitDelay, cfg.batchCommitDelay)
+	})
+}
@@ -0,0 +1,94 @@
+package sqs
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+const (
+	// DeadLetterReasonAttribute is the message attribute holding the reason of a DeadLetter outcome
+	DeadLetterReasonAttribute = "loafer-dead-letter-reason"
+	// DeadLetterReasonMaxReceiveCount is the reason passed to the DeadLetterFunc with the messages
+	// that exceeded the max receive count
+	DeadLetterReasonMaxReceiveCount = "max_receive_count_exceeded"
+
+	maxMessageAttributes = 10
+)
+
+// DeadLetterHandler returns a handler forwarding the messages to the queue named queueName,
+// the same way RouteWithMaxReceiveCount does. The messages are deleted from the source queue
+// once forwarded.
+//
+// It is meant to be the fallback of a loafergo.Mux, to dead-letter the messages of an unknown type:
+//
+//	loafergo.NewMux(loafergo.MuxByAttribute("event_type"),
+//		loafergo.MuxWithFallback(sqs.DeadLetterHandler(sqsClient, "orders-dlq")))
+func DeadLetterHandler(client loafergo.SQSClient, queueName string) loafergo.Handler {
+	var (
+		mu       sync.Mutex
+		queueURL string
+	)
+
+	return func(ctx context.Context, msg loafergo.Message) error {
+		mu.Lock()
+		if queueURL == "" {
+			o, err := client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: &queueName})
+			if err != nil {
+				mu.Unlock()
+				return loafergo.ErrDeadLetter.Context(err)
+			}
+			queueURL = aws.ToString(o.QueueUrl)
+		}
+		url := queueURL
+		mu.Unlock()
+
+		if err := forward(ctx, client, url, msg, ""); err != nil {
+			return loafergo.ErrDeadLetter.Context(err)
+		}
+		return nil
+	}
+}
+
+// forward sends the message body and attributes to the queue,
+// keeping the MessageGroupId and MessageDeduplicationId of FIFO messages.
+// A non-empty reason is added in the DeadLetterReasonAttribute attribute, when the message has room for it.
+func forward(ctx context.Context, client loafergo.SQSClient, queueURL string, msg loafergo.Message, reason string) error {
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String(string(msg.Body())),
+	}
+	if m, ok := msg.(*message); ok {
+		// an offloaded payload is forwarded as is, along with its claim check,
+		// so it must not be deleted when the message is committed
+		input.MessageBody = aws.String(aws.ToString(m.originalMessage.Body))
+		m.claimCheckKey = ""
+		if len(m.originalMessage.MessageAttributes) > 0 {
+			input.MessageAttributes = maps.Clone(m.originalMessage.MessageAttributes)
+		}
+	}
+	if reason != "" && len(input.MessageAttributes) < maxMessageAttributes {
+		if input.MessageAttributes == nil {
+			input.MessageAttributes = map[string]types.MessageAttributeValue{}
+		}
+		input.MessageAttributes[DeadLetterReasonAttribute] = types.MessageAttributeValue{
+			DataType:    aws.String(DataTypeString.String()),
+			StringValue: aws.String(reason),
+		}
+	}
+	if groupID := msg.SystemAttributeByKey(messageGroupID); groupID != "" {
+		input.MessageGroupId = aws.String(groupID)
+	}
+	if deduplicationID := msg.SystemAttributeByKey(messageDeduplicationID); deduplicationID != "" {
+		input.MessageDeduplicationId = aws.String(deduplicationID)
+	}
+
+	_, err := client.SendMessage(ctx, input)
+	return err
+}
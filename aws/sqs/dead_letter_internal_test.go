@@ -0,0 +1,87 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+func TestDeadLetterHandler(t *testing.T) {
+	ctx := context.Background()
+	newFIFOMessage := func() *message {
+		return newMessage(types.Message{
+			Body:          aws.String("body"),
+			ReceiptHandle: aws.String("receipt-handler"),
+			Attributes: map[string]string{
+				messageGroupID:         "group-1",
+				messageDeduplicationID: "dedup-1",
+			},
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"event_type": {DataType: aws.String("String"), StringValue: aws.String("unknown")},
+			},
+		})
+	}
+
+	t.Run("Should forward the messages to the queue", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		h := DeadLetterHandler(mockSQSClient, "dlq")
+		m := newFIFOMessage()
+
+		mockSQSClient.On("GetQueueUrl", ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("dlq")}).
+			Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("dlq-url")}, nil).Once()
+		mockSQSClient.On("SendMessage", ctx, &sqs.SendMessageInput{
+			QueueUrl:               aws.String("dlq-url"),
+			MessageBody:            aws.String("body"),
+			MessageAttributes:      m.originalMessage.MessageAttributes,
+			MessageGroupId:         aws.String("group-1"),
+			MessageDeduplicationId: aws.String("dedup-1"),
+		}).Return(&sqs.SendMessageOutput{}, nil).Twice()
+
+		assert.NoError(t, h(ctx, m))
+		assert.NoError(t, h(ctx, m), "the queue url is resolved once")
+	})
+
+	t.Run("Should return error when the queue does not exist", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		h := DeadLetterHandler(mockSQSClient, "dlq")
+
+		mockSQSClient.On("GetQueueUrl", ctx, mock.Anything).Return(nil, errors.New("not found")).Once()
+
+		assert.ErrorIs(t, h(ctx, newFIFOMessage()), loafergo.ErrDeadLetter)
+	})
+
+	t.Run("Should forward the claim check of an offloaded message", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		store := fake.NewBlobStore(t)
+		h := DeadLetterHandler(mockSQSClient, "dlq")
+		m := newMessage(types.Message{
+			Body:          aws.String(`{"loaferClaimCheck":"key-1"}`),
+			ReceiptHandle: aws.String("receipt-handler"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				claimcheck.AttributeKey: {DataType: aws.String("String"), StringValue: aws.String("key-1")},
+			},
+		})
+
+		store.On("Get", ctx, "key-1").Return([]byte("payload"), nil).Once()
+		assert.NoError(t, m.resolveClaimCheck(ctx, store))
+
+		mockSQSClient.On("GetQueueUrl", ctx, mock.Anything).
+			Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("dlq-url")}, nil).Once()
+		mockSQSClient.On("SendMessage", ctx, mock.MatchedBy(func(in *sqs.SendMessageInput) bool {
+			return aws.ToString(in.MessageBody) == `{"loaferClaimCheck":"key-1"}`
+		})).Return(&sqs.SendMessageOutput{}, nil).Once()
+
+		assert.NoError(t, h(ctx, m))
+		assert.Empty(t, m.claimCheckKey, "the payload is kept on commit")
+	})
+}
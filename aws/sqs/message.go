@@ -1,25 +1,51 @@
 package sqs
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+	"github.com/justcodes/loafer-go/v2/codec"
 )
 
 type sqsMessage struct {
-	Timestamp         time.Time                  `json:"Timestamp"`
-	MessageAttributes map[string]CustomAttribute `json:"MessageAttributes"`
-	Message           string                     `json:"Message"`
+	Timestamp         time.Time                    `json:"Timestamp"`
+	MessageAttributes map[string]envelopeAttribute `json:"MessageAttributes"`
+	Message           string                       `json:"Message"`
+}
+
+// envelopeAttribute is a message attribute of the SNS notification envelope
+type envelopeAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
 }
 
 // message serves as a wrapper for sqs.Message as well as controls the error handling channel
 type message struct {
-	message         sqsMessage
-	backoffChannel  chan time.Duration
-	dispatched      chan bool
+	message        sqsMessage
+	backoffChannel chan time.Duration
+	dispatched     chan bool
+	// codec decodes the messages that do not name their codec, JSON when nil
+	codec loafergo.Codec
+	// payload is the body fetched from the blob store, when the message was offloaded
+	payload         []byte
+	claimCheckKey   string
 	originalMessage types.Message
 	backedOff       bool
+	// pickUp is closed once a worker is about to handle the message, see route.PickUp
+	pickUp   chan struct{}
+	pickedUp sync.Once
+	// dispatch stops the visibility extension once, Dispatch may be called by Commit and HandlerMessage
+	dispatch sync.Once
+	logger   loafergo.Logger
 }
 
 func newMessage(m types.Message) *message {
@@ -32,18 +58,61 @@ func newMessage(m types.Message) *message {
 		backedOff:       false,
 		backoffChannel:  make(chan time.Duration, 1),
 		dispatched:      make(chan bool, 1),
+		pickUp:          make(chan struct{}),
 		originalMessage: m,
 		message:         msg,
 	}
 }
 
 func (m *message) body() []byte {
+	if m.payload != nil {
+		return m.payload
+	}
+
 	if m.originalMessage.Body != nil {
 		return []byte(*m.originalMessage.Body)
 	}
 	return []byte(``)
 }
 
+// resolveClaimCheck fetches the offloaded payload of the message from store.
+// The pointer is either the SQS body, when the message was sent to the queue,
+// or the SNS envelope message, when it was published to a topic.
+func (m *message) resolveClaimCheck(ctx context.Context, store loafergo.BlobStore) error {
+	if store == nil {
+		return nil
+	}
+
+	if a, ok := m.message.MessageAttributes[claimcheck.AttributeKey]; ok {
+		data, err := store.Get(ctx, a.Value)
+		if err != nil {
+			return err
+		}
+		m.message.Message = string(data)
+		m.claimCheckKey = a.Value
+		return nil
+	}
+
+	a, ok := m.originalMessage.MessageAttributes[claimcheck.AttributeKey]
+	if !ok {
+		return nil
+	}
+
+	key := aws.ToString(a.StringValue)
+	data, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	// like the received body, the payload may be an SNS envelope
+	var msg sqsMessage
+	_ = json.Unmarshal(data, &msg)
+	m.message = msg
+	m.payload = data
+	m.claimCheckKey = key
+	return nil
+}
+
 // Metadata A map of the attributes requested in ReceiveMessage to their respective values.
 func (m *message) Metadata() map[string]string {
 	attr := map[string]string{}
@@ -53,25 +122,40 @@ func (m *message) Metadata() map[string]string {
 	return attr
 }
 
-// Decode will unmarshal the message body into a supplied output using JSON
+// Decode will unmarshal the message body into a supplied output,
+// using the codec named by the content-type attribute of the queue message, or the route codec
 func (m *message) Decode(out interface{}) error {
-	return json.Unmarshal(m.body(), &out)
+	attrs := make(map[string]string, 2)
+	for _, k := range []string{codec.ContentTypeAttribute, codec.ContentEncodingAttribute} {
+		if a, ok := m.originalMessage.MessageAttributes[k]; ok {
+			attrs[k] = aws.ToString(a.StringValue)
+		}
+	}
+	return codec.Decode(m.body(), attrs, out, m.codec)
 }
 
 // Attribute will return the custom attribute sent with the request.
+// SNS envelope attributes take precedence over the native SQS ones.
 func (m *message) Attribute(key string) string {
-	id, ok := m.message.MessageAttributes[key]
-	if !ok {
-		return ""
+	if a, ok := m.message.MessageAttributes[key]; ok {
+		return a.Value
 	}
 
-	return id.Value
+	if a, ok := m.originalMessage.MessageAttributes[key]; ok {
+		return nativeAttribute(a).String()
+	}
+	return ""
 }
 
-// Attributes will return the custom attributes sent with the request.
+// Attributes will return the custom attributes sent with the request,
+// merging the native SQS attributes with the SNS envelope ones.
 func (m *message) Attributes() map[string]string {
 	a := map[string]string{}
 
+	for k, v := range m.originalMessage.MessageAttributes {
+		a[k] = nativeAttribute(v).String()
+	}
+
 	for k, v := range m.message.MessageAttributes {
 		a[k] = v.Value
 	}
@@ -79,6 +163,39 @@ func (m *message) Attributes() map[string]string {
 	return a
 }
 
+// NativeAttributes will return the attributes set on the SQS message itself.
+func (m *message) NativeAttributes() map[string]loafergo.MessageAttribute {
+	a := make(map[string]loafergo.MessageAttribute, len(m.originalMessage.MessageAttributes))
+	for k, v := range m.originalMessage.MessageAttributes {
+		a[k] = nativeAttribute(v)
+	}
+	return a
+}
+
+// EnvelopeAttributes will return the attributes of the SNS notification envelope.
+// The envelope carries Binary values base64 encoded, they are decoded into BinaryValue.
+func (m *message) EnvelopeAttributes() map[string]loafergo.MessageAttribute {
+	a := make(map[string]loafergo.MessageAttribute, len(m.message.MessageAttributes))
+	for k, v := range m.message.MessageAttributes {
+		attr := loafergo.MessageAttribute{DataType: v.Type, StringValue: v.Value}
+		if strings.HasPrefix(v.Type, DataTypeBinary.String()) {
+			if b, err := base64.StdEncoding.DecodeString(v.Value); err == nil {
+				attr = loafergo.MessageAttribute{DataType: v.Type, BinaryValue: b}
+			}
+		}
+		a[k] = attr
+	}
+	return a
+}
+
+func nativeAttribute(v types.MessageAttributeValue) loafergo.MessageAttribute {
+	return loafergo.MessageAttribute{
+		DataType:    aws.ToString(v.DataType),
+		StringValue: aws.ToString(v.StringValue),
+		BinaryValue: v.BinaryValue,
+	}
+}
+
 // SystemAttributeByKey will return the system attributing by key.
 func (m *message) SystemAttributeByKey(key string) string {
 	value, ok := m.originalMessage.Attributes[key]
@@ -99,14 +216,29 @@ func (m *message) Identifier() string {
 	return *m.originalMessage.ReceiptHandle
 }
 
+// MessageID returns the identifier assigned to the message by SQS
+func (m *message) MessageID() string {
+	if m.originalMessage.MessageId != nil {
+		return *m.originalMessage.MessageId
+	}
+	return ""
+}
+
 // Message returns the body message
 func (m *message) Message() string {
 	return m.message.Message
 }
 
-// DecodeMessage will unmarshal the message into a supplied output using JSON
+// DecodeMessage will unmarshal the message into a supplied output,
+// using the codec named by the content-type attribute of the SNS envelope, or the route codec
 func (m *message) DecodeMessage(out any) error {
-	return json.Unmarshal([]byte(m.message.Message), &out)
+	attrs := make(map[string]string, 2)
+	for _, k := range []string{codec.ContentTypeAttribute, codec.ContentEncodingAttribute} {
+		if a, ok := m.message.MessageAttributes[k]; ok {
+			attrs[k] = a.Value
+		}
+	}
+	return codec.Decode([]byte(m.message.Message), attrs, out, m.codec)
 }
 
 // TimeStamp returns the message timestamp
@@ -114,9 +246,11 @@ func (m *message) TimeStamp() time.Time {
 	return m.message.Timestamp
 }
 
-// Dispatch sets dispatched as true
+// Dispatch sets dispatched as true, the calls after the first one do nothing
 func (m *message) Dispatch() {
-	m.dispatched <- true
+	m.dispatch.Do(func() {
+		m.dispatched <- true
+	})
 }
 
 // Backoff sets the visibilityTimeout of the message,
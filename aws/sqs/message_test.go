@@ -7,6 +7,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/codec"
 )
 
 var (
@@ -71,6 +74,56 @@ func TestMessage_Attributes(t *testing.T) {
 	})
 }
 
+func TestMessage_NativeAttributes(t *testing.T) {
+	msg := newMessage(types.Message{
+		Body: aws.String("raw body"),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"seller_id": {DataType: aws.String("Number"), StringValue: aws.String("123")},
+			"trace":     {DataType: aws.String("Binary"), BinaryValue: []byte("trace-id")},
+		},
+	})
+
+	assert.Equal(t, map[string]loafergo.MessageAttribute{
+		"seller_id": {DataType: "Number", StringValue: "123"},
+		"trace":     {DataType: "Binary", BinaryValue: []byte("trace-id")},
+	}, msg.NativeAttributes())
+	assert.Empty(t, msg.EnvelopeAttributes())
+
+	assert.Equal(t, "123", msg.Attribute("seller_id"))
+	assert.Equal(t, "dHJhY2UtaWQ=", msg.Attribute("trace"))
+	assert.Equal(t, map[string]string{"seller_id": "123", "trace": "dHJhY2UtaWQ="}, msg.Attributes())
+}
+
+func TestMessage_EnvelopeAttributes(t *testing.T) {
+	body := `{"Message": "hello", "MessageAttributes": {` +
+		`"foo": {"Type": "String", "Value": "envelope"}, ` +
+		`"count": {"Type": "Number", "Value": "42"}, ` +
+		`"trace": {"Type": "Binary", "Value": "dHJhY2UtaWQ="}}}`
+	msg := newMessage(types.Message{
+		Body: aws.String(body),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"foo":    {DataType: aws.String("String"), StringValue: aws.String("native")},
+			"native": {DataType: aws.String("String"), StringValue: aws.String("only")},
+		},
+	})
+
+	assert.Equal(t, map[string]loafergo.MessageAttribute{
+		"foo":   {DataType: "String", StringValue: "envelope"},
+		"count": {DataType: "Number", StringValue: "42"},
+		"trace": {DataType: "Binary", BinaryValue: []byte("trace-id")},
+	}, msg.EnvelopeAttributes())
+	assert.Equal(t, "native", msg.NativeAttributes()["foo"].StringValue)
+
+	assert.Equal(t, "envelope", msg.Attribute("foo"), "the envelope takes precedence")
+	assert.Equal(t, "only", msg.Attribute("native"))
+	assert.Equal(t, map[string]string{
+		"foo":    "envelope",
+		"count":  "42",
+		"trace":  "dHJhY2UtaWQ=",
+		"native": "only",
+	}, msg.Attributes())
+}
+
 func TestMessage_Body(t *testing.T) {
 	t.Run("With body", func(t *testing.T) {
 		msg := newMessage(types.Message{
@@ -111,6 +164,48 @@ func TestMessage_Decode(t *testing.T) {
 		assert.Error(t, err)
 		assert.ErrorContains(t, err, "unexpected end of JSON input")
 	})
+
+	t.Run("With the codec named by the attributes", func(t *testing.T) {
+		body, attrs, err := codec.Encode(codec.Gzip(codec.JSON), map[string]string{"foo": "bar"})
+		assert.NoError(t, err)
+
+		msgAttrs := map[string]types.MessageAttributeValue{}
+		for k, v := range attrs {
+			msgAttrs[k] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+		}
+		msg := newMessage(types.Message{Body: aws.String(body), MessageAttributes: msgAttrs})
+
+		var d map[string]string
+		assert.NoError(t, msg.Decode(&d))
+		assert.Equal(t, "bar", d["foo"])
+	})
+
+	t.Run("With the route codec", func(t *testing.T) {
+		body, _, err := codec.Encode(codec.Zstd(codec.JSON), map[string]string{"foo": "bar"})
+		assert.NoError(t, err)
+
+		msg := newMessage(types.Message{Body: aws.String(body)})
+		msg.codec = codec.Zstd(codec.JSON)
+
+		var d map[string]string
+		assert.NoError(t, msg.Decode(&d))
+		assert.Equal(t, "bar", d["foo"])
+	})
+
+	t.Run("With a content type set by an external producer", func(t *testing.T) {
+		for _, contentType := range []string{"text/plain", "text/plain; charset=utf-8", "application/json; charset=utf-8"} {
+			msg := newMessage(types.Message{
+				Body: aws.String(`{"foo": "bar"}`),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					codec.ContentTypeAttribute: {DataType: aws.String("String"), StringValue: aws.String(contentType)},
+				},
+			})
+
+			var d map[string]string
+			assert.NoError(t, msg.Decode(&d), contentType)
+			assert.Equal(t, "bar", d["foo"], contentType)
+		}
+	})
 }
 
 func TestMessage_Metadata(t *testing.T) {
@@ -134,6 +229,15 @@ func TestMessage_Dispatch(t *testing.T) {
 	assert.True(t, <-msg.dispatched)
 }
 
+func TestMessage_Dispatch_Twice(t *testing.T) {
+	msg := newMessage(types.Message{Body: &mockBody})
+	msg.Dispatch()
+	// the second call must not block on the buffered channel
+	msg.Dispatch()
+	assert.True(t, <-msg.dispatched)
+	assert.Empty(t, msg.dispatched)
+}
+
 func TestMessage_Message(t *testing.T) {
 	t.Run("With body", func(t *testing.T) {
 		msg := newMessage(types.Message{Body: &mockBody})
@@ -210,6 +314,32 @@ func TestMessage_MessageDecode(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, new(data), d)
 	})
+
+	t.Run("With the codec named by the envelope attributes", func(t *testing.T) {
+		body, _, err := codec.Encode(codec.Gzip(codec.JSON), map[string]string{"foo": "bar"})
+		assert.NoError(t, err)
+
+		msg := newMessage(types.Message{
+			Body: aws.String(`{"Message": "` + body + `", "MessageAttributes": {` +
+				`"content-type": {"Type": "String", "Value": "application/json"},` +
+				`"content-encoding": {"Type": "String", "Value": "gzip"}}}`),
+		})
+
+		var d map[string]string
+		assert.NoError(t, msg.DecodeMessage(&d))
+		assert.Equal(t, "bar", d["foo"])
+	})
+
+	t.Run("With a content type set by an external producer", func(t *testing.T) {
+		msg := newMessage(types.Message{
+			Body: aws.String(`{"Message": "{\"foo\": \"bar\"}", "MessageAttributes": {` +
+				`"content-type": {"Type": "String", "Value": "text/plain"}}}`),
+		})
+
+		var d map[string]string
+		assert.NoError(t, msg.DecodeMessage(&d))
+		assert.Equal(t, "bar", d["foo"])
+	})
 }
 
 func TestMessage_SystemAttributes(t *testing.T) {
@@ -246,3 +376,15 @@ func TestMessage_SystemAttributeByKey(t *testing.T) {
 		assert.Equal(t, "", attr)
 	})
 }
+
+func TestMessage_MessageID(t *testing.T) {
+	t.Run("With message id", func(t *testing.T) {
+		msg := newMessage(types.Message{MessageId: aws.String("message-id")})
+		assert.Equal(t, "message-id", msg.MessageID())
+	})
+
+	t.Run("Without message id", func(t *testing.T) {
+		msg := newMessage(types.Message{})
+		assert.Equal(t, "", msg.MessageID())
+	})
+}
@@ -0,0 +1,330 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+	"github.com/justcodes/loafer-go/v2/cloudevents"
+	"github.com/justcodes/loafer-go/v2/codec"
+)
+
+const (
+	// DefaultMaxBatchSize is the default max batch size for sqs
+	DefaultMaxBatchSize = 10
+)
+
+// Producer represents loafer sqs producer
+type Producer interface {
+	Produce(ctx context.Context, input *SendInput) (string, error)
+	ProduceBatch(ctx context.Context, input *SendBatchInput) (*SendBatchOutput, error)
+	ProduceEvent(ctx context.Context, input *SendEventInput) (string, error)
+}
+
+// ProducerConfig provides service configuration for an SQS producer.
+type ProducerConfig struct {
+	SQSClient loafergo.SQSClient
+	// BlobStore enables the claim-check pattern: the messages larger than OffloadThreshold
+	// are stored in it and a pointer is sent instead, see the claimcheck package.
+	BlobStore loafergo.BlobStore
+	// OffloadThreshold is the message size, attributes included, above which the message is offloaded.
+	// Defaults to claimcheck.DefaultThreshold.
+	OffloadThreshold int
+	// Codec marshals the Payload of the inputs, the content-type and content-encoding attributes are sent along.
+	// Defaults to codec.JSON.
+	Codec loafergo.Codec
+}
+
+// SendInput has the sqs message attributes
+type SendInput struct {
+	Attributes map[string]string
+	// Payload is marshaled with the producer codec and sent instead of Message when set
+	Payload         any
+	Message         string
+	GroupID         string
+	DeduplicationID string
+	QueueURL        string
+	// DelaySeconds postpones the delivery of the message, up to 15 minutes.
+	// It is not supported by FIFO queues, which only accept a queue-level delay.
+	DelaySeconds int32
+}
+
+// SendBatchInput holds the sqs batch send attributes
+type SendBatchInput struct {
+	QueueURL string
+	Messages []*SendBatchEntry
+}
+
+// SendBatchEntry holds the sqs batch send attributes
+// Each entry must have a unique ID to identify it in the request and response
+type SendBatchEntry struct {
+	Attributes map[string]string
+	// Payload is marshaled with the producer codec and sent instead of Message when set
+	Payload         any
+	ID              string
+	Message         string
+	GroupID         string
+	DeduplicationID string
+	DelaySeconds    int32
+}
+
+// SendBatchOutput holds the sqs batch send response
+type SendBatchOutput struct {
+	Failed     []*SendBatchEntryFailed
+	Successful []*SendBatchEntrySuccessful
+}
+
+// SendBatchEntrySuccessful holds the sqs batch send response
+type SendBatchEntrySuccessful struct {
+	EntryID   string
+	MessageID string
+}
+
+// SendBatchEntryFailed holds the sqs batch send response
+type SendBatchEntryFailed struct {
+	Err     error
+	EntryID string
+	Code    string
+	// SenderFault is true when the entry failed because of the request, so sending it again will fail too
+	SenderFault bool
+}
+
+// SendEventInput has the sqs attributes of a CloudEvents event
+type SendEventInput struct {
+	Event *cloudevents.Event
+	// Attributes are sent along with the event, the event attributes take precedence
+	Attributes map[string]string
+	QueueURL   string
+	GroupID    string
+	// DeduplicationID defaults to the event ID when GroupID is set
+	DeduplicationID string
+	Mode            cloudevents.Mode
+	DelaySeconds    int32
+}
+
+type producer struct {
+	sqs              loafergo.SQSClient
+	blobStore        loafergo.BlobStore
+	codec            loafergo.Codec
+	offloadThreshold int
+}
+
+// NewProducer creates a new Producer
+// It encapsulates the Amazon Simple Queue Service client to send messages directly to a queue
+func NewProducer(config *ProducerConfig) (Producer, error) {
+	if config == nil {
+		return nil, loafergo.ErrEmptyParam
+	}
+
+	if config.SQSClient == nil {
+		return nil, loafergo.ErrEmptyRequiredField
+	}
+
+	return &producer{
+		sqs:              config.SQSClient,
+		blobStore:        config.BlobStore,
+		codec:            config.Codec,
+		offloadThreshold: config.OffloadThreshold,
+	}, nil
+}
+
+// Produce sends a message to an Amazon SQS queue. When the queue is a FIFO queue,
+// the message must also contain a group ID and, when content-based deduplication is
+// disabled, a deduplication ID. The attributes are sent as String message attributes.
+//
+// When the producer has a BlobStore, a message larger than the offload threshold is stored
+// in it and a claim check pointer is sent instead.
+func (p *producer) Produce(ctx context.Context, input *SendInput) (string, error) {
+	if input == nil || reflect.DeepEqual(input, &SendInput{}) {
+		return "", loafergo.ErrEmptyInput
+	}
+
+	body, attributes, err := p.encode(ctx, input.Message, input.Payload, input.Attributes)
+	if err != nil {
+		return "", err
+	}
+
+	sendInp := &sqs.SendMessageInput{
+		MessageBody:  aws.String(body),
+		QueueUrl:     aws.String(input.QueueURL),
+		DelaySeconds: input.DelaySeconds,
+	}
+
+	if input.GroupID != "" {
+		sendInp.MessageGroupId = aws.String(input.GroupID)
+	}
+
+	if input.DeduplicationID != "" {
+		sendInp.MessageDeduplicationId = aws.String(input.DeduplicationID)
+	}
+
+	if len(attributes) > 0 {
+		sendInp.MessageAttributes = p.messageAttributes(attributes)
+	}
+
+	result, err := p.sqs.SendMessage(ctx, sendInp)
+	if err != nil {
+		return "", fmt.Errorf("failed to send message; queue: %s  error: %w", input.QueueURL, err)
+	}
+
+	return aws.ToString(result.MessageId), nil
+}
+
+// ProduceBatch sends a batch of messages to an Amazon SQS queue.
+// Each entry must have a unique ID to identify it in the request and response
+// When the queue is a FIFO queue, the messages must also contain a group ID
+// and, when content-based deduplication is disabled, a deduplication ID.
+//
+// The batch can partially fail, the result of each entry is reported in the output.
+func (p *producer) ProduceBatch(ctx context.Context, input *SendBatchInput) (*SendBatchOutput, error) {
+	if input == nil || reflect.DeepEqual(input, &SendBatchInput{}) || len(input.Messages) == 0 {
+		return nil, loafergo.ErrEmptyInput
+	}
+
+	if len(input.Messages) > DefaultMaxBatchSize {
+		return nil, fmt.Errorf("maximum batch size is %d", DefaultMaxBatchSize)
+	}
+
+	sendInp := &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(input.QueueURL),
+	}
+
+	for _, msg := range input.Messages {
+		body, attributes, err := p.encode(ctx, msg.Message, msg.Payload, msg.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := types.SendMessageBatchRequestEntry{
+			Id:           aws.String(msg.ID),
+			MessageBody:  aws.String(body),
+			DelaySeconds: msg.DelaySeconds,
+		}
+
+		if msg.GroupID != "" {
+			entry.MessageGroupId = aws.String(msg.GroupID)
+		}
+
+		if msg.DeduplicationID != "" {
+			entry.MessageDeduplicationId = aws.String(msg.DeduplicationID)
+		}
+
+		if len(attributes) > 0 {
+			entry.MessageAttributes = p.messageAttributes(attributes)
+		}
+
+		sendInp.Entries = append(sendInp.Entries, entry)
+	}
+
+	result, err := p.sqs.SendMessageBatch(ctx, sendInp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send messages; queue: %s  error: %w", input.QueueURL, err)
+	}
+
+	return &SendBatchOutput{
+		Failed:     p.getFailedEntries(result.Failed),
+		Successful: p.getSuccessfulEntries(result.Successful),
+	}, nil
+}
+
+// ProduceEvent sends a CloudEvents event to an Amazon SQS queue, in structured or binary mode.
+// The consumers read it back with cloudevents.FromMessage.
+func (p *producer) ProduceEvent(ctx context.Context, input *SendEventInput) (string, error) {
+	if input == nil || input.Event == nil {
+		return "", loafergo.ErrEmptyInput
+	}
+
+	body, attrs, err := cloudevents.Encode(input.Event, input.Mode)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range input.Attributes {
+		if _, ok := attrs[k]; !ok {
+			attrs[k] = v
+		}
+	}
+
+	deduplicationID := input.DeduplicationID
+	if deduplicationID == "" && input.GroupID != "" {
+		deduplicationID = input.Event.ID
+	}
+
+	return p.Produce(ctx, &SendInput{
+		Attributes:      attrs,
+		Message:         body,
+		GroupID:         input.GroupID,
+		DeduplicationID: deduplicationID,
+		QueueURL:        input.QueueURL,
+		DelaySeconds:    input.DelaySeconds,
+	})
+}
+
+func (p *producer) getSuccessfulEntries(entries []types.SendMessageBatchResultEntry) []*SendBatchEntrySuccessful {
+	successful := make([]*SendBatchEntrySuccessful, len(entries))
+	for i, entry := range entries {
+		successful[i] = &SendBatchEntrySuccessful{
+			EntryID:   aws.ToString(entry.Id),
+			MessageID: aws.ToString(entry.MessageId),
+		}
+	}
+	return successful
+}
+
+func (p *producer) getFailedEntries(entries []types.BatchResultErrorEntry) []*SendBatchEntryFailed {
+	failed := make([]*SendBatchEntryFailed, len(entries))
+	for i, entry := range entries {
+		failed[i] = &SendBatchEntryFailed{
+			EntryID:     aws.ToString(entry.Id),
+			Code:        aws.ToString(entry.Code),
+			SenderFault: entry.SenderFault,
+			Err:         fmt.Errorf("failed to send message; error: %s", aws.ToString(entry.Message)),
+		}
+	}
+	return failed
+}
+
+func (p *producer) messageAttributes(attr map[string]string) map[string]types.MessageAttributeValue {
+	ma := make(map[string]types.MessageAttributeValue)
+	for k, v := range attr {
+		ma[k] = types.MessageAttributeValue{
+			DataType:    aws.String(DataTypeString.String()),
+			StringValue: aws.String(v),
+		}
+	}
+	return ma
+}
+
+// encode marshals the payload with the producer codec, when set instead of the message,
+// and offloads the resulting body when it is too large
+func (p *producer) encode(
+	ctx context.Context,
+	message string,
+	payload any,
+	attributes map[string]string,
+) (string, map[string]string, error) {
+	if payload != nil {
+		c := p.codec
+		if c == nil {
+			c = codec.JSON
+		}
+
+		body, attrs, err := codec.Encode(c, payload)
+		if err != nil {
+			return "", nil, err
+		}
+		for k, v := range attributes {
+			if _, ok := attrs[k]; !ok {
+				attrs[k] = v
+			}
+		}
+		message, attributes = body, attrs
+	}
+
+	return claimcheck.Offload(ctx, p.blobStore, message, attributes, p.offloadThreshold)
+}
@@ -0,0 +1,367 @@
+package sqs_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/aws/sqs"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+	"github.com/justcodes/loafer-go/v2/cloudevents"
+	"github.com/justcodes/loafer-go/v2/codec"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+const queueURL = "https://sqs.us-east-1.amazonaws.com/0000000/my_queue"
+
+type producerSuite struct {
+	suite.Suite
+	sqsClient *fake.SQSClient
+	producer  sqs.Producer
+}
+
+func TestProducerSuite(t *testing.T) {
+	suite.Run(t, new(producerSuite))
+}
+
+func (suite *producerSuite) SetupSuite() {
+	suite.sqsClient = fake.NewSQSClient(suite.T())
+	suite.producer, _ = sqs.NewProducer(&sqs.ProducerConfig{
+		SQSClient: suite.sqsClient,
+	})
+}
+
+func (suite *producerSuite) TearDownSuite() {
+	suite.SetupSuite()
+}
+
+func (suite *producerSuite) TestNewProducerWithError() {
+	suite.Run("With Config nil", func() {
+		p, err := sqs.NewProducer(nil)
+		suite.Nil(p)
+		suite.ErrorIs(err, loafergo.ErrEmptyParam)
+	})
+
+	suite.Run("With Client nil", func() {
+		p, err := sqs.NewProducer(&sqs.ProducerConfig{})
+		suite.Nil(p)
+		suite.ErrorIs(err, loafergo.ErrEmptyRequiredField)
+	})
+}
+
+func (suite *producerSuite) TestProduce() {
+	ctx := context.Background()
+	suite.Run("Should produce with Success", func() {
+		input := sqs.SendInput{
+			Message:  "my message",
+			QueueURL: queueURL,
+		}
+
+		param := &awsSqs.SendMessageInput{
+			MessageBody: aws.String("my message"),
+			QueueUrl:    aws.String(queueURL),
+		}
+
+		suite.sqsClient.On("SendMessage", ctx, param).
+			Return(&awsSqs.SendMessageOutput{MessageId: aws.String("id")}, nil).
+			Once()
+
+		got, err := suite.producer.Produce(ctx, &input)
+		suite.NoError(err)
+		suite.Equal("id", got)
+	})
+
+	suite.Run("Should produce with all fields", func() {
+		input := sqs.SendInput{
+			Message:         "my message",
+			GroupID:         "my-group",
+			DeduplicationID: "dedup-id",
+			QueueURL:        queueURL,
+			DelaySeconds:    30,
+			Attributes: map[string]string{
+				"custom": "custom_value",
+			},
+		}
+
+		param := &awsSqs.SendMessageInput{
+			MessageBody:  aws.String("my message"),
+			QueueUrl:     aws.String(queueURL),
+			DelaySeconds: 30,
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"custom": {DataType: aws.String("String"), StringValue: aws.String("custom_value")},
+			},
+			MessageGroupId:         aws.String("my-group"),
+			MessageDeduplicationId: aws.String("dedup-id"),
+		}
+
+		suite.sqsClient.On("SendMessage", ctx, param).
+			Return(&awsSqs.SendMessageOutput{MessageId: aws.String("id")}, nil).
+			Once()
+
+		got, err := suite.producer.Produce(ctx, &input)
+		suite.NoError(err)
+		suite.Equal("id", got)
+	})
+
+	suite.Run("SQS SendMessage error", func() {
+		input := sqs.SendInput{
+			Message:  "my message",
+			QueueURL: queueURL,
+		}
+
+		param := &awsSqs.SendMessageInput{
+			MessageBody: aws.String("my message"),
+			QueueUrl:    aws.String(queueURL),
+		}
+
+		suite.sqsClient.On("SendMessage", ctx, param).
+			Return(nil, fmt.Errorf("got error")).
+			Once()
+
+		got, err := suite.producer.Produce(ctx, &input)
+		suite.Empty(got)
+		suite.ErrorContains(err, "got error")
+	})
+
+	suite.Run("Should offload a message larger than the threshold", func() {
+		store := fake.NewBlobStore(suite.T())
+		producer, err := sqs.NewProducer(&sqs.ProducerConfig{
+			SQSClient:        suite.sqsClient,
+			BlobStore:        store,
+			OffloadThreshold: 5,
+		})
+		suite.Require().NoError(err)
+
+		var key string
+		store.On("Put", ctx, mock.Anything, []byte("my message")).
+			Run(func(args mock.Arguments) { key = args.String(1) }).
+			Return(nil).
+			Once()
+		suite.sqsClient.On("SendMessage", ctx, mock.MatchedBy(func(in *awsSqs.SendMessageInput) bool {
+			return aws.ToString(in.MessageBody) == `{"loaferClaimCheck":"`+key+`"}` &&
+				aws.ToString(in.MessageAttributes[claimcheck.AttributeKey].StringValue) == key
+		})).
+			Return(&awsSqs.SendMessageOutput{MessageId: aws.String("id")}, nil).
+			Once()
+
+		got, err := producer.Produce(ctx, &sqs.SendInput{Message: "my message", QueueURL: queueURL})
+		suite.NoError(err)
+		suite.Equal("id", got)
+	})
+
+	suite.Run("Should not send the message when it cannot be offloaded", func() {
+		store := fake.NewBlobStore(suite.T())
+		producer, err := sqs.NewProducer(&sqs.ProducerConfig{
+			SQSClient:        suite.sqsClient,
+			BlobStore:        store,
+			OffloadThreshold: 5,
+		})
+		suite.Require().NoError(err)
+
+		store.On("Put", ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("got error")).Once()
+
+		got, err := producer.Produce(ctx, &sqs.SendInput{Message: "my message", QueueURL: queueURL})
+		suite.Empty(got)
+		suite.ErrorIs(err, loafergo.ErrClaimCheck)
+	})
+
+	suite.Run("Should marshal the payload with the producer codec", func() {
+		producer, err := sqs.NewProducer(&sqs.ProducerConfig{
+			SQSClient: suite.sqsClient,
+			Codec:     codec.Gzip(codec.JSON),
+		})
+		suite.Require().NoError(err)
+
+		body, _, err := codec.Encode(codec.Gzip(codec.JSON), map[string]string{"foo": "bar"})
+		suite.Require().NoError(err)
+
+		param := &awsSqs.SendMessageInput{
+			MessageBody: aws.String(body),
+			QueueUrl:    aws.String(queueURL),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"custom":                       {DataType: aws.String("String"), StringValue: aws.String("custom_value")},
+				codec.ContentTypeAttribute:     {DataType: aws.String("String"), StringValue: aws.String("application/json")},
+				codec.ContentEncodingAttribute: {DataType: aws.String("String"), StringValue: aws.String("gzip")},
+			},
+		}
+
+		suite.sqsClient.On("SendMessage", ctx, param).
+			Return(&awsSqs.SendMessageOutput{MessageId: aws.String("id")}, nil).
+			Once()
+
+		got, err := producer.Produce(ctx, &sqs.SendInput{
+			Payload:    map[string]string{"foo": "bar"},
+			Attributes: map[string]string{"custom": "custom_value"},
+			QueueURL:   queueURL,
+		})
+		suite.NoError(err)
+		suite.Equal("id", got)
+	})
+
+	suite.Run("With Input nil", func() {
+		got, err := suite.producer.Produce(ctx, nil)
+		suite.Empty(got)
+		suite.ErrorIs(err, loafergo.ErrEmptyInput)
+	})
+}
+
+func (suite *producerSuite) TestProduceEvent() {
+	ctx := context.Background()
+	event := &cloudevents.Event{ID: "event-1", Source: "/orders", Type: "created", Data: []byte(`{"id":"1"}`)}
+
+	suite.Run("Should send the event in binary mode", func() {
+		param := &awsSqs.SendMessageInput{
+			MessageBody: aws.String(`{"id":"1"}`),
+			QueueUrl:    aws.String(queueURL),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"ce-specversion": {DataType: aws.String("String"), StringValue: aws.String("1.0")},
+				"ce-id":          {DataType: aws.String("String"), StringValue: aws.String("event-1")},
+				"ce-source":      {DataType: aws.String("String"), StringValue: aws.String("/orders")},
+				"ce-type":        {DataType: aws.String("String"), StringValue: aws.String("created")},
+				"custom":         {DataType: aws.String("String"), StringValue: aws.String("custom_value")},
+			},
+			MessageGroupId:         aws.String("my-group"),
+			MessageDeduplicationId: aws.String("event-1"),
+		}
+
+		suite.sqsClient.On("SendMessage", ctx, param).
+			Return(&awsSqs.SendMessageOutput{MessageId: aws.String("id")}, nil).
+			Once()
+
+		got, err := suite.producer.ProduceEvent(ctx, &sqs.SendEventInput{
+			Event:      event,
+			Attributes: map[string]string{"custom": "custom_value"},
+			QueueURL:   queueURL,
+			GroupID:    "my-group",
+			Mode:       cloudevents.Binary,
+		})
+		suite.NoError(err)
+		suite.Equal("id", got)
+	})
+
+	suite.Run("With an invalid event", func() {
+		got, err := suite.producer.ProduceEvent(ctx, &sqs.SendEventInput{Event: &cloudevents.Event{ID: "event-1"}, QueueURL: queueURL})
+		suite.Empty(got)
+		suite.ErrorIs(err, loafergo.ErrInvalidCloudEvent)
+	})
+
+	suite.Run("With Input nil", func() {
+		got, err := suite.producer.ProduceEvent(ctx, nil)
+		suite.Empty(got)
+		suite.ErrorIs(err, loafergo.ErrEmptyInput)
+	})
+}
+
+func (suite *producerSuite) TestProduceBatch() {
+	ctx := context.Background()
+	suite.Run("Should report each entry result", func() {
+		input := sqs.SendBatchInput{
+			Messages: []*sqs.SendBatchEntry{
+				{
+					ID:           "id1",
+					Message:      "my message 1",
+					DelaySeconds: 10,
+					Attributes:   map[string]string{"custom": "custom_value"},
+				},
+				{
+					ID:              "id2",
+					Message:         "my message 2",
+					GroupID:         "my-group",
+					DeduplicationID: "dedup-id",
+				},
+			},
+			QueueURL: queueURL,
+		}
+
+		param := &awsSqs.SendMessageBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries: []types.SendMessageBatchRequestEntry{
+				{
+					Id:           aws.String("id1"),
+					MessageBody:  aws.String("my message 1"),
+					DelaySeconds: 10,
+					MessageAttributes: map[string]types.MessageAttributeValue{
+						"custom": {DataType: aws.String("String"), StringValue: aws.String("custom_value")},
+					},
+				},
+				{
+					Id:                     aws.String("id2"),
+					MessageBody:            aws.String("my message 2"),
+					MessageGroupId:         aws.String("my-group"),
+					MessageDeduplicationId: aws.String("dedup-id"),
+				},
+			},
+		}
+
+		suite.sqsClient.On("SendMessageBatch", ctx, param).
+			Return(&awsSqs.SendMessageBatchOutput{
+				Successful: []types.SendMessageBatchResultEntry{
+					{Id: aws.String("id1"), MessageId: aws.String("message-id1")},
+				},
+				Failed: []types.BatchResultErrorEntry{
+					{
+						Id:          aws.String("id2"),
+						Code:        aws.String("InvalidParameterValue"),
+						Message:     aws.String("invalid group"),
+						SenderFault: true,
+					},
+				},
+			}, nil).
+			Once()
+
+		got, err := suite.producer.ProduceBatch(ctx, &input)
+		suite.NoError(err)
+		suite.Len(got.Successful, 1)
+		suite.Equal("id1", got.Successful[0].EntryID)
+		suite.Equal("message-id1", got.Successful[0].MessageID)
+		suite.Len(got.Failed, 1)
+		suite.Equal("id2", got.Failed[0].EntryID)
+		suite.Equal("InvalidParameterValue", got.Failed[0].Code)
+		suite.True(got.Failed[0].SenderFault)
+		suite.ErrorContains(got.Failed[0].Err, "invalid group")
+	})
+
+	suite.Run("SQS SendMessageBatch error", func() {
+		input := sqs.SendBatchInput{
+			Messages: []*sqs.SendBatchEntry{{ID: "id1", Message: "my message 1"}},
+			QueueURL: queueURL,
+		}
+
+		suite.sqsClient.On("SendMessageBatch", ctx, &awsSqs.SendMessageBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries: []types.SendMessageBatchRequestEntry{
+				{Id: aws.String("id1"), MessageBody: aws.String("my message 1")},
+			},
+		}).
+			Return(nil, fmt.Errorf("got error")).
+			Once()
+
+		got, err := suite.producer.ProduceBatch(ctx, &input)
+		suite.Nil(got)
+		suite.ErrorContains(err, "got error")
+	})
+
+	suite.Run("With too many messages", func() {
+		input := sqs.SendBatchInput{QueueURL: queueURL}
+		for i := 0; i <= sqs.DefaultMaxBatchSize; i++ {
+			input.Messages = append(input.Messages, &sqs.SendBatchEntry{ID: fmt.Sprint(i), Message: "my message"})
+		}
+
+		got, err := suite.producer.ProduceBatch(ctx, &input)
+		suite.Nil(got)
+		suite.EqualError(err, "maximum batch size is 10")
+	})
+
+	suite.Run("With Input nil", func() {
+		got, err := suite.producer.ProduceBatch(ctx, nil)
+		suite.Nil(got)
+		suite.ErrorIs(err, loafergo.ErrEmptyInput)
+	})
+}
@@ -2,32 +2,50 @@ package sqs
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 
 	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
 )
 
 const (
 	all                             = "All"
 	defaultVisibilityTimeoutControl = 10
+
+	messageGroupID          = "MessageGroupId"
+	messageDeduplicationID  = "MessageDeduplicationId"
+	approximateReceiveCount = "ApproximateReceiveCount"
 )
 
 type route struct {
-	sqs               loafergo.SQSClient
-	handler           loafergo.Handler
-	queueName         string
-	queueURL          string
-	customGroupFields []string
-	extensionLimit    int
-	runMode           loafergo.Mode
-	visibilityTimeout int32
-	maxMessages       int32
-	waitTimeSeconds   int32
-	workerPoolSize    int32
+	sqs                loafergo.SQSClient
+	handler            loafergo.Handler
+	batchHandler       loafergo.BatchHandler
+	deadLetterFunc     DeadLetterFunc
+	blobStore          loafergo.BlobStore
+	claimCheck         claimcheck.Config
+	codec              loafergo.Codec
+	retryPolicy        *loafergo.RetryPolicy
+	scalingPolicy      *loafergo.ScalingPolicy
+	batcher            *commitBatcher
+	queueName          string
+	queueURL           string
+	deadLetterQueue    string
+	deadLetterQueueURL string
+	customGroupFields  []string
+	extensionLimit     int
+	runMode            loafergo.Mode
+	visibilityTimeout  int32
+	maxMessages        int32
+	waitTimeSeconds    int32
+	workerPoolSize     int32
+	maxReceiveCount    int32
 }
 
 // DoneCtxKey is the context key for the done channel that is optionally passed to the router
@@ -61,9 +79,15 @@ func NewRoute(config *Config, optFns ...func(config *RouteConfig)) loafergo.Rout
 		optFn(cfg)
 	}
 
-	return &route{
+	handler := config.Handler
+	if handler != nil {
+		handler = loafergo.Chain(cfg.middlewares...)(handler)
+	}
+
+	r := &route{
 		sqs:               config.SQSClient,
-		handler:           config.Handler,
+		handler:           handler,
+		batchHandler:      config.BatchHandler,
 		queueName:         config.QueueName,
 		extensionLimit:    cfg.extensionLimit,
 		visibilityTimeout: cfg.visibilityTimeout,
@@ -72,7 +96,20 @@ func NewRoute(config *Config, optFns ...func(config *RouteConfig)) loafergo.Rout
 		workerPoolSize:    cfg.workerPoolSize,
 		runMode:           cfg.runMode,
 		customGroupFields: cfg.customGroupFields,
+		maxReceiveCount:   cfg.maxReceiveCount,
+		deadLetterQueue:   cfg.deadLetterQueue,
+		deadLetterFunc:    cfg.deadLetterFunc,
+		blobStore:         cfg.blobStore,
+		claimCheck:        cfg.claimCheck,
+		codec:             cfg.codec,
+		retryPolicy:       cfg.retryPolicy,
+		scalingPolicy:     cfg.scalingPolicy,
+	}
+
+	if cfg.batchCommit {
+		r.batcher = newCommitBatcher(r.sqs, func() string { return r.queueURL }, cfg.batchCommitSize, cfg.batchCommitDelay)
 	}
+	return r
 }
 
 // Configure sets the queue url to route
@@ -88,17 +125,47 @@ func (r *route) Configure(ctx context.Context) error {
 	}
 
 	r.queueURL = *o.QueueUrl
+
+	if r.deadLetterQueue != "" {
+		o, err = r.sqs.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: &r.deadLetterQueue})
+		if err != nil {
+			return err
+		}
+		r.deadLetterQueueURL = *o.QueueUrl
+	}
 	return nil
 }
 
 // GetMessages gets messages from queue
-func (r *route) GetMessages(ctx context.Context, logger loafergo.Logger) (messages []loafergo.Message, err error) {
+func (r *route) GetMessages(ctx context.Context, logger loafergo.Logger) ([]loafergo.Message, error) {
+	return r.receive(ctx, logger, r.maxMessages)
+}
+
+// GetMessagesUpTo gets at most maxMessages messages from queue, and never more than the route max messages
+func (r *route) GetMessagesUpTo(ctx context.Context, logger loafergo.Logger, maxMessages int32) ([]loafergo.Message, error) {
+	return r.receive(ctx, logger, max(1, min(maxMessages, r.maxMessages)))
+}
+
+// PickUp starts extending the visibility of the message for its handling, once a worker is about to handle it.
+// Until then, the message is only kept invisible, so the extension limit applies from the moment it is picked up.
+func (r *route) PickUp(ctx context.Context, m loafergo.Message) {
+	msg, ok := m.(*message)
+	if !ok {
+		return
+	}
+	msg.pickedUp.Do(func() {
+		close(msg.pickUp)
+	})
+}
+
+func (r *route) receive(ctx context.Context, logger loafergo.Logger, maxMessages int32) (messages []loafergo.Message, err error) {
 	output, err := r.sqs.ReceiveMessage(
 		ctx,
 		&sqs.ReceiveMessageInput{
 			QueueUrl:                    &r.queueURL,
 			WaitTimeSeconds:             r.waitTimeSeconds,
-			MaxNumberOfMessages:         r.maxMessages,
+			VisibilityTimeout:           r.visibilityTimeout,
+			MaxNumberOfMessages:         maxMessages,
 			MessageAttributeNames:       []string{all},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
 		},
@@ -109,15 +176,18 @@ func (r *route) GetMessages(ctx context.Context, logger loafergo.Logger) (messag
 
 	for _, m := range output.Messages {
 		msg := newMessage(m)
+		msg.codec = r.codec
+		msg.logger = logger
 		messages = append(messages, msg)
-		// change the message visibility
-		go r.changeMessageVisibility(ctx, msg, logger)
+		go r.extendVisibility(ctx, msg, logger)
 	}
 
 	return
 }
 
-// Commit deletes the message from the queue
+// Commit deletes the message from the queue,
+// and its offloaded payload from the blob store when the route was configured with claimcheck.DeleteOnCommit.
+// With RouteWithBatchCommit, the message is deleted in the background and Commit returns once it is buffered.
 func (r *route) Commit(ctx context.Context, m loafergo.Message) error {
 	// if the handler backed off the message, we should not delete it
 	if m.BackedOff() {
@@ -126,6 +196,19 @@ func (r *route) Commit(ctx context.Context, m loafergo.Message) error {
 
 	defer m.Dispatch()
 	identifier := m.Identifier()
+	if r.batcher != nil {
+		ctx = context.WithoutCancel(ctx)
+		r.batcher.delete(ctx, identifier, func(err error) {
+			if err == nil {
+				err = r.deleteClaimCheck(ctx, m)
+			}
+			if err != nil {
+				r.commitFailed(ctx, m, err)
+			}
+		})
+		return nil
+	}
+
 	_, err := r.sqs.DeleteMessage(
 		ctx,
 		&sqs.DeleteMessageInput{QueueUrl: &r.queueURL, ReceiptHandle: &identifier},
@@ -133,19 +216,148 @@ func (r *route) Commit(ctx context.Context, m loafergo.Message) error {
 	if err != nil {
 		return err
 	}
-	return err
+	return r.deleteClaimCheck(ctx, m)
+}
+
+// Flush deletes the messages buffered by RouteWithBatchCommit and waits for the batches being deleted,
+// the Manager calls it once the workers of the route stopped
+func (r *route) Flush(ctx context.Context) {
+	if r.batcher != nil {
+		r.batcher.drain(ctx)
+	}
+}
+
+// deleteClaimCheck deletes the offloaded payload of a deleted message, when the route is configured to.
+// The payload is only deleted once the message can no longer be received.
+func (r *route) deleteClaimCheck(ctx context.Context, m loafergo.Message) error {
+	msg, ok := m.(*message)
+	if !ok || msg.claimCheckKey == "" || !r.claimCheck.DeleteOnCommit {
+		return nil
+	}
+	if err := r.blobStore.Delete(ctx, msg.claimCheckKey); err != nil {
+		return loafergo.ErrClaimCheck.Context(err)
+	}
+	return nil
+}
+
+// commitFailed reports a message whose deletion failed in the background, as the Manager does for a failed Commit
+func (r *route) commitFailed(ctx context.Context, m loafergo.Message, err error) {
+	loafergo.MetricsFromContext(ctx).CommitError(r.queueName, err)
+
+	log := slog.Default()
+	if msg, ok := m.(*message); ok && msg.logger != nil {
+		log = loafergo.StructuredLogger(msg.logger)
+	}
+	log.ErrorContext(ctx, "commit_message_error",
+		"queue", r.queueName, "error", err, "message_id", m.MessageID(), "identifier", m.Identifier())
 }
 
 // HandlerMessage consumes the message from the queue
+// Messages that exceeded the max receive count are dead-lettered instead of handled,
+// the offloaded payload of the others is fetched before calling the handler.
+// A message the handler fails is retried following the retry policy, when the route has one.
+// The visibility of a message the Manager did not pick up starts being extended here.
 func (r *route) HandlerMessage(ctx context.Context, msg loafergo.Message) error {
+	r.PickUp(ctx, msg)
+	defer func() {
+		if v := recover(); v != nil {
+			// stop extending the message visibility before handing the panic over
+			msg.Dispatch()
+			panic(v)
+		}
+	}()
+
+	if r.exceededMaxReceiveCount(msg) {
+		if err := r.deadLetter(ctx, msg); err != nil {
+			msg.Dispatch()
+			return loafergo.ErrDeadLetter.Context(err)
+		}
+		return nil
+	}
+
+	if err := r.resolveClaimCheck(ctx, msg); err != nil {
+		msg.Dispatch()
+		return err
+	}
+
 	err := r.handler(ctx, msg)
 	if err != nil {
-		msg.Dispatch()
+		r.settle(msg, err)
 		return err
 	}
 	return nil
 }
 
+// BatchMode reports whether the route was created with a batch handler
+func (r *route) BatchMode(ctx context.Context) bool {
+	return r.batchHandler != nil
+}
+
+// HandlerBatch consumes all the messages of a receive call at once
+// Messages that exceeded the max receive count are dead-lettered instead of handled,
+// the failed ones stop having their visibility extended, or are retried following the retry policy,
+// so they are received again
+// The visibility of the messages the Manager did not pick up starts being extended here.
+func (r *route) HandlerBatch(ctx context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+	for _, msg := range msgs {
+		r.PickUp(ctx, msg)
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			for _, msg := range msgs {
+				msg.Dispatch()
+			}
+			panic(v)
+		}
+	}()
+
+	result := loafergo.BatchResult{}
+	batch := make([]loafergo.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if !r.exceededMaxReceiveCount(msg) {
+			if err := r.resolveClaimCheck(ctx, msg); err != nil {
+				result.Fail(msg, err)
+				continue
+			}
+			batch = append(batch, msg)
+			continue
+		}
+		if err := r.deadLetter(ctx, msg); err != nil {
+			result.Fail(msg, loafergo.ErrDeadLetter.Context(err))
+		}
+	}
+
+	handled := make(map[string]loafergo.Message, len(batch))
+	if len(batch) > 0 {
+		for _, msg := range batch {
+			handled[msg.Identifier()] = msg
+		}
+		for id, err := range r.batchHandler(ctx, batch) {
+			if err != nil {
+				result[id] = err
+			}
+		}
+	}
+
+	for _, msg := range msgs {
+		err := result.Err(msg)
+		if err == nil {
+			continue
+		}
+		if _, ok := handled[msg.Identifier()]; ok {
+			r.settle(msg, err)
+			continue
+		}
+		msg.Dispatch()
+	}
+	return result
+}
+
+// Name returns the name of the queue consumed by the route
+func (r *route) Name(ctx context.Context) string {
+	return r.queueName
+}
+
 // WorkerPoolSize returns the router worker pool size
 func (r *route) WorkerPoolSize(ctx context.Context) int32 {
 	return r.workerPoolSize
@@ -161,13 +373,55 @@ func (r *route) RunMode(ctx context.Context) loafergo.Mode {
 	return r.runMode
 }
 
+// ScalingPolicy returns the policy resizing the worker pool of the route, nil when it has a fixed size
+func (r *route) ScalingPolicy(ctx context.Context) *loafergo.ScalingPolicy {
+	return r.scalingPolicy
+}
+
+// Backlog returns the approximate number of messages available in the queue
+func (r *route) Backlog(ctx context.Context) (int, error) {
+	output, err := r.sqs.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       &r.queueURL,
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(output.Attributes[string(types.QueueAttributeNameApproximateNumberOfMessages)])
+}
+
 // CustomGroupFields returns the router custom group fields
 func (r *route) CustomGroupFields(ctx context.Context) []string {
 	return r.customGroupFields
 }
 
+// extendVisibility keeps a received message invisible while it waits for a worker, e.g. behind a busy
+// worker in PerGroupID mode, then extends its visibility while it is handled, see changeMessageVisibility.
+// The message was received with the route visibility timeout, it is renewed before it expires.
+func (r *route) extendVisibility(ctx context.Context, m *message, logger loafergo.Logger) {
+	ticker := time.NewTicker(time.Duration(r.visibilityTimeout-defaultVisibilityTimeoutControl) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.pickUp:
+			r.changeMessageVisibility(ctx, m, logger)
+			return
+		case <-m.dispatched:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.doChangeVisibilityTimeout(ctx, m, r.visibilityTimeout, logger) == nil {
+				loafergo.MetricsFromContext(ctx).VisibilityExtended(r.queueName, time.Duration(r.visibilityTimeout)*time.Second)
+			}
+		}
+	}
+}
+
 func (r *route) changeMessageVisibility(ctx context.Context, m *message, logger loafergo.Logger) {
 	var count int
+	metrics := loafergo.MetricsFromContext(ctx)
 	extension := r.visibilityTimeout
 	sleepTime := time.Duration(r.visibilityTimeout-defaultVisibilityTimeoutControl) * time.Second
 	ticker := time.NewTicker(sleepTime)
@@ -183,20 +437,26 @@ func (r *route) changeMessageVisibility(ctx context.Context, m *message, logger
 
 		select {
 		case d := <-m.backoffChannel:
-			r.doChangeVisibilityTimeout(ctx, m, int32(d.Seconds()), logger)
+			if r.doChangeVisibilityTimeout(ctx, m, int32(d.Seconds()), logger) == nil {
+				metrics.BackedOff(r.queueName, d)
+			}
 			return
 		case <-m.dispatched:
 			return
+		case <-ctx.Done():
+			return
 		case <-ticker.C:
 			count++
 			// double the allowed processing time
 			extension += r.visibilityTimeout
-			r.doChangeVisibilityTimeout(ctx, m, extension, logger)
+			if r.doChangeVisibilityTimeout(ctx, m, extension, logger) == nil {
+				metrics.VisibilityExtended(r.queueName, time.Duration(extension)*time.Second)
+			}
 		}
 	}
 }
 
-func (r *route) doChangeVisibilityTimeout(ctx context.Context, m *message, timeout int32, logger loafergo.Logger) {
+func (r *route) doChangeVisibilityTimeout(ctx context.Context, m *message, timeout int32, logger loafergo.Logger) error {
 	if timeout < 0 {
 		timeout = 0
 	}
@@ -207,11 +467,13 @@ func (r *route) doChangeVisibilityTimeout(ctx context.Context, m *message, timeo
 		timeout = maxLimit
 	}
 
-	logMsg := fmt.Sprintf(
-		"change_visibility_timeout; queueUrl: %s; receipt_hendler: %s; timeout: %ds",
-		r.queueURL, *m.originalMessage.ReceiptHandle, timeout,
+	log := loafergo.StructuredLogger(logger).With(
+		"queue", r.queueName,
+		"message_id", m.MessageID(),
+		"identifier", *m.originalMessage.ReceiptHandle,
+		"timeout", time.Duration(timeout)*time.Second,
 	)
-	logger.Log(logMsg)
+	log.Debug("change_visibility_timeout")
 	_, err := r.sqs.ChangeMessageVisibility(
 		ctx,
 		&sqs.ChangeMessageVisibilityInput{
@@ -221,17 +483,101 @@ func (r *route) doChangeVisibilityTimeout(ctx context.Context, m *message, timeo
 		},
 	)
 	if err != nil {
-		logMsg = fmt.Sprintf(
-			"change_visibility_timeout_error: %v; queueUrl: %s; receipt_hendler: %s; timeout: %ds",
-			err, r.queueURL, *m.originalMessage.ReceiptHandle, timeout,
-		)
-		logger.Log(logMsg)
+		log.Warn("change_visibility_timeout_error", "error", err)
 	}
 
 	done, ok := ctx.Value(DoneCtxKey{}).(chan bool)
 	if ok {
 		done <- true
 	}
+	return err
+}
+
+// settle changes the visibility of a handled message according to its outcome, see loafergo.Outcome:
+// a Nack is retried following the retry policy and a RetryAfter is backed off by its delay.
+// The other outcomes are settled by the Manager, which commits the message.
+func (r *route) settle(msg loafergo.Message, err error) {
+	outcome := loafergo.OutcomeOf(err)
+	switch outcome.Kind() {
+	case loafergo.OutcomeNack:
+		r.retry(msg, err)
+	case loafergo.OutcomeRetryAfter:
+		if msg.BackedOff() {
+			msg.Dispatch()
+			return
+		}
+		msg.Backoff(outcome.Delay())
+	}
+}
+
+// retry schedules the next delivery of a message the handler failed, following the route retry policy.
+// Without policy, or when the handler backed the message off itself, it only stops extending its visibility.
+func (r *route) retry(msg loafergo.Message, err error) {
+	if r.retryPolicy == nil || msg.BackedOff() {
+		msg.Dispatch()
+		return
+	}
+
+	attempt, _ := strconv.Atoi(msg.SystemAttributeByKey(approximateReceiveCount))
+	msg.Backoff(r.retryPolicy.Delay(err, attempt))
+}
+
+// resolveClaimCheck fetches the offloaded payload of the message, if any
+func (r *route) resolveClaimCheck(ctx context.Context, msg loafergo.Message) error {
+	m, ok := msg.(*message)
+	if !ok {
+		return nil
+	}
+
+	if err := m.resolveClaimCheck(ctx, r.blobStore); err != nil {
+		return loafergo.ErrClaimCheck.Context(err)
+	}
+	return nil
+}
+
+func (r *route) exceededMaxReceiveCount(msg loafergo.Message) bool {
+	if r.maxReceiveCount <= 0 {
+		return false
+	}
+
+	count, err := strconv.Atoi(msg.SystemAttributeByKey(approximateReceiveCount))
+	if err != nil {
+		return false
+	}
+	return int32(count) > r.maxReceiveCount
+}
+
+// DeadLetter hands the message settled with a DeadLetter outcome over to the dead-letter callback or queue.
+// The reason is passed to the callback, or forwarded in the DeadLetterReasonAttribute attribute.
+func (r *route) DeadLetter(ctx context.Context, msg loafergo.Message, reason string) error {
+	var err error
+	switch {
+	case r.deadLetterFunc != nil:
+		err = r.deadLetterFunc(ctx, msg, reason)
+	case r.deadLetterQueueURL != "":
+		err = forward(ctx, r.sqs, r.deadLetterQueueURL, msg, reason)
+	default:
+		err = errors.New("no dead-letter queue configured")
+	}
+
+	if err != nil {
+		// the message is not committed, stop extending its visibility
+		msg.Dispatch()
+	}
+	return err
+}
+
+// deadLetter hands the message over to the dead-letter callback or queue
+func (r *route) deadLetter(ctx context.Context, msg loafergo.Message) error {
+	if r.deadLetterFunc != nil {
+		return r.deadLetterFunc(ctx, msg, DeadLetterReasonMaxReceiveCount)
+	}
+
+	// Configure requires a dead-letter queue or function, an unconfigured route keeps the message
+	if r.deadLetterQueueURL == "" {
+		return errors.New("no dead-letter queue configured")
+	}
+	return forward(ctx, r.sqs, r.deadLetterQueueURL, msg, "")
 }
 
 func (r *route) checkRequiredFields() error {
@@ -239,8 +585,13 @@ func (r *route) checkRequiredFields() error {
 		return loafergo.ErrNoSQSClient
 	}
 
-	if r.handler == nil {
+	if r.handler == nil && r.batchHandler == nil {
 		return loafergo.ErrNoHandler
 	}
+
+	// a message exceeding the max receive count would be deleted without a trace
+	if r.maxReceiveCount > 0 && r.deadLetterQueue == "" && r.deadLetterFunc == nil {
+		return loafergo.ErrEmptyRequiredField.Context(errors.New("max receive count requires a dead-letter queue or function"))
+	}
 	return nil
 }
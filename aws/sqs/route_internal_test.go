@@ -2,6 +2,7 @@ package sqs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -9,9 +10,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
 	"github.com/justcodes/loafer-go/v2/fake"
 )
 
@@ -101,3 +104,615 @@ func TestRouteChangeMessageVisibility(t *testing.T) {
 	})
 
 }
+
+func TestRouteChangeMessageVisibilityMetrics(t *testing.T) {
+	newTestMessage := func() *message {
+		return newMessage(types.Message{
+			Body:          aws.String("body"),
+			ReceiptHandle: aws.String("receipt-handler"),
+		})
+	}
+	visibilityInput := func(timeout int32) *sqs.ChangeMessageVisibilityInput {
+		return &sqs.ChangeMessageVisibilityInput{
+			QueueUrl:          aws.String("queue-url"),
+			ReceiptHandle:     aws.String("receipt-handler"),
+			VisibilityTimeout: timeout,
+		}
+	}
+
+	t.Run("Should record the visibility extensions", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		r := &route{
+			sqs:               mockSQSClient,
+			queueName:         "queue-name",
+			queueURL:          "queue-url",
+			extensionLimit:    1,
+			visibilityTimeout: 11,
+		}
+		metrics := loafergo.NewInMemoryMetrics()
+		ctx := loafergo.ContextWithMetrics(context.Background(), metrics)
+
+		mockSQSClient.On("ChangeMessageVisibility", ctx, visibilityInput(11)).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil).Once()
+		mockSQSClient.On("ChangeMessageVisibility", ctx, visibilityInput(22)).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil).Once()
+
+		r.changeMessageVisibility(ctx, newTestMessage(), loafergo.NoOpLogger{})
+
+		rm := metrics.Snapshot()["queue-name"]
+		assert.Equal(t, int64(1), rm.VisibilityExtensions)
+		assert.Equal(t, int64(0), rm.Backoffs)
+	})
+
+	t.Run("Should record the backoff", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		r := &route{
+			sqs:               mockSQSClient,
+			queueName:         "queue-name",
+			queueURL:          "queue-url",
+			extensionLimit:    1,
+			visibilityTimeout: 30,
+		}
+		metrics := loafergo.NewInMemoryMetrics()
+		ctx := loafergo.ContextWithMetrics(context.Background(), metrics)
+		m := newTestMessage()
+
+		mockSQSClient.On("ChangeMessageVisibility", ctx, visibilityInput(30)).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil).Once()
+		mockSQSClient.On("ChangeMessageVisibility", ctx, visibilityInput(5)).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil).Once()
+
+		m.Backoff(5 * time.Second)
+		r.changeMessageVisibility(ctx, m, loafergo.NoOpLogger{})
+
+		rm := metrics.Snapshot()["queue-name"]
+		assert.Equal(t, int64(0), rm.VisibilityExtensions)
+		assert.Equal(t, int64(1), rm.Backoffs)
+	})
+
+	t.Run("Should not record a failed extension", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		r := &route{
+			sqs:               mockSQSClient,
+			queueName:         "queue-name",
+			queueURL:          "queue-url",
+			extensionLimit:    1,
+			visibilityTimeout: 11,
+		}
+		metrics := loafergo.NewInMemoryMetrics()
+		ctx := loafergo.ContextWithMetrics(context.Background(), metrics)
+
+		mockSQSClient.On("ChangeMessageVisibility", ctx, visibilityInput(11)).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil).Once()
+		mockSQSClient.On("ChangeMessageVisibility", ctx, visibilityInput(22)).
+			Return(nil, fmt.Errorf("throttled")).Once()
+
+		r.changeMessageVisibility(ctx, newTestMessage(), loafergo.NoOpLogger{})
+
+		assert.Equal(t, int64(0), metrics.Snapshot()["queue-name"].VisibilityExtensions)
+	})
+}
+
+func TestRouteMaxReceiveCount(t *testing.T) {
+	newPoisonMessage := func(receiveCount string) *message {
+		return newMessage(types.Message{
+			Body:          aws.String("poison"),
+			ReceiptHandle: aws.String("receipt-handler"),
+			Attributes: map[string]string{
+				approximateReceiveCount: receiveCount,
+				messageGroupID:          "group-1",
+				messageDeduplicationID:  "dedup-1",
+			},
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"foo": {DataType: aws.String("String"), StringValue: aws.String("bar")},
+			},
+		})
+	}
+	failingHandler := func(ctx context.Context, m loafergo.Message) error {
+		t.Fatal("handler must not be called")
+		return nil
+	}
+
+	t.Run("Should forward the message to the dead-letter queue", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		r := &route{
+			sqs:                mockSQSClient,
+			handler:            failingHandler,
+			queueURL:           "queue-url",
+			deadLetterQueueURL: "dlq-url",
+			maxReceiveCount:    3,
+		}
+		m := newPoisonMessage("4")
+
+		mockSQSClient.On("SendMessage", context.Background(), &sqs.SendMessageInput{
+			QueueUrl:               aws.String("dlq-url"),
+			MessageBody:            aws.String("poison"),
+			MessageAttributes:      m.originalMessage.MessageAttributes,
+			MessageGroupId:         aws.String("group-1"),
+			MessageDeduplicationId: aws.String("dedup-1"),
+		}).Return(&sqs.SendMessageOutput{}, nil).Once()
+
+		err := r.HandlerMessage(context.Background(), m)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should return error when forwarding fails", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		r := &route{
+			sqs:                mockSQSClient,
+			handler:            failingHandler,
+			queueURL:           "queue-url",
+			deadLetterQueueURL: "dlq-url",
+			maxReceiveCount:    3,
+		}
+		m := newPoisonMessage("4")
+
+		mockSQSClient.On("SendMessage", context.Background(), mock.Anything).
+			Return(nil, fmt.Errorf("got error")).Once()
+
+		err := r.HandlerMessage(context.Background(), m)
+		assert.ErrorIs(t, err, loafergo.ErrDeadLetter)
+		assert.True(t, <-m.dispatched)
+	})
+
+	t.Run("Should keep the message without a dead-letter queue", func(t *testing.T) {
+		r := &route{
+			handler:         failingHandler,
+			queueURL:        "queue-url",
+			maxReceiveCount: 3,
+		}
+		m := newPoisonMessage("4")
+
+		err := r.HandlerMessage(context.Background(), m)
+		assert.ErrorIs(t, err, loafergo.ErrDeadLetter)
+		assert.ErrorContains(t, err, "no dead-letter queue configured")
+		assert.True(t, <-m.dispatched)
+	})
+
+	t.Run("Should invoke the dead-letter callback", func(t *testing.T) {
+		var (
+			got    loafergo.Message
+			reason string
+		)
+		r := &route{
+			handler:         failingHandler,
+			queueURL:        "queue-url",
+			maxReceiveCount: 3,
+			deadLetterFunc: func(ctx context.Context, m loafergo.Message, why string) error {
+				got, reason = m, why
+				return nil
+			},
+		}
+		m := newPoisonMessage("4")
+
+		err := r.HandlerMessage(context.Background(), m)
+		assert.NoError(t, err)
+		assert.Equal(t, m, got)
+		assert.Equal(t, DeadLetterReasonMaxReceiveCount, reason)
+	})
+
+	t.Run("Should handle the message below the max receive count", func(t *testing.T) {
+		var handled bool
+		r := &route{
+			handler: func(ctx context.Context, m loafergo.Message) error {
+				handled = true
+				return nil
+			},
+			queueURL:           "queue-url",
+			deadLetterQueueURL: "dlq-url",
+			maxReceiveCount:    3,
+		}
+
+		err := r.HandlerMessage(context.Background(), newPoisonMessage("3"))
+		assert.NoError(t, err)
+		assert.True(t, handled)
+	})
+}
+
+func TestRouteHandlerMessagePanic(t *testing.T) {
+	r := &route{
+		handler: func(ctx context.Context, m loafergo.Message) error {
+			panic("boom")
+		},
+		queueURL: "queue-url",
+	}
+	m := newMessage(types.Message{
+		Body:          aws.String("body"),
+		ReceiptHandle: aws.String("receipt-handler"),
+	})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = r.HandlerMessage(context.Background(), m)
+	})
+	assert.True(t, <-m.dispatched)
+}
+
+func TestRouteHandlerBatch(t *testing.T) {
+	newBatchMessage := func(receipt, receiveCount string) *message {
+		return newMessage(types.Message{
+			Body:          aws.String("body"),
+			ReceiptHandle: aws.String(receipt),
+			Attributes:    map[string]string{approximateReceiveCount: receiveCount},
+		})
+	}
+	isDispatched := func(m *message) bool {
+		select {
+		case <-m.dispatched:
+			return true
+		default:
+			return false
+		}
+	}
+
+	t.Run("Should dispatch the failed messages only", func(t *testing.T) {
+		var handled []loafergo.Message
+		r := NewRoute(&Config{
+			BatchHandler: func(ctx context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+				handled = msgs
+				result := loafergo.BatchResult{}
+				result.Fail(msgs[1], errors.New("handler failed"))
+				return result
+			},
+		}).(*route)
+		ok, failed := newBatchMessage("receipt-1", "1"), newBatchMessage("receipt-2", "1")
+
+		assert.True(t, r.BatchMode(context.Background()))
+		result := r.HandlerBatch(context.Background(), []loafergo.Message{ok, failed})
+
+		assert.Equal(t, []loafergo.Message{ok, failed}, handled)
+		assert.NoError(t, result.Err(ok))
+		assert.EqualError(t, result.Err(failed), "handler failed")
+		assert.False(t, isDispatched(ok))
+		assert.True(t, isDispatched(failed))
+	})
+
+	t.Run("Should dead-letter the messages exceeding the max receive count", func(t *testing.T) {
+		var handled []loafergo.Message
+		r := NewRoute(&Config{
+			BatchHandler: func(ctx context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+				handled = msgs
+				return nil
+			},
+		}, RouteWithMaxReceiveCount(3, ""), RouteWithDeadLetterFunc(func(ctx context.Context, m loafergo.Message, _ string) error {
+			if m.Identifier() == "receipt-3" {
+				return errors.New("dlq unavailable")
+			}
+			return nil
+		})).(*route)
+		ok := newBatchMessage("receipt-1", "1")
+		poison := newBatchMessage("receipt-2", "4")
+		stuck := newBatchMessage("receipt-3", "4")
+
+		result := r.HandlerBatch(context.Background(), []loafergo.Message{ok, poison, stuck})
+
+		assert.Equal(t, []loafergo.Message{ok}, handled)
+		assert.NoError(t, result.Err(ok))
+		assert.NoError(t, result.Err(poison))
+		assert.ErrorIs(t, result.Err(stuck), loafergo.ErrDeadLetter)
+		assert.True(t, isDispatched(stuck))
+	})
+
+	t.Run("Should dispatch every message on panic", func(t *testing.T) {
+		r := NewRoute(&Config{
+			BatchHandler: func(ctx context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+				panic("boom")
+			},
+		}).(*route)
+		m1, m2 := newBatchMessage("receipt-1", "1"), newBatchMessage("receipt-2", "1")
+
+		assert.PanicsWithValue(t, "boom", func() {
+			r.HandlerBatch(context.Background(), []loafergo.Message{m1, m2})
+		})
+		assert.True(t, isDispatched(m1))
+		assert.True(t, isDispatched(m2))
+	})
+
+	t.Run("Should not be in batch mode with a handler", func(t *testing.T) {
+		r := NewRoute(&Config{Handler: stubHandler}).(*route)
+		assert.False(t, r.BatchMode(context.Background()))
+	})
+}
+
+func TestRouteClaimCheck(t *testing.T) {
+	newClaimCheckMessage := func(receipt, body string, attrs map[string]types.MessageAttributeValue) *message {
+		return newMessage(types.Message{
+			Body:              aws.String(body),
+			ReceiptHandle:     aws.String(receipt),
+			MessageAttributes: attrs,
+		})
+	}
+	nativePointer := func(key string) map[string]types.MessageAttributeValue {
+		return map[string]types.MessageAttributeValue{
+			claimcheck.AttributeKey: {DataType: aws.String("String"), StringValue: aws.String(key)},
+		}
+	}
+
+	t.Run("Should fetch the payload sent to the queue and keep it on commit", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		store := fake.NewBlobStore(t)
+		var body []byte
+		r := NewRoute(&Config{
+			SQSClient: mockSQSClient,
+			Handler: func(ctx context.Context, m loafergo.Message) error {
+				body = m.Body()
+				return nil
+			},
+		}, RouteWithBlobStore(store)).(*route)
+		m := newClaimCheckMessage("receipt-1", `{"loaferClaimCheck":"key-1"}`, nativePointer("key-1"))
+
+		store.On("Get", context.Background(), "key-1").Return([]byte(`{"id":1}`), nil).Once()
+		mockSQSClient.On("DeleteMessage", context.Background(), mock.Anything).Return(&sqs.DeleteMessageOutput{}, nil).Once()
+
+		assert.NoError(t, r.HandlerMessage(context.Background(), m))
+		assert.Equal(t, `{"id":1}`, string(body))
+
+		var out struct{ ID int }
+		assert.NoError(t, m.Decode(&out))
+		assert.Equal(t, 1, out.ID)
+		assert.NoError(t, r.Commit(context.Background(), m))
+		store.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Should delete the payload on commit when configured to", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		store := fake.NewBlobStore(t)
+		var body []byte
+		r := NewRoute(&Config{
+			SQSClient: mockSQSClient,
+			Handler: func(ctx context.Context, m loafergo.Message) error {
+				body = m.Body()
+				return nil
+			},
+		}, RouteWithBlobStore(store, claimcheck.DeleteOnCommit())).(*route)
+		m := newClaimCheckMessage("receipt-1", `{"loaferClaimCheck":"key-1"}`, nativePointer("key-1"))
+
+		store.On("Get", context.Background(), "key-1").Return([]byte(`{"id":1}`), nil).Once()
+		mockSQSClient.On("DeleteMessage", context.Background(), mock.Anything).Return(&sqs.DeleteMessageOutput{}, nil).Once()
+		store.On("Delete", context.Background(), "key-1").Return(nil).Once()
+
+		assert.NoError(t, r.HandlerMessage(context.Background(), m))
+		assert.Equal(t, `{"id":1}`, string(body))
+
+		var out struct{ ID int }
+		assert.NoError(t, m.Decode(&out))
+		assert.Equal(t, 1, out.ID)
+		assert.NoError(t, r.Commit(context.Background(), m))
+	})
+
+	t.Run("Should fetch the payload published to a topic", func(t *testing.T) {
+		store := fake.NewBlobStore(t)
+		r := NewRoute(&Config{Handler: stubHandler}, RouteWithBlobStore(store)).(*route)
+		m := newClaimCheckMessage(
+			"receipt-1",
+			`{"Message":"{\"loaferClaimCheck\":\"key-1\"}","MessageAttributes":{"loafer-claim-check":{"Type":"String","Value":"key-1"}}}`,
+			nil,
+		)
+
+		store.On("Get", context.Background(), "key-1").Return([]byte(`{"id":1}`), nil).Once()
+
+		assert.NoError(t, r.HandlerMessage(context.Background(), m))
+		assert.Equal(t, `{"id":1}`, m.Message())
+		assert.Equal(t, "key-1", m.claimCheckKey)
+	})
+
+	t.Run("Should not call the handler when the payload cannot be fetched", func(t *testing.T) {
+		store := fake.NewBlobStore(t)
+		r := NewRoute(&Config{
+			Handler: func(ctx context.Context, m loafergo.Message) error {
+				t.Fatal("handler must not be called")
+				return nil
+			},
+		}, RouteWithBlobStore(store)).(*route)
+		m := newClaimCheckMessage("receipt-1", `{"loaferClaimCheck":"key-1"}`, nativePointer("key-1"))
+
+		store.On("Get", context.Background(), "key-1").Return(nil, errors.New("not found")).Once()
+
+		err := r.HandlerMessage(context.Background(), m)
+		assert.ErrorIs(t, err, loafergo.ErrClaimCheck)
+		assert.True(t, <-m.dispatched)
+	})
+
+	t.Run("Should keep the payload when the message is not deleted", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		store := fake.NewBlobStore(t)
+		r := NewRoute(
+			&Config{SQSClient: mockSQSClient, Handler: stubHandler},
+			RouteWithBlobStore(store, claimcheck.DeleteOnCommit()),
+		).(*route)
+		m := newClaimCheckMessage("receipt-1", `{"loaferClaimCheck":"key-1"}`, nativePointer("key-1"))
+
+		store.On("Get", context.Background(), "key-1").Return([]byte("payload"), nil).Once()
+		mockSQSClient.On("DeleteMessage", context.Background(), mock.Anything).Return(nil, errors.New("got error")).Once()
+
+		assert.NoError(t, r.HandlerMessage(context.Background(), m))
+		assert.EqualError(t, r.Commit(context.Background(), m), "got error")
+	})
+
+	t.Run("Should fail the batch messages whose payload cannot be fetched", func(t *testing.T) {
+		store := fake.NewBlobStore(t)
+		var handled []loafergo.Message
+		r := NewRoute(&Config{
+			BatchHandler: func(ctx context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+				handled = msgs
+				return nil
+			},
+		}, RouteWithBlobStore(store)).(*route)
+		ok := newClaimCheckMessage("receipt-1", "small", nil)
+		missing := newClaimCheckMessage("receipt-2", `{"loaferClaimCheck":"key-2"}`, nativePointer("key-2"))
+
+		store.On("Get", context.Background(), "key-2").Return(nil, errors.New("not found")).Once()
+
+		result := r.HandlerBatch(context.Background(), []loafergo.Message{ok, missing})
+		assert.Equal(t, []loafergo.Message{ok}, handled)
+		assert.NoError(t, result.Err(ok))
+		assert.ErrorIs(t, result.Err(missing), loafergo.ErrClaimCheck)
+		assert.True(t, <-missing.dispatched)
+	})
+}
+
+func TestNewRouteWithMiddleware(t *testing.T) {
+	var calls []string
+	r := NewRoute(&Config{
+		Handler: func(ctx context.Context, m loafergo.Message) error {
+			calls = append(calls, "handler")
+			return nil
+		},
+	}, RouteWithMiddleware(func(next loafergo.Handler) loafergo.Handler {
+		return func(ctx context.Context, m loafergo.Message) error {
+			calls = append(calls, "middleware")
+			return next(ctx, m)
+		}
+	}))
+
+	m := newMessage(types.Message{
+		Body:          aws.String("body"),
+		ReceiptHandle: aws.String("receipt-handler"),
+	})
+	err := r.HandlerMessage(context.Background(), m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"middleware", "handler"}, calls)
+}
+
+func TestRouteRetryPolicy(t *testing.T) {
+	handlerErr := errors.New("boom")
+	policy := loafergo.NewRetryPolicy(10*time.Second, time.Minute, loafergo.RetryWithJitter(0))
+	newTestMessage := func(id string) *message {
+		return newMessage(types.Message{
+			Body:          aws.String("body"),
+			ReceiptHandle: aws.String(id),
+			Attributes:    map[string]string{approximateReceiveCount: "3"},
+		})
+	}
+
+	t.Run("Should back off the failed message by its receive count", func(t *testing.T) {
+		r := &route{retryPolicy: policy, handler: func(ctx context.Context, m loafergo.Message) error {
+			return handlerErr
+		}}
+		m := newTestMessage("receipt-handler")
+
+		assert.ErrorIs(t, r.HandlerMessage(context.Background(), m), handlerErr)
+		assert.True(t, m.BackedOff())
+		assert.Equal(t, 40*time.Second, <-m.backoffChannel)
+		assert.Empty(t, m.dispatched)
+	})
+
+	t.Run("Should keep the backoff of the handler", func(t *testing.T) {
+		r := &route{retryPolicy: policy, handler: func(ctx context.Context, m loafergo.Message) error {
+			m.Backoff(time.Second)
+			return handlerErr
+		}}
+		m := newTestMessage("receipt-handler")
+
+		assert.ErrorIs(t, r.HandlerMessage(context.Background(), m), handlerErr)
+		assert.Equal(t, time.Second, <-m.backoffChannel)
+		assert.Len(t, m.dispatched, 1)
+	})
+
+	t.Run("Should only dispatch without retry policy", func(t *testing.T) {
+		r := &route{handler: func(ctx context.Context, m loafergo.Message) error {
+			return handlerErr
+		}}
+		m := newTestMessage("receipt-handler")
+
+		assert.ErrorIs(t, r.HandlerMessage(context.Background(), m), handlerErr)
+		assert.False(t, m.BackedOff())
+		assert.Len(t, m.dispatched, 1)
+	})
+
+	t.Run("Should back off the messages failed by the batch handler", func(t *testing.T) {
+		store := fake.NewBlobStore(t)
+		store.On("Get", mock.Anything, "key").Return(nil, errors.New("not found")).Once()
+
+		r := &route{retryPolicy: policy, blobStore: store, batchHandler: func(ctx context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+			return loafergo.FailAll(msgs, handlerErr)
+		}}
+		failed := newTestMessage("failed")
+		unresolved := newTestMessage("unresolved")
+		unresolved.originalMessage.MessageAttributes = map[string]types.MessageAttributeValue{
+			claimcheck.AttributeKey: {DataType: aws.String("String"), StringValue: aws.String("key")},
+		}
+
+		result := r.HandlerBatch(context.Background(), []loafergo.Message{failed, unresolved})
+		assert.ErrorIs(t, result.Err(failed), handlerErr)
+		assert.ErrorIs(t, result.Err(unresolved), loafergo.ErrClaimCheck)
+		assert.True(t, failed.BackedOff())
+		assert.False(t, unresolved.BackedOff())
+		assert.Len(t, unresolved.dispatched, 1)
+	})
+}
+
+func TestRouteOutcomes(t *testing.T) {
+	newTestMessage := func() *message {
+		return newMessage(types.Message{
+			Body:          aws.String("body"),
+			ReceiptHandle: aws.String("receipt-handler"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"event_type": {DataType: aws.String("String"), StringValue: aws.String("created")},
+			},
+		})
+	}
+	handlerReturning := func(err error) loafergo.Handler {
+		return func(ctx context.Context, m loafergo.Message) error {
+			return err
+		}
+	}
+
+	t.Run("Should back off a RetryAfter outcome by its delay", func(t *testing.T) {
+		policy := loafergo.NewRetryPolicy(time.Hour, time.Hour)
+		r := &route{retryPolicy: policy, handler: handlerReturning(loafergo.RetryAfter(time.Minute))}
+		m := newTestMessage()
+
+		assert.Error(t, r.HandlerMessage(context.Background(), m))
+		assert.Equal(t, time.Minute, <-m.backoffChannel)
+		assert.Empty(t, m.dispatched)
+	})
+
+	t.Run("Should leave the acked and dropped messages to the commit", func(t *testing.T) {
+		for _, outcome := range []error{loafergo.Ack(), loafergo.Drop(), loafergo.DeadLetter("invalid")} {
+			r := &route{handler: handlerReturning(outcome)}
+			m := newTestMessage()
+
+			assert.Error(t, r.HandlerMessage(context.Background(), m))
+			assert.False(t, m.BackedOff())
+			assert.Empty(t, m.dispatched)
+		}
+	})
+
+	t.Run("Should forward a dead-lettered message with its reason", func(t *testing.T) {
+		mockSQSClient := fake.NewSQSClient(t)
+		r := &route{sqs: mockSQSClient, deadLetterQueueURL: "dlq-url"}
+		m := newTestMessage()
+
+		mockSQSClient.On("SendMessage", mock.Anything, &sqs.SendMessageInput{
+			QueueUrl:    aws.String("dlq-url"),
+			MessageBody: aws.String("body"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"event_type":              {DataType: aws.String("String"), StringValue: aws.String("created")},
+				DeadLetterReasonAttribute: {DataType: aws.String("String"), StringValue: aws.String("invalid")},
+			},
+		}).Return(&sqs.SendMessageOutput{}, nil).Once()
+
+		assert.NoError(t, r.DeadLetter(context.Background(), m, "invalid"))
+		assert.Len(t, m.originalMessage.MessageAttributes, 1)
+		assert.Empty(t, m.dispatched)
+	})
+
+	t.Run("Should fail to dead-letter without dead-letter queue", func(t *testing.T) {
+		r := &route{}
+		m := newTestMessage()
+
+		assert.Error(t, r.DeadLetter(context.Background(), m, "invalid"))
+		assert.Len(t, m.dispatched, 1)
+	})
+
+	t.Run("Should dead-letter with the callback", func(t *testing.T) {
+		var reason string
+		r := &route{deadLetterFunc: func(ctx context.Context, m loafergo.Message, why string) error {
+			reason = why
+			return nil
+		}}
+
+		assert.NoError(t, r.DeadLetter(context.Background(), newTestMessage(), "invalid"))
+		assert.Equal(t, "invalid", reason)
+	})
+}
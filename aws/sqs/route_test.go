@@ -3,12 +3,15 @@ package sqs_test
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsSqs "github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
@@ -86,6 +89,29 @@ func (suite *routeSuite) TestConfigure() {
 		suite.Equal("got error", err.Error())
 	})
 
+	suite.Run("Should configure route with dead-letter queue", func() {
+		route := suite.setupRouter(sqs.RouteWithMaxReceiveCount(3, "example-1-dlq"))
+		param := &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}
+		suite.sqsClient.On("GetQueueUrl", context.Background(), param).
+			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1")}, nil).
+			Once()
+		dlqParam := &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1-dlq")}
+		suite.sqsClient.On("GetQueueUrl", context.Background(), dlqParam).
+			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-dlq")}, nil).
+			Once()
+
+		err := route.Configure(context.Background())
+		suite.NoError(err)
+	})
+
+	suite.Run("Should return error when the max receive count has no dead-letter queue or function", func() {
+		route := suite.setupRouter(sqs.RouteWithMaxReceiveCount(3, ""))
+
+		err := route.Configure(context.Background())
+		suite.ErrorIs(err, loafergo.ErrEmptyRequiredField)
+		suite.ErrorContains(err, "max receive count requires a dead-letter queue or function")
+	})
+
 	suite.Run("Should return error when sqs client is nil", func() {
 		suite.route = sqs.NewRoute(&sqs.Config{
 			SQSClient: nil,
@@ -111,6 +137,23 @@ func (suite *routeSuite) TestConfigure() {
 		suite.ErrorIs(err, loafergo.ErrNoHandler)
 		suite.TearDownSuite()
 	})
+
+	suite.Run("Should configure a route with a batch handler only", func() {
+		suite.route = sqs.NewRoute(&sqs.Config{
+			SQSClient: suite.sqsClient,
+			BatchHandler: func(ctx context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+				return nil
+			},
+			QueueName: "example-1",
+		})
+		suite.sqsClient.On("GetQueueUrl", context.Background(), &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}).
+			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).
+			Once()
+
+		err := suite.route.Configure(context.Background())
+		suite.NoError(err)
+		suite.TearDownSuite()
+	})
 }
 
 func (suite *routeSuite) TestGetMessages() {
@@ -119,7 +162,7 @@ func (suite *routeSuite) TestGetMessages() {
 
 	suite.Run("Should return the messages", func() {
 		suite.route = suite.setupRouter()
-		ctx, done := setupContext(1)
+		ctx, done := suite.setupContext(1)
 		cParam := &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}
 		suite.sqsClient.On("GetQueueUrl", ctx, cParam).
 			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).
@@ -130,6 +173,7 @@ func (suite *routeSuite) TestGetMessages() {
 		param := &awsSqs.ReceiveMessageInput{
 			QueueUrl:                    aws.String("example-1-url"),
 			WaitTimeSeconds:             8,
+			VisibilityTimeout:           12,
 			MaxNumberOfMessages:         15,
 			MessageAttributeNames:       []string{"All"},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
@@ -151,6 +195,7 @@ func (suite *routeSuite) TestGetMessages() {
 
 		messages, err := suite.route.GetMessages(ctx, logger)
 		suite.NoError(err)
+		suite.route.(loafergo.PickUpRouter).PickUp(ctx, messages[0])
 		<-done
 
 		suite.Len(messages, 1)
@@ -160,7 +205,7 @@ func (suite *routeSuite) TestGetMessages() {
 
 	suite.Run("Should return error when receive message", func() {
 		suite.route = suite.setupRouter()
-		ctx, done := setupContext(1)
+		ctx, done := suite.setupContext(1)
 		cParam := &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}
 		suite.sqsClient.On("GetQueueUrl", ctx, cParam).
 			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).
@@ -171,6 +216,7 @@ func (suite *routeSuite) TestGetMessages() {
 		param := &awsSqs.ReceiveMessageInput{
 			QueueUrl:                    aws.String("example-1-url"),
 			WaitTimeSeconds:             8,
+			VisibilityTimeout:           12,
 			MaxNumberOfMessages:         15,
 			MessageAttributeNames:       []string{"All"},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
@@ -189,12 +235,74 @@ func (suite *routeSuite) TestGetMessages() {
 	})
 }
 
+func (suite *routeSuite) TestGetMessagesUpTo() {
+	logger := new(fake.Logger)
+	logger.On("Log", mock.Anything).Return()
+
+	testCases := []struct {
+		name     string
+		max      int32
+		expected int32
+	}{
+		{name: "Should receive the messages asked for", max: 3, expected: 3},
+		{name: "Should not receive more than the route max messages", max: 50, expected: 15},
+		{name: "Should receive at least one message", max: 0, expected: 1},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.route = suite.setupRouter()
+			ctx, done := suite.setupContext(1)
+			suite.sqsClient.On("GetQueueUrl", ctx, &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}).
+				Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).
+				Once()
+			suite.NoError(suite.route.Configure(ctx))
+
+			suite.sqsClient.On("ReceiveMessage", ctx, &awsSqs.ReceiveMessageInput{
+				QueueUrl:                    aws.String("example-1-url"),
+				WaitTimeSeconds:             8,
+				VisibilityTimeout:           12,
+				MaxNumberOfMessages:         tc.expected,
+				MessageAttributeNames:       []string{"All"},
+				MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
+			}).
+				Return(&awsSqs.ReceiveMessageOutput{
+					Messages: []types.Message{{
+						Body:          aws.String("hello world"),
+						ReceiptHandle: aws.String("receipt-handle"),
+					}},
+				}, nil).
+				Once()
+
+			messages, err := suite.route.(loafergo.BoundedRouter).GetMessagesUpTo(ctx, logger, tc.max)
+			suite.NoError(err)
+			suite.Len(messages, 1)
+
+			// the visibility is only extended once the message is picked up, and only once
+			suite.Empty(done)
+			suite.sqsClient.On("ChangeMessageVisibility", ctx, &awsSqs.ChangeMessageVisibilityInput{
+				QueueUrl:          aws.String("example-1-url"),
+				ReceiptHandle:     aws.String("receipt-handle"),
+				VisibilityTimeout: int32(12),
+			}).Return(nil, nil).Once()
+
+			suite.route.(loafergo.PickUpRouter).PickUp(ctx, messages[0])
+			suite.route.(loafergo.PickUpRouter).PickUp(ctx, messages[0])
+			<-done
+			messages[0].Dispatch()
+		})
+	}
+}
+
 // The goal of this is to create a channel that will be written on doChangeVisibilityTimeout of router
 // to notify that the goroutine has finished execution, otherwise, the test cases may finish before and fail
-func setupContext(amountOfVisibilityChangesExpected int) (context.Context, chan bool) {
+// setupContext returns a context cancelled at the end of the test, stopping the visibility extensions it started
+func (suite *routeSuite) setupContext(amountOfVisibilityChangesExpected int) (context.Context, chan bool) {
 	done := make(chan bool, amountOfVisibilityChangesExpected)
 	k := sqs.DoneCtxKey{}
-	return context.WithValue(context.Background(), k, done), done
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), k, done))
+	suite.T().Cleanup(cancel)
+	return ctx, done
 }
 
 func (suite *routeSuite) TestCommit() {
@@ -203,7 +311,7 @@ func (suite *routeSuite) TestCommit() {
 
 	suite.Run("Should commit commit", func() {
 		suite.route = suite.setupRouter()
-		ctx, done := setupContext(1)
+		ctx, done := suite.setupContext(1)
 		cParam := &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}
 		suite.sqsClient.On("GetQueueUrl", ctx, cParam).
 			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).
@@ -214,6 +322,7 @@ func (suite *routeSuite) TestCommit() {
 		param := &awsSqs.ReceiveMessageInput{
 			QueueUrl:                    aws.String("example-1-url"),
 			WaitTimeSeconds:             8,
+			VisibilityTimeout:           12,
 			MaxNumberOfMessages:         15,
 			MessageAttributeNames:       []string{"All"},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
@@ -236,6 +345,7 @@ func (suite *routeSuite) TestCommit() {
 		message, err := suite.route.GetMessages(ctx, logger)
 		suite.NoError(err)
 
+		suite.route.(loafergo.PickUpRouter).PickUp(ctx, message[0])
 		<-done
 
 		commitParam := &awsSqs.DeleteMessageInput{
@@ -252,7 +362,7 @@ func (suite *routeSuite) TestCommit() {
 
 	suite.Run("Should return error when commit error", func() {
 		suite.route = suite.setupRouter()
-		ctx, done := setupContext(1)
+		ctx, done := suite.setupContext(1)
 		cParam := &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}
 		suite.sqsClient.On("GetQueueUrl", ctx, cParam).
 			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).
@@ -263,6 +373,7 @@ func (suite *routeSuite) TestCommit() {
 		param := &awsSqs.ReceiveMessageInput{
 			QueueUrl:                    aws.String("example-1-url"),
 			WaitTimeSeconds:             8,
+			VisibilityTimeout:           12,
 			MaxNumberOfMessages:         15,
 			MessageAttributeNames:       []string{"All"},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
@@ -285,6 +396,7 @@ func (suite *routeSuite) TestCommit() {
 		message, err := suite.route.GetMessages(ctx, logger)
 		suite.NoError(err)
 
+		suite.route.(loafergo.PickUpRouter).PickUp(ctx, message[0])
 		<-done
 
 		commitParam := &awsSqs.DeleteMessageInput{
@@ -307,7 +419,7 @@ func (suite *routeSuite) TestHandlerMessage() {
 
 	suite.Run("should handler message", func() {
 		suite.route = suite.setupRouter()
-		ctx, done := setupContext(1)
+		ctx, done := suite.setupContext(1)
 		cParam := &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}
 		suite.sqsClient.On("GetQueueUrl", ctx, cParam).
 			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).
@@ -318,6 +430,7 @@ func (suite *routeSuite) TestHandlerMessage() {
 		param := &awsSqs.ReceiveMessageInput{
 			QueueUrl:                    aws.String("example-1-url"),
 			WaitTimeSeconds:             8,
+			VisibilityTimeout:           12,
 			MaxNumberOfMessages:         15,
 			MessageAttributeNames:       []string{"All"},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
@@ -341,6 +454,7 @@ func (suite *routeSuite) TestHandlerMessage() {
 		suite.NoError(err)
 		suite.NoError(err)
 
+		suite.route.(loafergo.PickUpRouter).PickUp(ctx, message[0])
 		<-done
 
 		err = suite.route.HandlerMessage(ctx, message[0])
@@ -349,7 +463,7 @@ func (suite *routeSuite) TestHandlerMessage() {
 
 	suite.Run("should return error when handler message error", func() {
 		suite.route = suite.setupRouter()
-		ctx, done := setupContext(1)
+		ctx, done := suite.setupContext(1)
 		cParam := &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}
 		suite.sqsClient.On("GetQueueUrl", ctx, cParam).
 			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).
@@ -360,6 +474,7 @@ func (suite *routeSuite) TestHandlerMessage() {
 		param := &awsSqs.ReceiveMessageInput{
 			QueueUrl:                    aws.String("example-1-url"),
 			WaitTimeSeconds:             8,
+			VisibilityTimeout:           12,
 			MaxNumberOfMessages:         15,
 			MessageAttributeNames:       []string{"All"},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
@@ -382,6 +497,7 @@ func (suite *routeSuite) TestHandlerMessage() {
 		message, err := suite.route.GetMessages(ctx, logger)
 		suite.NoError(err)
 
+		suite.route.(loafergo.PickUpRouter).PickUp(ctx, message[0])
 		<-done
 
 		suite.route = sqs.NewRoute(&sqs.Config{
@@ -465,6 +581,49 @@ func (suite *routeSuite) TestCustomGroupFields() {
 	})
 }
 
+func (suite *routeSuite) TestAutoscaling() {
+	suite.Run("should not scale without policy", func() {
+		suite.SetupSuite()
+		got := suite.route.(loafergo.ScalingRouter).ScalingPolicy(context.Background())
+		suite.Nil(got)
+		suite.TearDownSuite()
+	})
+
+	suite.Run("should return the scaling policy and the queue backlog", func() {
+		ctx := context.Background()
+		policy := loafergo.NewScalingPolicy(1, 10)
+		suite.route = suite.setupRouter(sqs.RouteWithAutoscaling(policy))
+		suite.sqsClient.On("GetQueueUrl", ctx, &awsSqs.GetQueueUrlInput{QueueName: aws.String("example-1")}).
+			Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).
+			Once()
+		suite.NoError(suite.route.Configure(ctx))
+
+		suite.sqsClient.On("GetQueueAttributes", ctx, &awsSqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String("example-1-url"),
+			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+		}).
+			Return(&awsSqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": "42"}}, nil).
+			Once()
+
+		scaling := suite.route.(loafergo.ScalingRouter)
+		suite.Same(policy, scaling.ScalingPolicy(ctx))
+		backlog, err := scaling.Backlog(ctx)
+		suite.NoError(err)
+		suite.Equal(42, backlog)
+		suite.TearDownSuite()
+	})
+
+	suite.Run("should return the error getting the queue backlog", func() {
+		ctx := context.Background()
+		suite.route = suite.setupRouter(sqs.RouteWithAutoscaling(loafergo.NewScalingPolicy(1, 10)))
+		suite.sqsClient.On("GetQueueAttributes", ctx, mock.Anything).Return(nil, fmt.Errorf("got error")).Once()
+
+		_, err := suite.route.(loafergo.ScalingRouter).Backlog(ctx)
+		suite.EqualError(err, "got error")
+		suite.TearDownSuite()
+	})
+}
+
 func (suite *routeSuite) TestChangeVisibilityInitially() {
 	logger := new(fake.Logger)
 	logger.On("Log", mock.Anything).Return()
@@ -483,7 +642,7 @@ func (suite *routeSuite) TestChangeVisibilityInitially() {
 			sqs.RouteWithWaitTimeSeconds(10),
 		)
 
-		ctx, done := setupContext(1)
+		ctx, done := suite.setupContext(1)
 
 		visibilityTimeout = int(suite.route.VisibilityTimeout(ctx))
 
@@ -500,6 +659,7 @@ func (suite *routeSuite) TestChangeVisibilityInitially() {
 		param := &awsSqs.ReceiveMessageInput{
 			QueueUrl:                    queueUrl,
 			WaitTimeSeconds:             10,
+			VisibilityTimeout:           int32(visibilityTimeout),
 			MaxNumberOfMessages:         10,
 			MessageAttributeNames:       []string{"All"},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
@@ -523,6 +683,7 @@ func (suite *routeSuite) TestChangeVisibilityInitially() {
 		messages, err := suite.route.GetMessages(ctx, logger)
 		suite.NoError(err)
 
+		suite.route.(loafergo.PickUpRouter).PickUp(ctx, messages[0])
 		<-done
 
 		msg := messages[0]
@@ -560,7 +721,7 @@ func (suite *routeSuite) TestChangeVisibilityTimeout() {
 			sqs.RouteWithWaitTimeSeconds(10),
 		)
 
-		ctx, done := setupContext(2)
+		ctx, done := suite.setupContext(2)
 
 		visibilityTimeout = int(suite.route.VisibilityTimeout(ctx))
 
@@ -577,6 +738,7 @@ func (suite *routeSuite) TestChangeVisibilityTimeout() {
 		param := &awsSqs.ReceiveMessageInput{
 			QueueUrl:                    queueUrl,
 			WaitTimeSeconds:             10,
+			VisibilityTimeout:           int32(visibilityTimeout),
 			MaxNumberOfMessages:         10,
 			MessageAttributeNames:       []string{"All"},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
@@ -606,6 +768,7 @@ func (suite *routeSuite) TestChangeVisibilityTimeout() {
 		messages, err := suite.route.GetMessages(ctx, logger)
 		suite.NoError(err)
 
+		suite.route.(loafergo.PickUpRouter).PickUp(ctx, messages[0])
 		<-done
 
 		msg := messages[0]
@@ -645,7 +808,7 @@ func (suite *routeSuite) TestBackoff() {
 			sqs.RouteWithWaitTimeSeconds(10),
 		)
 
-		ctx, done := setupContext(2)
+		ctx, done := suite.setupContext(2)
 
 		visibilityTimeout = int(suite.route.VisibilityTimeout(ctx))
 
@@ -662,6 +825,7 @@ func (suite *routeSuite) TestBackoff() {
 		param := &awsSqs.ReceiveMessageInput{
 			QueueUrl:                    queueUrl,
 			WaitTimeSeconds:             10,
+			VisibilityTimeout:           int32(visibilityTimeout),
 			MaxNumberOfMessages:         10,
 			MessageAttributeNames:       []string{"All"},
 			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
@@ -704,3 +868,80 @@ func (suite *routeSuite) TestBackoff() {
 		suite.Nil(err)
 	})
 }
+
+func TestRoute_PerGroupID_WaitingMessageVisibility(t *testing.T) {
+	sqsClient := fake.NewSQSClient(t)
+
+	var (
+		mu       sync.Mutex
+		events   []string
+		handling = make(chan struct{}, 2)
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	// the handler is slower than a visibility extension period, so the second message of the group
+	// waits behind the first one long enough for its receipt to expire without an extension
+	route := sqs.NewRoute(&sqs.Config{
+		SQSClient: sqsClient,
+		Handler: func(ctx context.Context, m loafergo.Message) error {
+			record("handle " + m.Identifier())
+			time.Sleep(1500 * time.Millisecond)
+			record("handled " + m.Identifier())
+			handling <- struct{}{}
+			return nil
+		},
+		QueueName: "example-1",
+	},
+		sqs.RouteWithVisibilityTimeout(11),
+		sqs.RouteWithWorkerPoolSize(2),
+		sqs.RouteWithRunMode(loafergo.PerGroupID),
+	)
+
+	groupMessage := func(receipt string) types.Message {
+		return types.Message{
+			Body:          aws.String("hello world"),
+			ReceiptHandle: aws.String(receipt),
+			Attributes:    map[string]string{"MessageGroupId": "group-1"},
+		}
+	}
+	sqsClient.On("GetQueueUrl", mock.Anything, mock.Anything).
+		Return(&awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("example-1-url")}, nil).Once()
+	sqsClient.On("ReceiveMessage", mock.Anything, mock.Anything).
+		Return(&awsSqs.ReceiveMessageOutput{Messages: []types.Message{groupMessage("receipt-1"), groupMessage("receipt-2")}}, nil).Once()
+	sqsClient.On("ReceiveMessage", mock.Anything, mock.Anything).
+		After(10*time.Millisecond).Return(&awsSqs.ReceiveMessageOutput{}, nil).Maybe()
+	sqsClient.On("ChangeMessageVisibility", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			input := args.Get(1).(*awsSqs.ChangeMessageVisibilityInput)
+			record(fmt.Sprintf("extend %s %d", aws.ToString(input.ReceiptHandle), input.VisibilityTimeout))
+		}).
+		Return(&awsSqs.ChangeMessageVisibilityOutput{}, nil)
+	sqsClient.On("DeleteMessage", mock.Anything, mock.Anything).Return(&awsSqs.DeleteMessageOutput{}, nil).Twice()
+
+	manager := loafergo.NewManager(&loafergo.Config{Logger: loafergo.NoOpLogger{}, RetryTimeout: time.Second})
+	manager.RegisterRoute(route)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- manager.Run(context.Background())
+	}()
+
+	for range 2 {
+		select {
+		case <-handling:
+		case <-time.After(5 * time.Second):
+			t.Fatal("messages not handled")
+		}
+	}
+	assert.NoError(t, manager.Shutdown(context.Background()))
+	assert.NoError(t, <-runErr)
+
+	mu.Lock()
+	defer mu.Unlock()
+	waiting := slices.Index(events, "extend receipt-2 11")
+	assert.NotEqual(t, -1, waiting, "the waiting message must be kept invisible")
+	assert.Less(t, waiting, slices.Index(events, "handled receipt-1"), "the message must be kept invisible while it waits")
+}
@@ -0,0 +1,103 @@
+// Package claimcheck implements the claim-check pattern for the payloads too large to be sent in a message.
+//
+// A producer configured with a loafergo.BlobStore stores the oversized payloads in it and sends
+// a pointer instead, marked by the AttributeKey message attribute. A route configured with the same
+// store fetches the payload before calling the handler.
+//
+// The payloads are kept in the store by default, and are expected to expire through a TTL or the lifecycle
+// rules of the bucket: with an SNS fan-out every subscribed queue receives the same claim check, and a
+// standard queue can deliver a message more than once. A route consuming the only copy of each claim
+// check can delete the payloads on commit with the DeleteOnCommit option.
+package claimcheck
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+const (
+	// AttributeKey is the message attribute holding the blob key of an offloaded payload
+	AttributeKey = "loafer-claim-check"
+	// DefaultThreshold is the maximum size of a message accepted by SNS and SQS, 256 KiB.
+	// Messages larger than it are offloaded.
+	DefaultThreshold = 256 * 1024
+)
+
+// Config are a discrete set of options that are valid for a route consuming offloaded payloads
+type Config struct {
+	// DeleteOnCommit deletes the payload of a message from the store once the message is committed
+	DeleteOnCommit bool
+}
+
+// DeleteOnCommit deletes the payload of a message from the store once the message is committed.
+//
+// Only use it when each claim check is received by a single queue, once: a payload published to a topic
+// with several subscribed queues, or delivered twice by a standard queue, is then missing for the other
+// copies of the message, which fail until they are dead-lettered.
+func DeleteOnCommit() func(*Config) {
+	return func(c *Config) {
+		c.DeleteOnCommit = true
+	}
+}
+
+// Pointer is the body sent in place of an offloaded payload
+type Pointer struct {
+	Key string `json:"loaferClaimCheck"`
+}
+
+// NewKey returns a random blob key
+func NewKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Size returns the size of a message as accounted by SNS and SQS:
+// the body plus the name, data type and value of each attribute
+func Size(body string, attributes map[string]string) int {
+	size := len(body)
+	for k, v := range attributes {
+		size += len(k) + len("String") + len(v)
+	}
+	return size
+}
+
+// Offload stores body in store when the message is larger than threshold,
+// and returns the pointer body along with a copy of the attributes marked with AttributeKey.
+// Otherwise, or when store is nil, it returns body and attributes unchanged.
+// A threshold lower than 1 means DefaultThreshold.
+func Offload(
+	ctx context.Context,
+	store loafergo.BlobStore,
+	body string,
+	attributes map[string]string,
+	threshold int,
+) (string, map[string]string, error) {
+	if threshold < 1 {
+		threshold = DefaultThreshold
+	}
+
+	if store == nil || Size(body, attributes) <= threshold {
+		return body, attributes, nil
+	}
+
+	key := NewKey()
+	if err := store.Put(ctx, key, []byte(body)); err != nil {
+		return "", nil, loafergo.ErrClaimCheck.Context(err)
+	}
+
+	pointer, err := json.Marshal(Pointer{Key: key})
+	if err != nil {
+		return "", nil, loafergo.ErrClaimCheck.Context(err)
+	}
+
+	attrs := make(map[string]string, len(attributes)+1)
+	maps.Copy(attrs, attributes)
+	attrs[AttributeKey] = key
+	return string(pointer), attrs, nil
+}
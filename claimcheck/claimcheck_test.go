@@ -0,0 +1,72 @@
+package claimcheck_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+func TestSize(t *testing.T) {
+	assert.Equal(t, 4, claimcheck.Size("body", nil))
+	assert.Equal(t, 4+len("key")+len("String")+len("value"), claimcheck.Size("body", map[string]string{"key": "value"}))
+}
+
+func TestOffload(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should keep a message below the threshold", func(t *testing.T) {
+		store := fake.NewBlobStore(t)
+		attrs := map[string]string{"foo": "bar"}
+
+		body, got, err := claimcheck.Offload(ctx, store, "body", attrs, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, "body", body)
+		assert.Equal(t, attrs, got)
+	})
+
+	t.Run("Should keep the message without a store", func(t *testing.T) {
+		large := strings.Repeat("a", claimcheck.DefaultThreshold+1)
+
+		body, got, err := claimcheck.Offload(ctx, nil, large, nil, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, large, body)
+		assert.Nil(t, got)
+	})
+
+	t.Run("Should store a message above the threshold", func(t *testing.T) {
+		store := fake.NewBlobStore(t)
+		attrs := map[string]string{"foo": "bar"}
+		var key string
+		store.On("Put", ctx, mock.Anything, []byte("large body")).
+			Run(func(args mock.Arguments) { key = args.String(1) }).
+			Return(nil).
+			Once()
+
+		body, got, err := claimcheck.Offload(ctx, store, "large body", attrs, 5)
+		assert.NoError(t, err)
+
+		var pointer claimcheck.Pointer
+		assert.NoError(t, json.Unmarshal([]byte(body), &pointer))
+		assert.Equal(t, key, pointer.Key)
+		assert.Equal(t, map[string]string{"foo": "bar", claimcheck.AttributeKey: key}, got)
+		assert.Equal(t, map[string]string{"foo": "bar"}, attrs, "the attributes are not modified")
+	})
+
+	t.Run("Should return error when the store fails", func(t *testing.T) {
+		store := fake.NewBlobStore(t)
+		store.On("Put", ctx, mock.Anything, mock.Anything).Return(errors.New("got error")).Once()
+
+		_, _, err := claimcheck.Offload(ctx, store, "large body", nil, 5)
+		assert.ErrorIs(t, err, loafergo.ErrClaimCheck)
+		assert.ErrorContains(t, err, "got error")
+	})
+}
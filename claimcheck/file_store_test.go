@@ -0,0 +1,47 @@
+package claimcheck_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "blobs")
+	store, err := claimcheck.NewFileStore(dir)
+	assert.NoError(t, err)
+
+	t.Run("Should put, get and delete a payload", func(t *testing.T) {
+		assert.NoError(t, store.Put(ctx, "key-1", []byte("payload")))
+
+		got, err := store.Get(ctx, "key-1")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("payload"), got)
+
+		assert.NoError(t, store.Delete(ctx, "key-1"))
+		_, err = store.Get(ctx, "key-1")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Empty(t, entries, "no temporary file is left behind")
+	})
+
+	t.Run("Should ignore a missing payload on delete", func(t *testing.T) {
+		assert.NoError(t, store.Delete(ctx, "missing"))
+	})
+
+	t.Run("Should reject the keys outside of the directory", func(t *testing.T) {
+		assert.Error(t, store.Put(ctx, "../escape", []byte("payload")))
+		_, err := store.Get(ctx, "/etc/passwd")
+		assert.Error(t, err)
+		assert.Error(t, store.Delete(ctx, ""))
+	})
+}
@@ -0,0 +1,158 @@
+package cloudevents_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/cloudevents"
+	"github.com/justcodes/loafer-go/v2/codec"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+type order struct {
+	ID string `json:"id"`
+}
+
+func newEvent(t *testing.T) *cloudevents.Event {
+	e, err := cloudevents.New("/orders", "com.example.order.created", order{ID: "1"})
+	assert.NoError(t, err)
+	e.Subject = "order-1"
+	e.Time = time.Date(2024, 9, 10, 17, 37, 15, 0, time.UTC)
+	e.Extensions = map[string]string{"traceparent": "00-abc-def-01"}
+	return e
+}
+
+func TestEncodeParse(t *testing.T) {
+	for name, mode := range map[string]cloudevents.Mode{"structured": cloudevents.Structured, "binary": cloudevents.Binary} {
+		t.Run("Should round trip the event in "+name+" mode", func(t *testing.T) {
+			in := newEvent(t)
+
+			body, attrs, err := cloudevents.Encode(in, mode)
+			assert.NoError(t, err)
+
+			got, err := cloudevents.Parse([]byte(body), attrs)
+			assert.NoError(t, err)
+			assert.Equal(t, in, got)
+
+			var o order
+			assert.NoError(t, got.DecodeData(&o))
+			assert.Equal(t, "1", o.ID)
+		})
+	}
+
+	t.Run("Should send the attributes as message attributes in binary mode", func(t *testing.T) {
+		body, attrs, err := cloudevents.Encode(newEvent(t), cloudevents.Binary)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"id":"1"}`, body)
+		assert.Equal(t, "1.0", attrs["ce-specversion"])
+		assert.Equal(t, "/orders", attrs["ce-source"])
+		assert.Equal(t, "com.example.order.created", attrs["ce-type"])
+		assert.Equal(t, "order-1", attrs["ce-subject"])
+		assert.Equal(t, "2024-09-10T17:37:15Z", attrs["ce-time"])
+		assert.Equal(t, "00-abc-def-01", attrs["ce-traceparent"])
+		assert.Equal(t, "application/json", attrs[codec.ContentTypeAttribute])
+	})
+
+	t.Run("Should send the whole event as the body in structured mode", func(t *testing.T) {
+		e := newEvent(t)
+		body, attrs, err := cloudevents.Encode(e, cloudevents.Structured)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{codec.ContentTypeAttribute: cloudevents.ContentType}, attrs)
+		assert.JSONEq(t, `{
+			"specversion": "1.0",
+			"id": "`+e.ID+`",
+			"source": "/orders",
+			"type": "com.example.order.created",
+			"subject": "order-1",
+			"time": "2024-09-10T17:37:15Z",
+			"datacontenttype": "application/json",
+			"traceparent": "00-abc-def-01",
+			"data": {"id": "1"}
+		}`, body)
+	})
+
+	t.Run("Should base64 encode the binary data", func(t *testing.T) {
+		in := &cloudevents.Event{ID: "1", Source: "/files", Type: "file", DataContentType: "application/octet-stream", Data: []byte{0, 1, 2}}
+
+		body, _, err := cloudevents.Encode(in, cloudevents.Structured)
+		assert.NoError(t, err)
+		var members map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(body), &members))
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0, 1, 2}), members["data_base64"])
+
+		body, attrs, err := cloudevents.Encode(in, cloudevents.Binary)
+		assert.NoError(t, err)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0, 1, 2}), body)
+
+		got, err := cloudevents.Parse([]byte(body), attrs)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 1, 2}, got.Data)
+	})
+
+	t.Run("Should reject an event with too many attributes in binary mode", func(t *testing.T) {
+		e := newEvent(t)
+		e.Extensions = map[string]string{"traceparent": "00-abc-def-01", "tracestate": "a=b", "partitionkey": "1", "sequence": "1"}
+
+		_, _, err := cloudevents.Encode(e, cloudevents.Binary)
+		assert.ErrorIs(t, err, loafergo.ErrTooManyAttributes)
+		assert.ErrorContains(t, err, "the event needs 11 message attributes, at most 10 are accepted")
+
+		// the structured mode carries the extensions in the body
+		_, _, err = cloudevents.Encode(e, cloudevents.Structured)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject an incomplete event", func(t *testing.T) {
+		_, _, err := cloudevents.Encode(&cloudevents.Event{ID: "1", Source: "/orders"}, cloudevents.Structured)
+		assert.ErrorIs(t, err, loafergo.ErrInvalidCloudEvent)
+
+		_, err = cloudevents.Parse([]byte(`{"specversion":"1.0","id":"1"}`), nil)
+		assert.ErrorIs(t, err, loafergo.ErrInvalidCloudEvent)
+
+		_, err = cloudevents.Parse([]byte(`{"specversion":"0.3","id":"1","source":"/orders","type":"created"}`), nil)
+		assert.ErrorIs(t, err, loafergo.ErrInvalidCloudEvent)
+	})
+
+	t.Run("Should reject a message that is not an event", func(t *testing.T) {
+		_, err := cloudevents.Parse([]byte(`{"id":"1"}`), map[string]string{"foo": "bar"})
+		assert.ErrorIs(t, err, loafergo.ErrNotCloudEvent)
+	})
+}
+
+func TestFromMessage(t *testing.T) {
+	body, attrs, err := cloudevents.Encode(newEvent(t), cloudevents.Binary)
+	assert.NoError(t, err)
+	msgAttrs := make(map[string]loafergo.MessageAttribute, len(attrs))
+	for k, v := range attrs {
+		msgAttrs[k] = loafergo.MessageAttribute{DataType: "String", StringValue: v}
+	}
+
+	t.Run("Should read the event from the SNS envelope", func(t *testing.T) {
+		m := fake.NewMessage(t)
+		m.On("Message").Return(body)
+		m.On("EnvelopeAttributes").Return(msgAttrs).Once()
+
+		got, err := cloudevents.FromMessage(m)
+		assert.NoError(t, err)
+		assert.Equal(t, "/orders", got.Source)
+		assert.Equal(t, "com.example.order.created", got.Type)
+		assert.Equal(t, "order-1", got.Subject)
+		assert.JSONEq(t, `{"id":"1"}`, string(got.Data))
+	})
+
+	t.Run("Should read the event from the queue message", func(t *testing.T) {
+		m := fake.NewMessage(t)
+		m.On("Message").Return("")
+		m.On("Body").Return([]byte(body)).Once()
+		m.On("NativeAttributes").Return(msgAttrs).Once()
+
+		got, err := cloudevents.FromMessage(m)
+		assert.NoError(t, err)
+		assert.Equal(t, "/orders", got.Source)
+	})
+}
@@ -0,0 +1,138 @@
+package cloudevents
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/codec"
+)
+
+// the event attributes, as named in the structured format
+const (
+	attrSpecVersion     = "specversion"
+	attrID              = "id"
+	attrSource          = "source"
+	attrType            = "type"
+	attrSubject         = "subject"
+	attrTime            = "time"
+	attrDataContentType = "datacontenttype"
+	attrDataSchema      = "dataschema"
+	attrData            = "data"
+	attrDataBase64      = "data_base64"
+)
+
+// Encode returns the message body and attributes carrying the event in the given mode.
+// The event SpecVersion defaults to SpecVersion.
+func Encode(e *Event, mode Mode) (string, map[string]string, error) {
+	if e == nil {
+		return "", nil, loafergo.ErrEmptyInput
+	}
+
+	ev := *e
+	if ev.SpecVersion == "" {
+		ev.SpecVersion = SpecVersion
+	}
+	if err := ev.validate(); err != nil {
+		return "", nil, err
+	}
+
+	if mode == Binary {
+		return encodeBinary(&ev)
+	}
+	return encodeStructured(&ev)
+}
+
+func encodeBinary(e *Event) (string, map[string]string, error) {
+	attrs := make(map[string]string, len(e.Extensions)+8)
+	for k, v := range e.Extensions {
+		attrs[AttributePrefix+k] = v
+	}
+	for k, v := range e.attributes() {
+		attrs[AttributePrefix+k] = v
+	}
+	if e.DataContentType != "" {
+		attrs[codec.ContentTypeAttribute] = e.DataContentType
+	}
+	if len(attrs) > MaxMessageAttributes {
+		return "", nil, loafergo.ErrTooManyAttributes.Context(
+			fmt.Errorf("the event needs %d message attributes, at most %d are accepted", len(attrs), MaxMessageAttributes))
+	}
+
+	if isText(e.DataContentType) {
+		return string(e.Data), attrs, nil
+	}
+	return base64.StdEncoding.EncodeToString(e.Data), attrs, nil
+}
+
+func encodeStructured(e *Event) (string, map[string]string, error) {
+	event := make(map[string]any, len(e.Extensions)+10)
+	for k, v := range e.Extensions {
+		event[k] = v
+	}
+	for k, v := range e.attributes() {
+		event[k] = v
+	}
+	if e.DataContentType != "" {
+		event[attrDataContentType] = e.DataContentType
+	}
+
+	if len(e.Data) > 0 {
+		switch {
+		case isJSON(e.DataContentType) && json.Valid(e.Data):
+			event[attrData] = json.RawMessage(e.Data)
+		case isText(e.DataContentType):
+			event[attrData] = string(e.Data)
+		default:
+			event[attrDataBase64] = base64.StdEncoding.EncodeToString(e.Data)
+		}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return "", nil, loafergo.ErrMarshal.Context(err)
+	}
+	return string(body), map[string]string{codec.ContentTypeAttribute: ContentType}, nil
+}
+
+// attributes returns the event context attributes set, except the data content type
+func (e *Event) attributes() map[string]string {
+	attrs := map[string]string{
+		attrSpecVersion: e.SpecVersion,
+		attrID:          e.ID,
+		attrSource:      e.Source,
+		attrType:        e.Type,
+	}
+	if e.Subject != "" {
+		attrs[attrSubject] = e.Subject
+	}
+	if !e.Time.IsZero() {
+		attrs[attrTime] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.DataSchema != "" {
+		attrs[attrDataSchema] = e.DataSchema
+	}
+	return attrs
+}
+
+// isJSON reports whether the data is JSON, which is the default
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// isText reports whether the data can be sent as is in a message body
+func isText(contentType string) bool {
+	if isJSON(contentType) {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "xml"))
+}
@@ -0,0 +1,121 @@
+// Package cloudevents implements the CloudEvents 1.0 AWS SNS and SQS bindings used by loafer.
+//
+// An event is either sent in structured mode, the whole event being the JSON message body,
+// or in binary mode, the event attributes being the ce-* message attributes and the data the message body.
+// The producers send events with ProduceEvent, and FromMessage reads the event of a received message
+// whatever the mode it was sent in, with or without the SNS notification envelope.
+package cloudevents
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/codec"
+)
+
+const (
+	// SpecVersion is the version of the CloudEvents specification implemented by the package
+	SpecVersion = "1.0"
+	// ContentType is the content type of the events sent in structured mode
+	ContentType = "application/cloudevents+json"
+	// AttributePrefix prefixes the message attributes holding the event attributes in binary mode
+	AttributePrefix = "ce-"
+	// MaxMessageAttributes is the number of message attributes SNS and SQS accept
+	MaxMessageAttributes = 10
+)
+
+// Mode is the way an event is carried by a message
+type Mode int
+
+const (
+	// Structured sends the whole event, data included, as the JSON message body
+	Structured Mode = iota
+	// Binary sends the event attributes as ce-* message attributes and the data as the message body.
+	// SNS and SQS accept up to 10 message attributes, and the event uses up to 8 of them, extensions excluded:
+	// an event needing more fails to encode with ErrTooManyAttributes.
+	Binary
+)
+
+// Event is a CloudEvents 1.0 event
+type Event struct {
+	// Time is when the occurrence happened, optional
+	Time time.Time
+	// Extensions holds the extension attributes, e.g. traceparent
+	Extensions map[string]string
+	// ID identifies the event, along with Source
+	ID string
+	// Source identifies the context in which the event happened
+	Source string
+	// Type describes the kind of occurrence, e.g. com.example.order.created
+	Type string
+	// Subject identifies the subject of the event in the context of the source, optional
+	Subject string
+	// DataContentType is the content type of Data, application/json when empty
+	DataContentType string
+	// DataSchema identifies the schema Data adheres to, optional
+	DataSchema string
+	// SpecVersion is the version of the specification the event uses, SpecVersion when empty
+	SpecVersion string
+	// Data is the encoded event payload
+	Data []byte
+}
+
+// New creates an event with a random ID, the current time and data marshaled as JSON
+func New(source, eventType string, data any) (*Event, error) {
+	e := &Event{
+		ID:          NewID(),
+		Source:      source,
+		Type:        eventType,
+		Time:        time.Now().UTC(),
+		SpecVersion: SpecVersion,
+	}
+
+	if data != nil {
+		b, err := codec.JSON.Marshal(data)
+		if err != nil {
+			return nil, loafergo.ErrMarshal.Context(err)
+		}
+		e.Data = b
+		e.DataContentType = codec.JSON.ContentType()
+	}
+	return e, nil
+}
+
+// NewID returns a random UUID, to be used as event ID
+func NewID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// DecodeData unmarshals the event data into out, with the codec matching its content type
+func (e *Event) DecodeData(out any) error {
+	c := loafergo.Codec(codec.JSON)
+	if e.DataContentType != "" {
+		var err error
+		c, err = codec.Lookup(e.DataContentType, "")
+		if err != nil {
+			return err
+		}
+	}
+	return c.Unmarshal(e.Data, out)
+}
+
+func (e *Event) validate() error {
+	switch {
+	case e.SpecVersion != SpecVersion:
+		return loafergo.ErrInvalidCloudEvent.Context(fmt.Errorf("unsupported specversion %q", e.SpecVersion))
+	case e.ID == "":
+		return loafergo.ErrInvalidCloudEvent.Context(errors.New("id is missing"))
+	case e.Source == "":
+		return loafergo.ErrInvalidCloudEvent.Context(errors.New("source is missing"))
+	case e.Type == "":
+		return loafergo.ErrInvalidCloudEvent.Context(errors.New("type is missing"))
+	}
+	return nil
+}
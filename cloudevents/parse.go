@@ -0,0 +1,155 @@
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/codec"
+)
+
+// FromMessage returns the event carried by a received message, whatever the mode it was sent in.
+// When the message was published to a topic without raw message delivery, the event is read from
+// the SNS notification envelope, otherwise from the message body and attributes.
+// Messages that do not carry an event return ErrNotCloudEvent.
+func FromMessage(m loafergo.Message) (*Event, error) {
+	if m.Message() != "" {
+		return Parse([]byte(m.Message()), stringAttributes(m.EnvelopeAttributes()))
+	}
+	return Parse(m.Body(), stringAttributes(m.NativeAttributes()))
+}
+
+// Parse returns the event carried by a message body and attributes.
+// An event is in binary mode when the ce-specversion attribute is set, and in structured mode
+// when the content-type attribute is application/cloudevents+json or the body is a JSON object with a specversion.
+func Parse(body []byte, attributes map[string]string) (*Event, error) {
+	if attributes[AttributePrefix+attrSpecVersion] != "" {
+		return parseBinary(body, attributes)
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(attributes[codec.ContentTypeAttribute]); mediaType == ContentType {
+		return parseStructured(body)
+	}
+
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		var probe struct {
+			SpecVersion string `json:"specversion"`
+		}
+		if json.Unmarshal(trimmed, &probe) == nil && probe.SpecVersion != "" {
+			return parseStructured(body)
+		}
+	}
+	return nil, loafergo.ErrNotCloudEvent
+}
+
+func parseBinary(body []byte, attributes map[string]string) (*Event, error) {
+	e := &Event{DataContentType: attributes[codec.ContentTypeAttribute]}
+	for k, v := range attributes {
+		name, ok := strings.CutPrefix(strings.ToLower(k), AttributePrefix)
+		if !ok {
+			continue
+		}
+		if err := e.set(name, v); err != nil {
+			return nil, err
+		}
+	}
+
+	e.Data = body
+	if !isText(e.DataContentType) {
+		data, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			return nil, loafergo.ErrInvalidCloudEvent.Context(err)
+		}
+		e.Data = data
+	}
+	return e, e.validate()
+}
+
+func parseStructured(body []byte) (*Event, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, loafergo.ErrInvalidCloudEvent.Context(err)
+	}
+
+	e := &Event{}
+	for k, raw := range members {
+		switch k {
+		case attrData, attrDataBase64:
+			continue
+		}
+
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			// extensions may be numbers or booleans, they are kept in their JSON form
+			v = string(raw)
+		}
+		if err := e.set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	if raw, ok := members[attrDataBase64]; ok {
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return nil, loafergo.ErrInvalidCloudEvent.Context(err)
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, loafergo.ErrInvalidCloudEvent.Context(err)
+		}
+		e.Data = data
+	} else if raw, ok := members[attrData]; ok {
+		e.Data = raw
+		// a non JSON data is carried as a JSON string
+		var text string
+		if !isJSON(e.DataContentType) && json.Unmarshal(raw, &text) == nil {
+			e.Data = []byte(text)
+		}
+	}
+	return e, e.validate()
+}
+
+// set sets the event attribute named name, extensions included
+func (e *Event) set(name, value string) error {
+	switch name {
+	case attrSpecVersion:
+		e.SpecVersion = value
+	case attrID:
+		e.ID = value
+	case attrSource:
+		e.Source = value
+	case attrType:
+		e.Type = value
+	case attrSubject:
+		e.Subject = value
+	case attrDataContentType:
+		e.DataContentType = value
+	case attrDataSchema:
+		e.DataSchema = value
+	case attrTime:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return loafergo.ErrInvalidCloudEvent.Context(fmt.Errorf("time: %w", err))
+		}
+		e.Time = t
+	default:
+		if e.Extensions == nil {
+			e.Extensions = map[string]string{}
+		}
+		e.Extensions[name] = value
+	}
+	return nil
+}
+
+func stringAttributes(attrs map[string]loafergo.MessageAttribute) map[string]string {
+	a := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		a[k] = v.StringValue
+	}
+	return a
+}
@@ -0,0 +1,191 @@
+// Package codec provides the codecs used to marshal the message payloads, and the
+// content-type and content-encoding message attributes signalling them to the consumers.
+//
+// A producer configured with a codec marshals the Payload of its inputs and sends the
+// attributes along, a route decodes the messages with the codec they name, whatever the
+// codec of the route is. The codec of the route is used for the messages without attributes, and for
+// the ones naming an unknown media type, such as text/plain set by an external producer, when it parses them.
+//
+// SNS and SQS only carry text, so the payloads of the binary and compressed codecs are base64 encoded.
+package codec
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+const (
+	// ContentTypeAttribute is the message attribute holding the content type of the payload
+	ContentTypeAttribute = "content-type"
+	// ContentEncodingAttribute is the message attribute holding the compression of the payload, if any
+	ContentEncodingAttribute = "content-encoding"
+
+	// jsonSuffix is the structured syntax suffix of the media types based on JSON, see RFC 6839
+	jsonSuffix = "+json"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]loafergo.Codec{
+		JSON.ContentType():     JSON,
+		Protobuf.ContentType(): Protobuf,
+	}
+)
+
+// contentEncoder is implemented by the codecs compressing the payload of another codec
+type contentEncoder interface {
+	ContentEncoding() string
+}
+
+// Register makes a codec available to the consumers, by its content type.
+// JSON and Protobuf are registered by default.
+func Register(c loafergo.Codec) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[c.ContentType()] = c
+}
+
+// Lookup returns the codec matching the content-type and content-encoding attributes of a message.
+// The media types with a +json structured syntax suffix, such as application/cloudevents+json,
+// resolve to JSON unless a codec is registered for them. The gzip and zstd encodings are supported.
+func Lookup(contentType, contentEncoding string) (loafergo.Codec, error) {
+	c, err := lookupMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	return withEncoding(c, contentEncoding)
+}
+
+// lookupMediaType returns the codec registered for the media type of contentType
+func lookupMediaType(contentType string) (loafergo.Codec, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, loafergo.ErrUnsupportedCodec.Context(err)
+	}
+
+	registryMu.RLock()
+	c, ok := registry[mediaType]
+	registryMu.RUnlock()
+	if !ok && strings.HasSuffix(mediaType, jsonSuffix) {
+		c, ok = JSON, true
+	}
+	if !ok {
+		return nil, loafergo.ErrUnsupportedCodec.Context(fmt.Errorf("content-type %q", contentType))
+	}
+	return c, nil
+}
+
+// withEncoding wraps c with the compression named by contentEncoding
+func withEncoding(c loafergo.Codec, contentEncoding string) (loafergo.Codec, error) {
+	switch strings.ToLower(contentEncoding) {
+	case "", "identity":
+		return c, nil
+	case gzipEncoding:
+		return Gzip(c), nil
+	case zstdEncoding:
+		return Zstd(c), nil
+	default:
+		return nil, loafergo.ErrUnsupportedCodec.Context(fmt.Errorf("content-encoding %q", contentEncoding))
+	}
+}
+
+// Encode marshals v with c and returns the message body along with the attributes naming the codec
+func Encode(c loafergo.Codec, v any) (string, map[string]string, error) {
+	data, err := c.Marshal(v)
+	if err != nil {
+		return "", nil, loafergo.ErrMarshal.Context(err)
+	}
+
+	attrs := map[string]string{ContentTypeAttribute: c.ContentType()}
+	if encoding := contentEncoding(c); encoding != "" {
+		attrs[ContentEncodingAttribute] = encoding
+	}
+
+	if isText(c) {
+		return string(data), attrs, nil
+	}
+	return base64.StdEncoding.EncodeToString(data), attrs, nil
+}
+
+// Decode unmarshals the message body into v, with the codec named by the message attributes.
+// When the attributes do not name any codec, fallback is used, or JSON when it is nil.
+//
+// When they name an unknown media type, the body is decoded with fallback, or JSON, as it was before
+// the codecs were looked up, and ErrUnsupportedCodec is returned only when it does not parse.
+func Decode(body []byte, attributes map[string]string, v any, fallback loafergo.Codec) error {
+	if fallback == nil {
+		fallback = JSON
+	}
+
+	contentType := attributes[ContentTypeAttribute]
+	if contentType == "" {
+		return decode(fallback, body, v)
+	}
+
+	c, err := lookupMediaType(contentType)
+	if err != nil {
+		if decodeErr := decodeUnknown(fallback, body, attributes[ContentEncodingAttribute], v); decodeErr != nil {
+			return errors.Join(err, decodeErr)
+		}
+		return nil
+	}
+
+	c, err = withEncoding(c, attributes[ContentEncodingAttribute])
+	if err != nil {
+		return err
+	}
+	return decode(c, body, v)
+}
+
+// decodeUnknown decodes the body of a message naming an unknown media type with fallback,
+// compressed with encoding unless fallback is a compressed codec already
+func decodeUnknown(fallback loafergo.Codec, body []byte, encoding string, v any) error {
+	c := fallback
+	if contentEncoding(c) == "" {
+		var err error
+		if c, err = withEncoding(c, encoding); err != nil {
+			return err
+		}
+	}
+	return decode(c, body, v)
+}
+
+func decode(c loafergo.Codec, body []byte, v any) error {
+	if !isText(c) {
+		data := make([]byte, base64.StdEncoding.DecodedLen(len(body)))
+		n, err := base64.StdEncoding.Decode(data, body)
+		if err != nil {
+			return err
+		}
+		body = data[:n]
+	}
+	return c.Unmarshal(body, v)
+}
+
+func contentEncoding(c loafergo.Codec) string {
+	if e, ok := c.(contentEncoder); ok {
+		return e.ContentEncoding()
+	}
+	return ""
+}
+
+// isText reports whether the payloads of c can be sent as is
+func isText(c loafergo.Codec) bool {
+	if contentEncoding(c) != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(c.ContentType())
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml")
+}
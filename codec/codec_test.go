@@ -0,0 +1,238 @@
+package codec_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/codec"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+type order struct {
+	ID   string `json:"id"`
+	Item string `json:"item"`
+}
+
+func TestEncodeDecode(t *testing.T) {
+	testCases := []struct {
+		codec    loafergo.Codec
+		attrs    map[string]string
+		name     string
+		isBase64 bool
+	}{
+		{
+			name:  "JSON",
+			codec: codec.JSON,
+			attrs: map[string]string{codec.ContentTypeAttribute: "application/json"},
+		},
+		{
+			name:     "Gzip JSON",
+			codec:    codec.Gzip(codec.JSON),
+			attrs:    map[string]string{codec.ContentTypeAttribute: "application/json", codec.ContentEncodingAttribute: "gzip"},
+			isBase64: true,
+		},
+		{
+			name:     "Zstd JSON",
+			codec:    codec.Zstd(codec.JSON),
+			attrs:    map[string]string{codec.ContentTypeAttribute: "application/json", codec.ContentEncodingAttribute: "zstd"},
+			isBase64: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := order{ID: "1", Item: "book"}
+
+			body, attrs, err := codec.Encode(tc.codec, in)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.attrs, attrs)
+
+			_, b64Err := base64.StdEncoding.DecodeString(body)
+			assert.Equal(t, tc.isBase64, b64Err == nil)
+
+			var out order
+			assert.NoError(t, codec.Decode([]byte(body), attrs, &out, nil))
+			assert.Equal(t, in, out)
+		})
+	}
+}
+
+func TestProtobuf(t *testing.T) {
+	in := wrapperspb.String("hello")
+
+	body, attrs, err := codec.Encode(codec.Zstd(codec.Protobuf), in)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/x-protobuf", attrs[codec.ContentTypeAttribute])
+
+	t.Run("Should decode into a message", func(t *testing.T) {
+		out := &wrapperspb.StringValue{}
+		assert.NoError(t, codec.Decode([]byte(body), attrs, out, nil))
+		assert.True(t, proto.Equal(in, out))
+	})
+
+	t.Run("Should decode into a pointer to a message", func(t *testing.T) {
+		var out *wrapperspb.StringValue
+		assert.NoError(t, codec.Decode([]byte(body), attrs, &out, nil))
+		assert.True(t, proto.Equal(in, out))
+	})
+
+	t.Run("Should reject the values that are not messages", func(t *testing.T) {
+		_, _, err := codec.Encode(codec.Protobuf, order{})
+		assert.ErrorIs(t, err, loafergo.ErrMarshal)
+
+		var out order
+		assert.Error(t, codec.Decode([]byte(body), attrs, &out, nil))
+	})
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Should use the fallback without content type", func(t *testing.T) {
+		body, _, err := codec.Encode(codec.Gzip(codec.JSON), order{ID: "1"})
+		assert.NoError(t, err)
+
+		var out order
+		assert.NoError(t, codec.Decode([]byte(body), nil, &out, codec.Gzip(codec.JSON)))
+		assert.Equal(t, "1", out.ID)
+	})
+
+	t.Run("Should use JSON by default", func(t *testing.T) {
+		var out order
+		assert.NoError(t, codec.Decode([]byte(`{"id":"1"}`), map[string]string{}, &out, nil))
+		assert.Equal(t, "1", out.ID)
+	})
+
+	t.Run("Should accept content type parameters", func(t *testing.T) {
+		var out order
+		attrs := map[string]string{codec.ContentTypeAttribute: "application/json; charset=utf-8"}
+		assert.NoError(t, codec.Decode([]byte(`{"id":"1"}`), attrs, &out, nil))
+		assert.Equal(t, "1", out.ID)
+	})
+
+	t.Run("Should decode the +json media types as JSON", func(t *testing.T) {
+		for _, contentType := range []string{"application/cloudevents+json", "application/vnd.order+json; charset=utf-8"} {
+			var out order
+			attrs := map[string]string{codec.ContentTypeAttribute: contentType}
+			assert.NoError(t, codec.Decode([]byte(`{"id":"1"}`), attrs, &out, nil))
+			assert.Equal(t, "1", out.ID)
+		}
+	})
+
+	t.Run("Should decode an unknown content type with the fallback", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			attrs    map[string]string
+			fallback loafergo.Codec
+			body     func() string
+		}{
+			{
+				name:  "JSON by default",
+				attrs: map[string]string{codec.ContentTypeAttribute: "text/x-order; charset=utf-8"},
+				body:  func() string { return `{"id":"1"}` },
+			},
+			{
+				name:  "malformed content type",
+				attrs: map[string]string{codec.ContentTypeAttribute: "json"},
+				body:  func() string { return `{"id":"1"}` },
+			},
+			{
+				name:     "route codec",
+				attrs:    map[string]string{codec.ContentTypeAttribute: "application/octet-stream"},
+				fallback: codec.Gzip(codec.JSON),
+				body: func() string {
+					body, _, _ := codec.Encode(codec.Gzip(codec.JSON), order{ID: "1"})
+					return body
+				},
+			},
+			{
+				name:  "content encoding",
+				attrs: map[string]string{codec.ContentTypeAttribute: "application/octet-stream", codec.ContentEncodingAttribute: "zstd"},
+				body: func() string {
+					body, _, _ := codec.Encode(codec.Zstd(codec.JSON), order{ID: "1"})
+					return body
+				},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				var out order
+				assert.NoError(t, codec.Decode([]byte(tc.body()), tc.attrs, &out, tc.fallback))
+				assert.Equal(t, "1", out.ID)
+			})
+		}
+	})
+
+	t.Run("Should reject an unknown content type the fallback cannot parse", func(t *testing.T) {
+		var out order
+		attrs := map[string]string{codec.ContentTypeAttribute: "application/avro"}
+		assert.ErrorIs(t, codec.Decode([]byte("\x00avro"), attrs, &out, nil), loafergo.ErrUnsupportedCodec)
+	})
+
+	t.Run("Should reject an unknown content encoding", func(t *testing.T) {
+		var out order
+		attrs := map[string]string{codec.ContentTypeAttribute: "application/json", codec.ContentEncodingAttribute: "br"}
+		assert.ErrorIs(t, codec.Decode([]byte(`{}`), attrs, &out, nil), loafergo.ErrUnsupportedCodec)
+	})
+}
+
+// textCodec is a custom codec exchanging plain strings
+type textCodec struct{}
+
+func (textCodec) ContentType() string { return "text/plain" }
+
+func (textCodec) Marshal(v any) ([]byte, error) { return []byte(v.(string)), nil }
+
+func (textCodec) Unmarshal(data []byte, v any) error {
+	*v.(*string) = string(data)
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	codec.Register(textCodec{})
+
+	body, _, err := codec.Encode(textCodec{}, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", body)
+
+	c, err := codec.Lookup("text/plain", "gzip")
+	assert.NoError(t, err)
+
+	compressed, attrs, err := codec.Encode(c, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "gzip", attrs[codec.ContentEncodingAttribute])
+
+	var out string
+	assert.NoError(t, codec.Decode([]byte(compressed), attrs, &out, nil))
+	assert.Equal(t, "hello", out)
+}
+
+func TestCustomCodec(t *testing.T) {
+	t.Run("Should base64 encode the binary payloads", func(t *testing.T) {
+		c := fake.NewCodec(t)
+		c.On("ContentType").Return("application/x-custom")
+		c.On("Marshal", "hello").Return([]byte{0x00, 0xff}, nil).Once()
+		c.On("Unmarshal", []byte{0x00, 0xff}, mock.Anything).Return(nil).Once()
+		codec.Register(c)
+
+		body, attrs, err := codec.Encode(c, "hello")
+		assert.NoError(t, err)
+		assert.Equal(t, "AP8=", body)
+		assert.Equal(t, map[string]string{codec.ContentTypeAttribute: "application/x-custom"}, attrs)
+
+		var out string
+		assert.NoError(t, codec.Decode([]byte(body), attrs, &out, nil))
+	})
+
+	t.Run("Should wrap the marshal errors", func(t *testing.T) {
+		c := fake.NewCodec(t)
+		c.On("Marshal", "hello").Return(nil, errors.New("boom")).Once()
+
+		_, _, err := codec.Encode(c, "hello")
+		assert.ErrorIs(t, err, loafergo.ErrMarshal)
+	})
+}
@@ -0,0 +1,101 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+const (
+	gzipEncoding = "gzip"
+	zstdEncoding = "zstd"
+)
+
+// Gzip compresses the payloads marshaled by c with gzip
+func Gzip(c loafergo.Codec) loafergo.Codec {
+	return &compressed{Codec: c, encoding: gzipEncoding, compress: gzipCompress, decompress: gzipDecompress}
+}
+
+// Zstd compresses the payloads marshaled by c with zstd
+func Zstd(c loafergo.Codec) loafergo.Codec {
+	return &compressed{Codec: c, encoding: zstdEncoding, compress: zstdCompress, decompress: zstdDecompress}
+}
+
+// compressed wraps a codec, compressing its payloads
+type compressed struct {
+	loafergo.Codec
+	compress   func(data []byte) ([]byte, error)
+	decompress func(data []byte) ([]byte, error)
+	encoding   string
+}
+
+// ContentEncoding returns the compression algorithm, sent as the content-encoding attribute
+func (c *compressed) ContentEncoding() string {
+	return c.encoding
+}
+
+// Marshal returns the compressed encoding of v
+func (c *compressed) Marshal(v any) ([]byte, error) {
+	data, err := c.Codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.compress(data)
+}
+
+// Unmarshal decompresses data and parses it into v
+func (c *compressed) Unmarshal(data []byte, v any) error {
+	data, err := c.decompress(data)
+	if err != nil {
+		return err
+	}
+	return c.Codec.Unmarshal(data, v)
+}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return io.ReadAll(r)
+}
+
+// the zstd encoder and decoder are safe for concurrent use of EncodeAll and DecodeAll
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) { return zstd.NewWriter(nil) })
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) { return zstd.NewReader(nil) })
+)
+
+func zstdCompress(data []byte) ([]byte, error) {
+	enc, err := zstdEncoder()
+	if err != nil {
+		return nil, err
+	}
+	return enc.EncodeAll(data, nil), nil
+}
+
+func zstdDecompress(data []byte) ([]byte, error) {
+	dec, err := zstdDecoder()
+	if err != nil {
+		return nil, err
+	}
+	return dec.DecodeAll(data, nil)
+}
@@ -0,0 +1,25 @@
+package codec
+
+import (
+	"encoding/json"
+)
+
+// JSON marshals the payloads with encoding/json
+var JSON = jsonCodec{}
+
+type jsonCodec struct{}
+
+// ContentType returns application/json
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+// Marshal returns the JSON encoding of v
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal parses the JSON encoded data into v
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
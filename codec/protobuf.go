@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Protobuf marshals the payloads with the protobuf wire format, they must be proto.Message
+var Protobuf = protobufCodec{}
+
+type protobufCodec struct{}
+
+// ContentType returns application/x-protobuf
+func (protobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+// Marshal returns the wire format encoding of v
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal parses the wire format data into v.
+// v is either a proto.Message or a pointer to one, which is allocated.
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+
+	// e.g. the **pb.Order given by loafergo.TypedHandler[*pb.Order]
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Pointer {
+		return fmt.Errorf("%T is not a proto.Message", v)
+	}
+
+	m, ok := reflect.New(rv.Elem().Type().Elem()).Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto.Message", v)
+	}
+	if err := proto.Unmarshal(data, m); err != nil {
+		return err
+	}
+	rv.Elem().Set(reflect.ValueOf(m))
+	return nil
+}
@@ -1,13 +1,36 @@
 package loafergo
 
-import "time"
+import (
+	"context"
+	"log/slog"
+	"time"
+)
 
 const defaultRetryTimeout = 5 * time.Second
 
+// PanicHook is called when a handler panics while handling a message.
+// v is the value passed to panic.
+type PanicHook func(ctx context.Context, r Router, msg Message, v any)
+
 // Config defines settings shared across the manager and routes.
 type Config struct {
-	Logger       Logger
+	Logger Logger
+	// SlogHandler receives the structured records of the manager and routes.
+	// When set, it takes precedence over Logger.
+	SlogHandler slog.Handler
+	// OnPanic is called when a handler panics. The panic is recovered and
+	// the message goes through the handler error path as a *PanicError.
+	OnPanic PanicHook
+	// Middlewares wrap the message handling of every route.
+	// They run before the middlewares registered on the route itself.
+	Middlewares []Middleware
+	// Metrics receives the throughput, latency and failure events of the routes.
+	// Defaults to NoOpMetrics.
+	Metrics      Metrics
 	RetryTimeout time.Duration
+	// LogMessageBody adds the message body to the records logged for a message.
+	// Bodies may carry sensitive data, so it is disabled by default.
+	LogMessageBody bool
 }
 
 // loadConfig applies default values if not provided.
@@ -20,9 +43,17 @@ func loadConfig(cfg *Config) *Config {
 		cfg.RetryTimeout = defaultRetryTimeout
 	}
 
+	if cfg.SlogHandler != nil {
+		cfg.Logger = NewSlogLogger(cfg.SlogHandler)
+	}
+
 	if cfg.Logger == nil {
 		cfg.Logger = newDefaultLogger()
 	}
 
+	if cfg.Metrics == nil {
+		cfg.Metrics = NoOpMetrics{}
+	}
+
 	return cfg
 }
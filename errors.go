@@ -19,6 +19,17 @@ func (e Error) Error() string {
 	return e.message
 }
 
+// Is reports whether target is the same predefined error, regardless of the context.
+func (e Error) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && t.message == e.message
+}
+
+// Unwrap returns the context error.
+func (e Error) Unwrap() error {
+	return e.err
+}
+
 // Context wraps a base error with context.
 func (e Error) Context(err error) Error {
 	return Error{
@@ -27,15 +38,40 @@ func (e Error) Context(err error) Error {
 	}
 }
 
+// PanicError is the error produced when a handler panics while handling a message.
+type PanicError struct {
+	// Value is the value passed to panic
+	Value any
+	// Stack is the stack trace of the goroutine that panicked
+	Stack []byte
+}
+
+// Error returns the panic value as an error message.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panic: %v", e.Value)
+}
+
 // Predefined errors.
 var (
-	ErrNoRoute            = Error{message: "no routes registered"}
-	ErrGetMessage         = Error{message: "failed to receive messages"}
-	ErrInvalidCreds       = Error{message: "invalid aws credentials"}
-	ErrMarshal            = Error{message: "unable to marshal request"}
-	ErrNoSQSClient        = Error{message: "sqs client is nil"}
-	ErrNoHandler          = Error{message: "handler is nil"}
-	ErrEmptyParam         = Error{message: "required parameter is missing"}
-	ErrEmptyRequiredField = Error{message: "required field is missing"}
-	ErrEmptyInput         = Error{message: "input must be filled"}
+	ErrNoRoute             = Error{message: "no routes registered"}
+	ErrGetMessage          = Error{message: "failed to receive messages"}
+	ErrQueueBacklog        = Error{message: "failed to get the queue backlog"}
+	ErrInvalidCreds        = Error{message: "invalid aws credentials"}
+	ErrMarshal             = Error{message: "unable to marshal request"}
+	ErrNoSQSClient         = Error{message: "sqs client is nil"}
+	ErrNoHandler           = Error{message: "handler is nil"}
+	ErrEmptyParam          = Error{message: "required parameter is missing"}
+	ErrEmptyRequiredField  = Error{message: "required field is missing"}
+	ErrEmptyInput          = Error{message: "input must be filled"}
+	ErrDeadLetter          = Error{message: "failed to dead-letter message"}
+	ErrUndecodable         = Error{message: "unable to decode message"}
+	ErrDeleteMessage       = Error{message: "failed to delete message"}
+	ErrClaimCheck          = Error{message: "failed to handle claim check payload"}
+	ErrUnsupportedCodec    = Error{message: "unsupported content type or encoding"}
+	ErrNotCloudEvent       = Error{message: "message is not a cloud event"}
+	ErrInvalidCloudEvent   = Error{message: "invalid cloud event"}
+	ErrTooManyAttributes   = Error{message: "too many message attributes"}
+	ErrUnknownMessageType  = Error{message: "no handler registered for message type"}
+	ErrIdempotency         = Error{message: "failed to check message idempotency"}
+	ErrDuplicateInProgress = Error{message: "message is already being handled"}
 )
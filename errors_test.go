@@ -17,6 +17,15 @@ func TestError_Context(t *testing.T) {
 	assert.Equal(t, "required parameter is missing", wrapped.Context(nil).Error()) // should not panic with nil
 }
 
+func TestError_Is(t *testing.T) {
+	reason := errors.New("wrapped reason")
+	wrapped := loafergo.ErrGetMessage.Context(reason)
+
+	assert.ErrorIs(t, wrapped, loafergo.ErrGetMessage)
+	assert.ErrorIs(t, wrapped, reason)
+	assert.NotErrorIs(t, wrapped, loafergo.ErrNoRoute)
+}
+
 func TestPredefinedErrors(t *testing.T) {
 	assert.Equal(t, "no routes registered", loafergo.ErrNoRoute.Error())
 	assert.Equal(t, "failed to receive messages", loafergo.ErrGetMessage.Error())
@@ -27,4 +36,20 @@ func TestPredefinedErrors(t *testing.T) {
 	assert.Equal(t, "required parameter is missing", loafergo.ErrEmptyParam.Error())
 	assert.Equal(t, "required field is missing", loafergo.ErrEmptyRequiredField.Error())
 	assert.Equal(t, "input must be filled", loafergo.ErrEmptyInput.Error())
+	assert.Equal(t, "failed to dead-letter message", loafergo.ErrDeadLetter.Error())
+	assert.Equal(t, "unable to decode message", loafergo.ErrUndecodable.Error())
+	assert.Equal(t, "failed to delete message", loafergo.ErrDeleteMessage.Error())
+	assert.Equal(t, "failed to handle claim check payload", loafergo.ErrClaimCheck.Error())
+	assert.Equal(t, "unsupported content type or encoding", loafergo.ErrUnsupportedCodec.Error())
+	assert.Equal(t, "message is not a cloud event", loafergo.ErrNotCloudEvent.Error())
+	assert.Equal(t, "invalid cloud event", loafergo.ErrInvalidCloudEvent.Error())
+	assert.Equal(t, "too many message attributes", loafergo.ErrTooManyAttributes.Error())
+	assert.Equal(t, "no handler registered for message type", loafergo.ErrUnknownMessageType.Error())
+	assert.Equal(t, "failed to check message idempotency", loafergo.ErrIdempotency.Error())
+	assert.Equal(t, "message is already being handled", loafergo.ErrDuplicateInProgress.Error())
+}
+
+func TestPanicError(t *testing.T) {
+	err := &loafergo.PanicError{Value: "boom", Stack: []byte("stack")}
+	assert.Equal(t, "handler panic: boom", err.Error())
 }
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	mock "github.com/stretchr/testify/mock"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
 )
 
 // NewMessage creates a new instance of Message. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -399,6 +401,52 @@ func (_c *Message_Dispatch_Call) RunAndReturn(run func()) *Message_Dispatch_Call
 	return _c
 }
 
+// EnvelopeAttributes provides a mock function for the type Message
+func (_mock *Message) EnvelopeAttributes() map[string]loafergo.MessageAttribute {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnvelopeAttributes")
+	}
+
+	var r0 map[string]loafergo.MessageAttribute
+	if returnFunc, ok := ret.Get(0).(func() map[string]loafergo.MessageAttribute); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]loafergo.MessageAttribute)
+		}
+	}
+	return r0
+}
+
+// Message_EnvelopeAttributes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnvelopeAttributes'
+type Message_EnvelopeAttributes_Call struct {
+	*mock.Call
+}
+
+// EnvelopeAttributes is a helper method to define mock.On call
+func (_e *Message_Expecter) EnvelopeAttributes() *Message_EnvelopeAttributes_Call {
+	return &Message_EnvelopeAttributes_Call{Call: _e.mock.On("EnvelopeAttributes")}
+}
+
+func (_c *Message_EnvelopeAttributes_Call) Run(run func()) *Message_EnvelopeAttributes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Message_EnvelopeAttributes_Call) Return(stringToMessageAttribute map[string]loafergo.MessageAttribute) *Message_EnvelopeAttributes_Call {
+	_c.Call.Return(stringToMessageAttribute)
+	return _c
+}
+
+func (_c *Message_EnvelopeAttributes_Call) RunAndReturn(run func() map[string]loafergo.MessageAttribute) *Message_EnvelopeAttributes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Identifier provides a mock function for the type Message
 func (_mock *Message) Identifier() string {
 	ret := _mock.Called()
@@ -487,6 +535,50 @@ func (_c *Message_Message_Call) RunAndReturn(run func() string) *Message_Message
 	return _c
 }
 
+// MessageID provides a mock function for the type Message
+func (_mock *Message) MessageID() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for MessageID")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Message_MessageID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MessageID'
+type Message_MessageID_Call struct {
+	*mock.Call
+}
+
+// MessageID is a helper method to define mock.On call
+func (_e *Message_Expecter) MessageID() *Message_MessageID_Call {
+	return &Message_MessageID_Call{Call: _e.mock.On("MessageID")}
+}
+
+func (_c *Message_MessageID_Call) Run(run func()) *Message_MessageID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Message_MessageID_Call) Return(s string) *Message_MessageID_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *Message_MessageID_Call) RunAndReturn(run func() string) *Message_MessageID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Metadata provides a mock function for the type Message
 func (_mock *Message) Metadata() map[string]string {
 	ret := _mock.Called()
@@ -533,6 +625,52 @@ func (_c *Message_Metadata_Call) RunAndReturn(run func() map[string]string) *Mes
 	return _c
 }
 
+// NativeAttributes provides a mock function for the type Message
+func (_mock *Message) NativeAttributes() map[string]loafergo.MessageAttribute {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for NativeAttributes")
+	}
+
+	var r0 map[string]loafergo.MessageAttribute
+	if returnFunc, ok := ret.Get(0).(func() map[string]loafergo.MessageAttribute); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]loafergo.MessageAttribute)
+		}
+	}
+	return r0
+}
+
+// Message_NativeAttributes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'NativeAttributes'
+type Message_NativeAttributes_Call struct {
+	*mock.Call
+}
+
+// NativeAttributes is a helper method to define mock.On call
+func (_e *Message_Expecter) NativeAttributes() *Message_NativeAttributes_Call {
+	return &Message_NativeAttributes_Call{Call: _e.mock.On("NativeAttributes")}
+}
+
+func (_c *Message_NativeAttributes_Call) Run(run func()) *Message_NativeAttributes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Message_NativeAttributes_Call) Return(stringToMessageAttribute map[string]loafergo.MessageAttribute) *Message_NativeAttributes_Call {
+	_c.Call.Return(stringToMessageAttribute)
+	return _c
+}
+
+func (_c *Message_NativeAttributes_Call) RunAndReturn(run func() map[string]loafergo.MessageAttribute) *Message_NativeAttributes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SystemAttributeByKey provides a mock function for the type Message
 func (_mock *Message) SystemAttributeByKey(key string) string {
 	ret := _mock.Called(key)
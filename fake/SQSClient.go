@@ -204,6 +204,172 @@ func (_c *SQSClient_DeleteMessage_Call) RunAndReturn(run func(ctx context.Contex
 	return _c
 }
 
+// DeleteMessageBatch provides a mock function for the type SQSClient
+func (_mock *SQSClient) DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	var tmpRet mock.Arguments
+	if len(optFns) > 0 {
+		tmpRet = _mock.Called(ctx, params, optFns)
+	} else {
+		tmpRet = _mock.Called(ctx, params)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMessageBatch")
+	}
+
+	var r0 *sqs.DeleteMessageBatchOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sqs.DeleteMessageBatchInput, ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)); ok {
+		return returnFunc(ctx, params, optFns...)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sqs.DeleteMessageBatchInput, ...func(*sqs.Options)) *sqs.DeleteMessageBatchOutput); ok {
+		r0 = returnFunc(ctx, params, optFns...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sqs.DeleteMessageBatchOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sqs.DeleteMessageBatchInput, ...func(*sqs.Options)) error); ok {
+		r1 = returnFunc(ctx, params, optFns...)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// SQSClient_DeleteMessageBatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMessageBatch'
+type SQSClient_DeleteMessageBatch_Call struct {
+	*mock.Call
+}
+
+// DeleteMessageBatch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params *sqs.DeleteMessageBatchInput
+//   - optFns ...func(*sqs.Options)
+func (_e *SQSClient_Expecter) DeleteMessageBatch(ctx interface{}, params interface{}, optFns ...interface{}) *SQSClient_DeleteMessageBatch_Call {
+	return &SQSClient_DeleteMessageBatch_Call{Call: _e.mock.On("DeleteMessageBatch",
+		append([]interface{}{ctx, params}, optFns...)...)}
+}
+
+func (_c *SQSClient_DeleteMessageBatch_Call) Run(run func(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options))) *SQSClient_DeleteMessageBatch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sqs.DeleteMessageBatchInput
+		if args[1] != nil {
+			arg1 = args[1].(*sqs.DeleteMessageBatchInput)
+		}
+		var arg2 []func(*sqs.Options)
+		var variadicArgs []func(*sqs.Options)
+		if len(args) > 2 {
+			variadicArgs = args[2].([]func(*sqs.Options))
+		}
+		arg2 = variadicArgs
+		run(
+			arg0,
+			arg1,
+			arg2...,
+		)
+	})
+	return _c
+}
+
+func (_c *SQSClient_DeleteMessageBatch_Call) Return(deleteMessageBatchOutput *sqs.DeleteMessageBatchOutput, err error) *SQSClient_DeleteMessageBatch_Call {
+	_c.Call.Return(deleteMessageBatchOutput, err)
+	return _c
+}
+
+func (_c *SQSClient_DeleteMessageBatch_Call) RunAndReturn(run func(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)) *SQSClient_DeleteMessageBatch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetQueueAttributes provides a mock function for the type SQSClient
+func (_mock *SQSClient) GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error) {
+	var tmpRet mock.Arguments
+	if len(optFns) > 0 {
+		tmpRet = _mock.Called(ctx, params, optFns)
+	} else {
+		tmpRet = _mock.Called(ctx, params)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQueueAttributes")
+	}
+
+	var r0 *sqs.GetQueueAttributesOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sqs.GetQueueAttributesInput, ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)); ok {
+		return returnFunc(ctx, params, optFns...)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sqs.GetQueueAttributesInput, ...func(*sqs.Options)) *sqs.GetQueueAttributesOutput); ok {
+		r0 = returnFunc(ctx, params, optFns...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sqs.GetQueueAttributesOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sqs.GetQueueAttributesInput, ...func(*sqs.Options)) error); ok {
+		r1 = returnFunc(ctx, params, optFns...)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// SQSClient_GetQueueAttributes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetQueueAttributes'
+type SQSClient_GetQueueAttributes_Call struct {
+	*mock.Call
+}
+
+// GetQueueAttributes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params *sqs.GetQueueAttributesInput
+//   - optFns ...func(*sqs.Options)
+func (_e *SQSClient_Expecter) GetQueueAttributes(ctx interface{}, params interface{}, optFns ...interface{}) *SQSClient_GetQueueAttributes_Call {
+	return &SQSClient_GetQueueAttributes_Call{Call: _e.mock.On("GetQueueAttributes",
+		append([]interface{}{ctx, params}, optFns...)...)}
+}
+
+func (_c *SQSClient_GetQueueAttributes_Call) Run(run func(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options))) *SQSClient_GetQueueAttributes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sqs.GetQueueAttributesInput
+		if args[1] != nil {
+			arg1 = args[1].(*sqs.GetQueueAttributesInput)
+		}
+		var arg2 []func(*sqs.Options)
+		var variadicArgs []func(*sqs.Options)
+		if len(args) > 2 {
+			variadicArgs = args[2].([]func(*sqs.Options))
+		}
+		arg2 = variadicArgs
+		run(
+			arg0,
+			arg1,
+			arg2...,
+		)
+	})
+	return _c
+}
+
+func (_c *SQSClient_GetQueueAttributes_Call) Return(getQueueAttributesOutput *sqs.GetQueueAttributesOutput, err error) *SQSClient_GetQueueAttributes_Call {
+	_c.Call.Return(getQueueAttributesOutput, err)
+	return _c
+}
+
+func (_c *SQSClient_GetQueueAttributes_Call) RunAndReturn(run func(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)) *SQSClient_GetQueueAttributes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetQueueUrl provides a mock function for the type SQSClient
 func (_mock *SQSClient) GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
 	var tmpRet mock.Arguments
@@ -369,3 +535,169 @@ func (_c *SQSClient_ReceiveMessage_Call) RunAndReturn(run func(ctx context.Conte
 	_c.Call.Return(run)
 	return _c
 }
+
+// SendMessage provides a mock function for the type SQSClient
+func (_mock *SQSClient) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	var tmpRet mock.Arguments
+	if len(optFns) > 0 {
+		tmpRet = _mock.Called(ctx, params, optFns)
+	} else {
+		tmpRet = _mock.Called(ctx, params)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendMessage")
+	}
+
+	var r0 *sqs.SendMessageOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sqs.SendMessageInput, ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)); ok {
+		return returnFunc(ctx, params, optFns...)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sqs.SendMessageInput, ...func(*sqs.Options)) *sqs.SendMessageOutput); ok {
+		r0 = returnFunc(ctx, params, optFns...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sqs.SendMessageOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sqs.SendMessageInput, ...func(*sqs.Options)) error); ok {
+		r1 = returnFunc(ctx, params, optFns...)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// SQSClient_SendMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendMessage'
+type SQSClient_SendMessage_Call struct {
+	*mock.Call
+}
+
+// SendMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params *sqs.SendMessageInput
+//   - optFns ...func(*sqs.Options)
+func (_e *SQSClient_Expecter) SendMessage(ctx interface{}, params interface{}, optFns ...interface{}) *SQSClient_SendMessage_Call {
+	return &SQSClient_SendMessage_Call{Call: _e.mock.On("SendMessage",
+		append([]interface{}{ctx, params}, optFns...)...)}
+}
+
+func (_c *SQSClient_SendMessage_Call) Run(run func(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options))) *SQSClient_SendMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sqs.SendMessageInput
+		if args[1] != nil {
+			arg1 = args[1].(*sqs.SendMessageInput)
+		}
+		var arg2 []func(*sqs.Options)
+		var variadicArgs []func(*sqs.Options)
+		if len(args) > 2 {
+			variadicArgs = args[2].([]func(*sqs.Options))
+		}
+		arg2 = variadicArgs
+		run(
+			arg0,
+			arg1,
+			arg2...,
+		)
+	})
+	return _c
+}
+
+func (_c *SQSClient_SendMessage_Call) Return(sendMessageOutput *sqs.SendMessageOutput, err error) *SQSClient_SendMessage_Call {
+	_c.Call.Return(sendMessageOutput, err)
+	return _c
+}
+
+func (_c *SQSClient_SendMessage_Call) RunAndReturn(run func(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)) *SQSClient_SendMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendMessageBatch provides a mock function for the type SQSClient
+func (_mock *SQSClient) SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	var tmpRet mock.Arguments
+	if len(optFns) > 0 {
+		tmpRet = _mock.Called(ctx, params, optFns)
+	} else {
+		tmpRet = _mock.Called(ctx, params)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendMessageBatch")
+	}
+
+	var r0 *sqs.SendMessageBatchOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sqs.SendMessageBatchInput, ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)); ok {
+		return returnFunc(ctx, params, optFns...)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *sqs.SendMessageBatchInput, ...func(*sqs.Options)) *sqs.SendMessageBatchOutput); ok {
+		r0 = returnFunc(ctx, params, optFns...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sqs.SendMessageBatchOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *sqs.SendMessageBatchInput, ...func(*sqs.Options)) error); ok {
+		r1 = returnFunc(ctx, params, optFns...)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// SQSClient_SendMessageBatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendMessageBatch'
+type SQSClient_SendMessageBatch_Call struct {
+	*mock.Call
+}
+
+// SendMessageBatch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params *sqs.SendMessageBatchInput
+//   - optFns ...func(*sqs.Options)
+func (_e *SQSClient_Expecter) SendMessageBatch(ctx interface{}, params interface{}, optFns ...interface{}) *SQSClient_SendMessageBatch_Call {
+	return &SQSClient_SendMessageBatch_Call{Call: _e.mock.On("SendMessageBatch",
+		append([]interface{}{ctx, params}, optFns...)...)}
+}
+
+func (_c *SQSClient_SendMessageBatch_Call) Run(run func(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options))) *SQSClient_SendMessageBatch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *sqs.SendMessageBatchInput
+		if args[1] != nil {
+			arg1 = args[1].(*sqs.SendMessageBatchInput)
+		}
+		var arg2 []func(*sqs.Options)
+		var variadicArgs []func(*sqs.Options)
+		if len(args) > 2 {
+			variadicArgs = args[2].([]func(*sqs.Options))
+		}
+		arg2 = variadicArgs
+		run(
+			arg0,
+			arg1,
+			arg2...,
+		)
+	})
+	return _c
+}
+
+func (_c *SQSClient_SendMessageBatch_Call) Return(sendMessageBatchOutput *sqs.SendMessageBatchOutput, err error) *SQSClient_SendMessageBatch_Call {
+	_c.Call.Return(sendMessageBatchOutput, err)
+	return _c
+}
+
+func (_c *SQSClient_SendMessageBatch_Call) RunAndReturn(run func(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)) *SQSClient_SendMessageBatch_Call {
+	_c.Call.Return(run)
+	return _c
+}
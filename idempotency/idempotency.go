@@ -0,0 +1,162 @@
+// Package idempotency makes the handlers idempotent, skipping the messages already handled.
+//
+// Standard queues deliver the messages at least once. The Middleware records the key of every
+// message it handles in a DedupStore: a duplicate of a message already handled is skipped and
+// committed, while a duplicate of a message being handled is retried later.
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+const (
+	defaultTTL     = 24 * time.Hour
+	defaultLockTTL = 5 * time.Minute
+)
+
+// State is the state of a message key in a DedupStore
+type State int
+
+const (
+	// StateNew means the key was not recorded, the caller now holds it
+	StateNew State = iota
+	// StateInProgress means the message is being handled
+	StateInProgress
+	// StateCompleted means the message was already handled
+	StateCompleted
+)
+
+// DedupStore records the state of the message keys
+type DedupStore interface {
+	// Claim records key as in progress for ttl, when it is not recorded yet or its record expired,
+	// and returns StateNew. Otherwise, it returns the recorded state.
+	// Claiming must be atomic, so a single caller gets StateNew.
+	Claim(ctx context.Context, key string, ttl time.Duration) (State, error)
+	// Complete records key as completed for ttl
+	Complete(ctx context.Context, key string, ttl time.Duration) error
+	// Release removes the in progress record of key, so the message can be handled again
+	Release(ctx context.Context, key string) error
+}
+
+// KeyFunc derives the idempotency key of a message
+type KeyFunc func(msg loafergo.Message) (string, error)
+
+// KeyByMessageID uses the identifier assigned to the message by SQS.
+// It is the default KeyFunc.
+func KeyByMessageID(msg loafergo.Message) (string, error) {
+	return msg.MessageID(), nil
+}
+
+// KeyBySNSMessageID uses the MessageId of the SNS notification envelope, which is the same
+// for every queue subscribed to the topic, and for the retries of the SNS delivery.
+func KeyBySNSMessageID(msg loafergo.Message) (string, error) {
+	var envelope struct {
+		MessageID string `json:"MessageId"`
+	}
+	if err := json.Unmarshal(msg.Body(), &envelope); err != nil {
+		return "", err
+	}
+	return envelope.MessageID, nil
+}
+
+// KeyByDeduplicationID uses the MessageDeduplicationId set by the producer of a FIFO message
+func KeyByDeduplicationID(msg loafergo.Message) (string, error) {
+	return msg.SystemAttributeByKey("MessageDeduplicationId"), nil
+}
+
+// Config are a discrete set of options that are valid for loading the idempotency Middleware
+type Config struct {
+	key     KeyFunc
+	ttl     time.Duration
+	lockTTL time.Duration
+}
+
+// WithKeyFunc sets how the idempotency key of a message is derived. The default is KeyByMessageID.
+func WithKeyFunc(fn KeyFunc) func(*Config) {
+	return func(c *Config) {
+		c.key = fn
+	}
+}
+
+// WithTTL sets how long a handled message is remembered. The default is 24 hours.
+func WithTTL(d time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.ttl = d
+	}
+}
+
+// WithLockTTL sets how long a message is considered being handled, after which a duplicate can be handled.
+// It should be longer than the time the handler takes. The default is 5 minutes.
+func WithLockTTL(d time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.lockTTL = d
+	}
+}
+
+// Middleware skips the messages whose key is already recorded in store.
+//
+// A message already handled is skipped, the handler returns nil so it is committed.
+// A message being handled by another worker fails with ErrDuplicateInProgress, so it is received
+// again later. When the handler settles the message, with an Ack or a Drop outcome, the key is completed.
+// Otherwise the key is released so the message can be handled again: after a Nack or a RetryAfter outcome,
+// and after a DeadLetter outcome, as the message stays in the queue when the route cannot dead-letter it.
+func Middleware(store DedupStore, optFns ...func(*Config)) loafergo.Middleware {
+	cfg := &Config{
+		key:     KeyByMessageID,
+		ttl:     defaultTTL,
+		lockTTL: defaultLockTTL,
+	}
+	for _, optFn := range optFns {
+		optFn(cfg)
+	}
+
+	return func(next loafergo.Handler) loafergo.Handler {
+		return func(ctx context.Context, msg loafergo.Message) error {
+			key, err := cfg.key(msg)
+			if err == nil && key == "" {
+				err = errors.New("empty idempotency key")
+			}
+			if err != nil {
+				return loafergo.ErrIdempotency.Context(err)
+			}
+
+			state, err := store.Claim(ctx, key, cfg.lockTTL)
+			if err != nil {
+				return loafergo.ErrIdempotency.Context(err)
+			}
+
+			switch state {
+			case StateCompleted:
+				return nil
+			case StateInProgress:
+				return loafergo.ErrDuplicateInProgress
+			}
+
+			handled := false
+			defer func() {
+				if !handled {
+					// next panicked, the redeliveries must not be taken for duplicates in progress
+					_ = store.Release(context.WithoutCancel(ctx), key)
+				}
+			}()
+
+			err = next(ctx, msg)
+			handled = true
+			switch loafergo.OutcomeOf(err).Kind() {
+			case loafergo.OutcomeAck, loafergo.OutcomeDrop:
+				// the message was settled, failing now would handle it again;
+				// when the key cannot be completed, the lock still guards it until it expires
+				_ = store.Complete(context.WithoutCancel(ctx), key, cfg.ttl)
+			default:
+				// the handler error is the one that matters, the lock expires anyway
+				_ = store.Release(context.WithoutCancel(ctx), key)
+			}
+			return err
+		}
+	}
+}
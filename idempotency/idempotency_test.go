@@ -0,0 +1,194 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/fake"
+	"github.com/justcodes/loafer-go/v2/idempotency"
+	idempotencyfake "github.com/justcodes/loafer-go/v2/idempotency/fake"
+)
+
+func countingHandler(calls *int, err error) loafergo.Handler {
+	return func(ctx context.Context, m loafergo.Message) error {
+		*calls++
+		return err
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should handle a message once", func(t *testing.T) {
+		var calls int
+		handler := idempotency.Middleware(idempotency.NewMemoryStore(10))(countingHandler(&calls, nil))
+
+		msg := fake.NewMessage(t)
+		msg.On("MessageID").Return("message-1").Twice()
+
+		assert.NoError(t, handler(ctx, msg))
+		assert.NoError(t, handler(ctx, msg))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Should handle the message again after a failure", func(t *testing.T) {
+		var calls int
+		handlerErr := errors.New("boom")
+		handler := idempotency.Middleware(idempotency.NewMemoryStore(10))(countingHandler(&calls, handlerErr))
+
+		msg := fake.NewMessage(t)
+		msg.On("MessageID").Return("message-1").Twice()
+
+		assert.ErrorIs(t, handler(ctx, msg), handlerErr)
+		assert.ErrorIs(t, handler(ctx, msg), handlerErr)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Should release the key when the handler panics", func(t *testing.T) {
+		var calls int
+		handler := idempotency.Middleware(idempotency.NewMemoryStore(10))(func(ctx context.Context, m loafergo.Message) error {
+			calls++
+			if calls == 1 {
+				panic("boom")
+			}
+			return nil
+		})
+
+		msg := fake.NewMessage(t)
+		msg.On("MessageID").Return("message-1").Twice()
+
+		assert.PanicsWithValue(t, "boom", func() { _ = handler(ctx, msg) })
+		assert.NoError(t, handler(ctx, msg))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Should complete or release the key by outcome", func(t *testing.T) {
+		testCases := []struct {
+			name    string
+			outcome error
+			calls   int
+		}{
+			{name: "Ack", outcome: loafergo.Ack(), calls: 1},
+			{name: "Drop", outcome: loafergo.Drop(), calls: 1},
+			{name: "DeadLetter", outcome: loafergo.DeadLetter("invalid"), calls: 2},
+			{name: "Nack", outcome: loafergo.Nack(errors.New("boom")), calls: 2},
+			{name: "RetryAfter", outcome: loafergo.RetryAfter(time.Minute), calls: 2},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				var calls int
+				handler := idempotency.Middleware(idempotency.NewMemoryStore(10))(countingHandler(&calls, tc.outcome))
+
+				msg := fake.NewMessage(t)
+				msg.On("MessageID").Return("message-1").Twice()
+
+				assert.ErrorIs(t, handler(ctx, msg), tc.outcome)
+				// a redelivered duplicate is skipped once the message was acked or dropped, and handled again otherwise
+				err := handler(ctx, msg)
+				assert.Equal(t, tc.calls, calls)
+				if tc.calls > 1 {
+					assert.ErrorIs(t, err, tc.outcome)
+				} else {
+					assert.NoError(t, err)
+				}
+			})
+		}
+	})
+
+	t.Run("Should fail a duplicate in progress", func(t *testing.T) {
+		store := idempotency.NewMemoryStore(10)
+		var calls int
+		handler := idempotency.Middleware(store)(countingHandler(&calls, nil))
+
+		msg := fake.NewMessage(t)
+		msg.On("MessageID").Return("message-1").Once()
+
+		state, err := store.Claim(ctx, "message-1", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, idempotency.StateNew, state)
+
+		assert.ErrorIs(t, handler(ctx, msg), loafergo.ErrDuplicateInProgress)
+		assert.Zero(t, calls)
+	})
+
+	t.Run("Should handle a duplicate once the lock expired", func(t *testing.T) {
+		store := idempotency.NewMemoryStore(10)
+		var calls int
+		handler := idempotency.Middleware(store, idempotency.WithLockTTL(time.Millisecond))(countingHandler(&calls, nil))
+
+		msg := fake.NewMessage(t)
+		msg.On("MessageID").Return("message-1").Once()
+
+		_, err := store.Claim(ctx, "message-1", time.Millisecond)
+		assert.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+
+		assert.NoError(t, handler(ctx, msg))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Should use the key function and TTL", func(t *testing.T) {
+		store := idempotencyfake.NewDedupStore(t)
+		store.On("Claim", mock.Anything, "dedup-1", time.Minute).Return(idempotency.StateNew, nil).Once()
+		store.On("Complete", mock.Anything, "dedup-1", time.Hour).Return(errors.New("unavailable")).Once()
+
+		var calls int
+		handler := idempotency.Middleware(store,
+			idempotency.WithKeyFunc(idempotency.KeyByDeduplicationID),
+			idempotency.WithTTL(time.Hour),
+			idempotency.WithLockTTL(time.Minute),
+		)(countingHandler(&calls, nil))
+
+		msg := fake.NewMessage(t)
+		msg.On("SystemAttributeByKey", "MessageDeduplicationId").Return("dedup-1").Once()
+
+		assert.NoError(t, handler(ctx, msg))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Should fail when the key cannot be derived", func(t *testing.T) {
+		var calls int
+		handler := idempotency.Middleware(idempotencyfake.NewDedupStore(t))(countingHandler(&calls, nil))
+
+		msg := fake.NewMessage(t)
+		msg.On("MessageID").Return("").Once()
+
+		assert.ErrorIs(t, handler(ctx, msg), loafergo.ErrIdempotency)
+		assert.Zero(t, calls)
+	})
+
+	t.Run("Should fail when the store fails", func(t *testing.T) {
+		store := idempotencyfake.NewDedupStore(t)
+		store.On("Claim", mock.Anything, "message-1", mock.Anything).Return(idempotency.StateNew, errors.New("unavailable")).Once()
+
+		var calls int
+		handler := idempotency.Middleware(store)(countingHandler(&calls, nil))
+
+		msg := fake.NewMessage(t)
+		msg.On("MessageID").Return("message-1").Once()
+
+		assert.ErrorIs(t, handler(ctx, msg), loafergo.ErrIdempotency)
+		assert.Zero(t, calls)
+	})
+}
+
+func TestKeyBySNSMessageID(t *testing.T) {
+	msg := fake.NewMessage(t)
+	msg.On("Body").Return([]byte(`{"MessageId":"sns-1","Message":"{}"}`)).Once()
+
+	key, err := idempotency.KeyBySNSMessageID(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "sns-1", key)
+
+	msg = fake.NewMessage(t)
+	msg.On("Body").Return([]byte(`not json`)).Once()
+
+	_, err = idempotency.KeyBySNSMessageID(msg)
+	assert.Error(t, err)
+}
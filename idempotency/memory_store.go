@@ -0,0 +1,102 @@
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultMemoryCapacity = 10000
+
+type memoryEntry struct {
+	expiresAt time.Time
+	key       string
+	state     State
+}
+
+// MemoryStore is a DedupStore keeping the keys in memory, evicting the least recently used
+// when full. It only deduplicates the messages handled by the same process.
+type MemoryStore struct {
+	entries  map[string]*list.Element
+	lru      *list.List
+	now      func() time.Time
+	capacity int
+	mu       sync.Mutex
+}
+
+// NewMemoryStore creates a MemoryStore holding up to capacity keys, 10000 when lower than 1
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity < 1 {
+		capacity = defaultMemoryCapacity
+	}
+	return &MemoryStore{
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		now:      time.Now,
+		capacity: capacity,
+	}
+}
+
+// Claim records key as in progress, unless it is already recorded
+func (s *MemoryStore) Claim(_ context.Context, key string, ttl time.Duration) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if el, ok := s.entries[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		if now.Before(entry.expiresAt) {
+			s.lru.MoveToFront(el)
+			return entry.state, nil
+		}
+	}
+
+	s.set(key, StateInProgress, now.Add(ttl))
+	return StateNew, nil
+}
+
+// Complete records key as completed
+func (s *MemoryStore) Complete(_ context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, StateCompleted, s.now().Add(ttl))
+	return nil
+}
+
+// Release removes key, when it is in progress
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.entries[key]; ok && el.Value.(*memoryEntry).state == StateInProgress {
+		s.lru.Remove(el)
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// Len returns the number of keys held, expired ones included
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lru.Len()
+}
+
+func (s *MemoryStore) set(key string, state State, expiresAt time.Time) {
+	if el, ok := s.entries[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.state, entry.expiresAt = state, expiresAt
+		s.lru.MoveToFront(el)
+		return
+	}
+
+	s.entries[key] = s.lru.PushFront(&memoryEntry{key: key, state: state, expiresAt: expiresAt})
+	for s.lru.Len() > s.capacity {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
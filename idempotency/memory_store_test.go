@@ -0,0 +1,66 @@
+package idempotency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/justcodes/loafer-go/v2/idempotency"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should record the state of a key", func(t *testing.T) {
+		store := idempotency.NewMemoryStore(10)
+
+		state, _ := store.Claim(ctx, "a", time.Minute)
+		assert.Equal(t, idempotency.StateNew, state)
+		state, _ = store.Claim(ctx, "a", time.Minute)
+		assert.Equal(t, idempotency.StateInProgress, state)
+
+		assert.NoError(t, store.Complete(ctx, "a", time.Minute))
+		state, _ = store.Claim(ctx, "a", time.Minute)
+		assert.Equal(t, idempotency.StateCompleted, state)
+
+		// a completed key is not released
+		assert.NoError(t, store.Release(ctx, "a"))
+		state, _ = store.Claim(ctx, "a", time.Minute)
+		assert.Equal(t, idempotency.StateCompleted, state)
+	})
+
+	t.Run("Should release a key in progress", func(t *testing.T) {
+		store := idempotency.NewMemoryStore(10)
+
+		_, _ = store.Claim(ctx, "a", time.Minute)
+		assert.NoError(t, store.Release(ctx, "a"))
+		state, _ := store.Claim(ctx, "a", time.Minute)
+		assert.Equal(t, idempotency.StateNew, state)
+	})
+
+	t.Run("Should expire a key", func(t *testing.T) {
+		store := idempotency.NewMemoryStore(10)
+
+		assert.NoError(t, store.Complete(ctx, "a", time.Millisecond))
+		time.Sleep(5 * time.Millisecond)
+		state, _ := store.Claim(ctx, "a", time.Minute)
+		assert.Equal(t, idempotency.StateNew, state)
+	})
+
+	t.Run("Should evict the least recently used key", func(t *testing.T) {
+		store := idempotency.NewMemoryStore(2)
+
+		_ = store.Complete(ctx, "a", time.Minute)
+		_ = store.Complete(ctx, "b", time.Minute)
+		_, _ = store.Claim(ctx, "a", time.Minute)
+		_ = store.Complete(ctx, "c", time.Minute)
+		assert.Equal(t, 2, store.Len())
+
+		state, _ := store.Claim(ctx, "a", time.Minute)
+		assert.Equal(t, idempotency.StateCompleted, state)
+		state, _ = store.Claim(ctx, "b", time.Minute)
+		assert.Equal(t, idempotency.StateNew, state)
+	})
+}
@@ -0,0 +1,160 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const defaultTable = "loafer_dedup"
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLConfig are a discrete set of options that are valid for loading the SQLStore
+type SQLConfig struct {
+	table              string
+	dollarPlaceholders bool
+}
+
+// SQLWithTable sets the table holding the keys. The default is loafer_dedup.
+func SQLWithTable(name string) func(*SQLConfig) {
+	return func(c *SQLConfig) {
+		c.table = name
+	}
+}
+
+// SQLWithDollarPlaceholders uses the $1, $2 placeholders, as required by PostgreSQL,
+// instead of the ? ones used by SQLite.
+func SQLWithDollarPlaceholders() func(*SQLConfig) {
+	return func(c *SQLConfig) {
+		c.dollarPlaceholders = true
+	}
+}
+
+// SQLStore is a DedupStore keeping the keys in a database/sql table,
+// so the messages are deduplicated across the consumer instances.
+//
+// The database must support INSERT ... ON CONFLICT DO NOTHING, e.g. SQLite or PostgreSQL;
+// MySQL, which has no ON CONFLICT clause, is not supported.
+// The table is created with CreateTable, the expired keys are removed with DeleteExpired.
+type SQLStore struct {
+	db        *sql.DB
+	now       func() time.Time
+	claim     string
+	selectKey string
+	expire    string
+	complete  string
+	release   string
+	cleanup   string
+	create    string
+}
+
+// NewSQLStore creates a SQLStore using db
+func NewSQLStore(db *sql.DB, optFns ...func(*SQLConfig)) (*SQLStore, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	cfg := &SQLConfig{table: defaultTable}
+	for _, optFn := range optFns {
+		optFn(cfg)
+	}
+	if !tableName.MatchString(cfg.table) {
+		return nil, fmt.Errorf("invalid table name: %q", cfg.table)
+	}
+
+	q := func(query string) string {
+		query = strings.ReplaceAll(query, "{table}", cfg.table)
+		if !cfg.dollarPlaceholders {
+			return query
+		}
+		var b strings.Builder
+		n := 0
+		for _, r := range query {
+			if r == '?' {
+				n++
+				fmt.Fprintf(&b, "$%d", n)
+				continue
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+
+	return &SQLStore{
+		db:  db,
+		now: time.Now,
+		create: q(`CREATE TABLE IF NOT EXISTS {table} (
+	dedup_key VARCHAR(512) PRIMARY KEY,
+	state INTEGER NOT NULL,
+	expires_at BIGINT NOT NULL
+)`),
+		expire:    q(`DELETE FROM {table} WHERE dedup_key = ? AND expires_at <= ?`),
+		claim:     q(`INSERT INTO {table} (dedup_key, state, expires_at) VALUES (?, ?, ?) ON CONFLICT (dedup_key) DO NOTHING`),
+		selectKey: q(`SELECT state FROM {table} WHERE dedup_key = ?`),
+		complete: q(`INSERT INTO {table} (dedup_key, state, expires_at) VALUES (?, ?, ?) ` +
+			`ON CONFLICT (dedup_key) DO UPDATE SET state = excluded.state, expires_at = excluded.expires_at`),
+		release: q(`DELETE FROM {table} WHERE dedup_key = ? AND state = ?`),
+		cleanup: q(`DELETE FROM {table} WHERE expires_at <= ?`),
+	}, nil
+}
+
+// CreateTable creates the table holding the keys, when it does not exist
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, s.create)
+	return err
+}
+
+// Claim records key as in progress, unless it is already recorded.
+// The primary key guarantees a single caller inserts it.
+func (s *SQLStore) Claim(ctx context.Context, key string, ttl time.Duration) (State, error) {
+	now := s.now()
+	if _, err := s.db.ExecContext(ctx, s.expire, key, now.UnixMilli()); err != nil {
+		return 0, err
+	}
+
+	res, err := s.db.ExecContext(ctx, s.claim, key, StateInProgress, now.Add(ttl).UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		return StateNew, nil
+	}
+
+	var state State
+	err = s.db.QueryRowContext(ctx, s.selectKey, key).Scan(&state)
+	if errors.Is(err, sql.ErrNoRows) {
+		// released in the meantime, the message is retried later
+		return StateInProgress, nil
+	}
+	return state, err
+}
+
+// Complete records key as completed
+func (s *SQLStore) Complete(ctx context.Context, key string, ttl time.Duration) error {
+	_, err := s.db.ExecContext(ctx, s.complete, key, StateCompleted, s.now().Add(ttl).UnixMilli())
+	return err
+}
+
+// Release removes key, when it is in progress
+func (s *SQLStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, s.release, key, StateInProgress)
+	return err
+}
+
+// DeleteExpired removes the expired keys and returns how many were removed
+func (s *SQLStore) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.cleanup, s.now().UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,112 @@
+package idempotency_test
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/justcodes/loafer-go/v2/idempotency"
+)
+
+func newSQLStore(t *testing.T) *idempotency.SQLStore {
+	db, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	store, err := idempotency.NewSQLStore(db, idempotency.SQLWithTable("dedup"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, store.CreateTable(context.Background())) {
+		t.FailNow()
+	}
+	return store
+}
+
+func TestNewSQLStore(t *testing.T) {
+	_, err := idempotency.NewSQLStore(nil)
+	assert.Error(t, err)
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	_, err = idempotency.NewSQLStore(db, idempotency.SQLWithTable("dedup; DROP TABLE users"))
+	assert.Error(t, err)
+}
+
+func TestSQLStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should record the state of a key", func(t *testing.T) {
+		store := newSQLStore(t)
+
+		state, err := store.Claim(ctx, "a", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, idempotency.StateNew, state)
+		state, err = store.Claim(ctx, "a", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, idempotency.StateInProgress, state)
+
+		assert.NoError(t, store.Complete(ctx, "a", time.Minute))
+		state, err = store.Claim(ctx, "a", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, idempotency.StateCompleted, state)
+
+		assert.NoError(t, store.Release(ctx, "a"))
+		state, _ = store.Claim(ctx, "a", time.Minute)
+		assert.Equal(t, idempotency.StateCompleted, state)
+	})
+
+	t.Run("Should release a key in progress", func(t *testing.T) {
+		store := newSQLStore(t)
+
+		_, _ = store.Claim(ctx, "a", time.Minute)
+		assert.NoError(t, store.Release(ctx, "a"))
+		state, _ := store.Claim(ctx, "a", time.Minute)
+		assert.Equal(t, idempotency.StateNew, state)
+	})
+
+	t.Run("Should expire a key", func(t *testing.T) {
+		store := newSQLStore(t)
+
+		assert.NoError(t, store.Complete(ctx, "a", time.Millisecond))
+		assert.NoError(t, store.Complete(ctx, "b", time.Minute))
+		time.Sleep(5 * time.Millisecond)
+
+		state, _ := store.Claim(ctx, "a", time.Millisecond)
+		assert.Equal(t, idempotency.StateNew, state)
+		time.Sleep(5 * time.Millisecond)
+
+		n, err := store.DeleteExpired(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("Should let a single caller claim a key", func(t *testing.T) {
+		store := newSQLStore(t)
+
+		var mu sync.Mutex
+		var claimed int
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Go(func() {
+				state, err := store.Claim(ctx, "a", time.Minute)
+				if err == nil && state == idempotency.StateNew {
+					mu.Lock()
+					claimed++
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+		assert.Equal(t, 1, claimed)
+	})
+}
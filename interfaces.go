@@ -20,6 +20,55 @@ type Router interface {
 	CustomGroupFields(ctx context.Context) []string
 }
 
+// NamedRouter is optionally implemented by a Router to expose the name of the queue it consumes.
+// The Manager uses it to identify the route in logs.
+type NamedRouter interface {
+	Name(ctx context.Context) string
+}
+
+// BatchRouter is optionally implemented by a Router able to handle all the messages
+// of a receive call at once. When BatchMode returns true, the Manager calls HandlerBatch
+// instead of HandlerMessage and commits the messages that did not fail individually.
+type BatchRouter interface {
+	BatchMode(ctx context.Context) bool
+	HandlerBatch(ctx context.Context, msgs []Message) BatchResult
+}
+
+// DeadLetterRouter is optionally implemented by a Router able to dead-letter a message on demand.
+// The Manager uses it for the messages settled with a DeadLetter outcome, and commits them once dead-lettered.
+type DeadLetterRouter interface {
+	DeadLetter(ctx context.Context, msg Message, reason string) error
+}
+
+// BoundedRouter is optionally implemented by a Router able to receive fewer messages than its maximum.
+// The Manager uses it to only receive as many messages as the route has free workers for.
+type BoundedRouter interface {
+	GetMessagesUpTo(ctx context.Context, logger Logger, maxMessages int32) ([]Message, error)
+}
+
+// PickUpRouter is optionally implemented by a Router tracking when its messages start being handled.
+// The Manager calls PickUp from the worker, right before handling the message, so the route can start
+// the handling time of a message, e.g. its visibility extension limit, only once a worker is actually available for it.
+type PickUpRouter interface {
+	PickUp(ctx context.Context, msg Message)
+}
+
+// FlushRouter is optionally implemented by a Router buffering its commits.
+// The Manager calls Flush once the workers of the route stopped, so the messages they committed
+// are deleted before Run returns.
+type FlushRouter interface {
+	Flush(ctx context.Context)
+}
+
+// ScalingRouter is optionally implemented by a Router whose worker pool grows and shrinks with its load.
+// When ScalingPolicy returns a policy, the Manager starts the route with WorkerPoolSize workers within
+// the policy bounds, and resizes the pool following the policy, Backlog and the utilization of the workers.
+type ScalingRouter interface {
+	ScalingPolicy(ctx context.Context) *ScalingPolicy
+	// Backlog returns the number of messages waiting in the queue
+	Backlog(ctx context.Context) (int, error)
+}
+
 // SQSClient represents the aws sqs client methods
 type SQSClient interface {
 	ChangeMessageVisibility(
@@ -27,18 +76,40 @@ type SQSClient interface {
 		params *sqs.ChangeMessageVisibilityInput,
 		optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
 	GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
+	GetQueueAttributes(
+		ctx context.Context,
+		params *sqs.GetQueueAttributesInput,
+		optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
 	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	DeleteMessageBatch(
+		ctx context.Context,
+		params *sqs.DeleteMessageBatchInput,
+		optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	SendMessageBatch(
+		ctx context.Context,
+		params *sqs.SendMessageBatchInput,
+		optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
 }
 
 // Message represents the message interface methods
 type Message interface {
-	// Decode will unmarshal the body message into a supplied output using JSON
+	// Decode will unmarshal the body message into a supplied output,
+	// using the codec named by its content-type attribute, JSON by default
 	Decode(out interface{}) error
 	// Attribute will return the custom attribute sent throughout the request.
+	// It looks up the SNS envelope attributes first, then the native queue attributes.
 	Attribute(key string) string
-	// Attributes will return the custom attributes sent with the request.
+	// Attributes will return the custom attributes sent with the request,
+	// merging the native queue attributes with the SNS envelope ones, the latter taking precedence.
+	// Binary values are base64 encoded.
 	Attributes() map[string]string
+	// NativeAttributes will return the typed attributes set on the queue message itself,
+	// by a direct producer or a raw message delivery subscription.
+	NativeAttributes() map[string]MessageAttribute
+	// EnvelopeAttributes will return the typed attributes parsed from the SNS notification envelope.
+	EnvelopeAttributes() map[string]MessageAttribute
 	// SystemAttributeByKey will return the system attributes by key.
 	SystemAttributeByKey(key string) string
 	// SystemAttributes will return the system attributes.
@@ -47,6 +118,8 @@ type Message interface {
 	Metadata() map[string]string
 	// Identifier will return an identifier associated with the message ReceiptHandle.
 	Identifier() string
+	// MessageID will return the identifier assigned to the message by the queue.
+	MessageID() string
 	// Dispatch used to dispatch a message if necessary
 	Dispatch()
 	// Backoff used to change the visibilityTimeout of the message
@@ -61,10 +134,26 @@ type Message interface {
 	Message() string
 	// TimeStamp returns the message timestamp
 	TimeStamp() time.Time
-	// DecodeMessage will unmarshal the message into a supplied output using JSON
+	// DecodeMessage will unmarshal the message into a supplied output,
+	// using the codec named by its content-type attribute, JSON by default
 	DecodeMessage(out any) error
 }
 
+// BlobStore stores the payloads too large to be sent in a message, see the claimcheck package
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Codec marshals the message payloads, see the codec package
+type Codec interface {
+	// ContentType is sent as the content-type message attribute, so the consumers pick the matching codec
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
 // SNSClient represents the aws sns client methods
 type SNSClient interface {
 	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
@@ -4,3 +4,61 @@ import "context"
 
 // Handler represents the handler function
 type Handler func(context.Context, Message) error
+
+// Middleware wraps a Handler to add behavior before and after it handles a message
+type Middleware func(Handler) Handler
+
+// Chain composes the middlewares into a single Middleware.
+// The first middleware is the outermost one, so it is the first to see the message.
+//
+// Example:
+//
+//	handler := loafergo.Chain(
+//		loafergo.RecoverMiddleware(),
+//		loafergo.TimeoutMiddleware(10*time.Second),
+//	)(myHandler)
+func Chain(mws ...Middleware) Middleware {
+	return func(h Handler) Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			h = mws[i](h)
+		}
+		return h
+	}
+}
+
+// BatchHandler handles all the messages received by a single receive call at once.
+// It reports the outcome of each message in the returned BatchResult.
+type BatchHandler func(context.Context, []Message) BatchResult
+
+// BatchResult reports the messages a BatchHandler failed to handle, keyed by Message.Identifier.
+// Messages missing from the result, and all of them when the result is nil, are handled successfully.
+//
+// Example:
+//
+//	result := loafergo.BatchResult{}
+//	for _, m := range msgs {
+//		if err := store(ctx, m); err != nil {
+//			result.Fail(m, err)
+//		}
+//	}
+//	return result
+type BatchResult map[string]error
+
+// Fail marks the message as failed with err.
+func (r BatchResult) Fail(m Message, err error) {
+	r[m.Identifier()] = err
+}
+
+// Err returns the error the message failed with, or nil when it was handled successfully.
+func (r BatchResult) Err(m Message) error {
+	return r[m.Identifier()]
+}
+
+// FailAll returns a BatchResult marking every message as failed with err.
+func FailAll(msgs []Message, err error) BatchResult {
+	result := make(BatchResult, len(msgs))
+	for _, m := range msgs {
+		result.Fail(m, err)
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package loafergo_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+func TestBatchResult(t *testing.T) {
+	failed := new(fake.Message)
+	failed.On("Identifier").Return("id-1")
+	handled := new(fake.Message)
+	handled.On("Identifier").Return("id-2")
+
+	errFailed := errors.New("failed")
+	result := loafergo.BatchResult{}
+	result.Fail(failed, errFailed)
+
+	assert.Equal(t, errFailed, result.Err(failed))
+	assert.NoError(t, result.Err(handled))
+
+	var empty loafergo.BatchResult
+	assert.NoError(t, empty.Err(failed), "a nil result marks every message as handled")
+}
+
+func TestFailAll(t *testing.T) {
+	m1 := new(fake.Message)
+	m1.On("Identifier").Return("id-1")
+	m2 := new(fake.Message)
+	m2.On("Identifier").Return("id-2")
+
+	errFailed := errors.New("failed")
+	result := loafergo.FailAll([]loafergo.Message{m1, m2}, errFailed)
+
+	assert.Equal(t, loafergo.BatchResult{"id-1": errFailed, "id-2": errFailed}, result)
+}
@@ -0,0 +1,209 @@
+// Package loafertest provides an in-process SNS and SQS broker for the tests.
+//
+// The Broker implements loafergo.SQSClient and loafergo.SNSClient with the semantics of the
+// AWS services: topics fanning out to the subscribed queues, raw or enveloped deliveries
+// filtered by the subscription filter policy, visibility timeouts, receipt handles, receive
+// counts, FIFO ordering and deduplication, long polling, redrive to a dead-letter queue and the
+// approximate message counts of GetQueueAttributes.
+// The Manager, sqs.NewRoute and sns.NewProducer run against it without LocalStack:
+//
+//	b := loafertest.NewBroker()
+//	queueURL := b.CreateQueue("orders")
+//	topicARN := b.CreateTopic("events")
+//	_ = b.Subscribe(topicARN, queueURL)
+//
+//	producer, _ := sns.NewProducer(&sns.Config{SNSClient: b})
+//	route := sqs.NewRoute(&sqs.Config{SQSClient: b, Handler: handler, QueueName: "orders"})
+//
+// The broker clock is the wall clock, Advance moves it forward to expire the visibility
+// timeouts, delays and deduplication windows without sleeping.
+//
+// RunRoute and RunHandler feed messages built with NewMessage to a route or a handler through
+// a Manager, and report what happened to each of them: committed, backed off, left for a retry
+// or dead-lettered.
+package loafertest
+
+import (
+	"crypto/md5" //nolint:gosec // SQS digests the bodies with MD5
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	defaultRegion            = "us-east-1"
+	defaultAccountID         = "000000000000"
+	defaultVisibilityTimeout = 30 * time.Second
+	// deduplicationInterval is the window in which the FIFO queues and topics drop the duplicates
+	deduplicationInterval = 5 * time.Minute
+	fifoSuffix            = ".fifo"
+)
+
+// Config are a discrete set of options that are valid for loading the broker configuration
+type Config struct {
+	region    string
+	accountID string
+}
+
+// LoadConfigFunc is a type alias for Config functional config
+type LoadConfigFunc func(config *Config)
+
+// WithRegion sets the region of the queue URLs and the topic ARNs.
+// The default is us-east-1.
+func WithRegion(region string) LoadConfigFunc {
+	return func(c *Config) {
+		c.region = region
+	}
+}
+
+// WithAccountID sets the account of the queue URLs and the topic ARNs.
+// The default is 000000000000.
+func WithAccountID(id string) LoadConfigFunc {
+	return func(c *Config) {
+		c.accountID = id
+	}
+}
+
+// Broker is an in-process SNS and SQS broker, safe for concurrent use.
+// It implements loafergo.SQSClient and loafergo.SNSClient.
+type Broker struct {
+	mu        sync.Mutex
+	region    string
+	accountID string
+	offset    time.Duration
+	sequence  uint64
+	// changed is closed and replaced each time a message may have become visible
+	changed chan struct{}
+	queues  map[string]*queue
+	topics  map[string]*topic
+}
+
+// NewBroker creates an empty broker
+func NewBroker(optFns ...LoadConfigFunc) *Broker {
+	cfg := &Config{region: defaultRegion, accountID: defaultAccountID}
+	for _, optFn := range optFns {
+		optFn(cfg)
+	}
+
+	return &Broker{
+		region:    cfg.region,
+		accountID: cfg.accountID,
+		changed:   make(chan struct{}),
+		queues:    make(map[string]*queue),
+		topics:    make(map[string]*topic),
+	}
+}
+
+// Advance moves the broker clock forward by d, expiring the visibility timeouts,
+// delays and deduplication windows that end within it.
+func (b *Broker) Advance(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.offset += d
+	b.notify()
+}
+
+// Len returns the number of messages stored in the queue, visible, delayed or in flight.
+// It returns zero for an unknown queue.
+func (b *Broker) Len(queueName string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return 0
+	}
+	return len(q.messages)
+}
+
+// InFlight returns the number of messages of the queue received and not yet visible again.
+// It returns zero for an unknown queue.
+func (b *Broker) InFlight(queueName string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return 0
+	}
+
+	now := b.now()
+	var n int
+	for _, m := range q.messages {
+		if m.inFlight(now) {
+			n++
+		}
+	}
+	return n
+}
+
+// Bodies returns the bodies of the messages stored in the queue, in order, without receiving them.
+// It returns nil for an unknown queue.
+func (b *Broker) Bodies(queueName string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return nil
+	}
+
+	bodies := make([]string, len(q.messages))
+	for i, m := range q.messages {
+		bodies[i] = m.body
+	}
+	return bodies
+}
+
+// now returns the broker clock, b.mu must be held
+func (b *Broker) now() time.Time {
+	return time.Now().Add(b.offset)
+}
+
+// notify wakes up the long polling receivers, b.mu must be held
+func (b *Broker) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// nextSequenceNumber returns the next FIFO sequence number, b.mu must be held
+func (b *Broker) nextSequenceNumber() string {
+	b.sequence++
+	return fmt.Sprintf("%020d", b.sequence)
+}
+
+// queueByURL returns the queue addressed by url, b.mu must be held
+func (b *Broker) queueByURL(url *string) (*queue, error) {
+	if url != nil {
+		if i := strings.LastIndex(*url, "/"); i >= 0 {
+			if q, ok := b.queues[(*url)[i+1:]]; ok && q.url == *url {
+				return q, nil
+			}
+		}
+	}
+	return nil, &types.QueueDoesNotExist{Message: stringPtr("The specified queue does not exist.")}
+}
+
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s)) //nolint:gosec // SQS digests the bodies with MD5
+	return hex.EncodeToString(sum[:])
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,271 @@
+package loafertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// filterPolicy is a subscription filter policy on the message attributes.
+// A message matches when, for every attribute of the policy, one of its conditions matches.
+type filterPolicy map[string][]condition
+
+type conditionKind int
+
+const (
+	conditionString conditionKind = iota
+	conditionNumber
+	conditionPrefix
+	conditionSuffix
+	conditionEqualsIgnoreCase
+	conditionAnythingBut
+	conditionExists
+	conditionNumeric
+)
+
+// condition is a filter policy condition: an exact string or number, or an operator object
+type condition struct {
+	kind   conditionKind
+	str    string
+	num    float64
+	exists bool
+	bounds []bound
+	// but holds the conditions an anything-but condition rejects
+	but []condition
+}
+
+type bound struct {
+	op    string
+	value float64
+}
+
+// attributeValue is a message attribute value as the filter policies compare it.
+// A String.Array attribute matches when one of its elements matches.
+type attributeValue struct {
+	str      string
+	num      float64
+	isNumber bool
+}
+
+// parseFilterPolicy parses the JSON filter policy of a subscription, supporting the exact
+// strings and numbers, prefix, suffix, equals-ignore-case, anything-but, exists and numeric operators.
+func parseFilterPolicy(policy string) (filterPolicy, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(policy), &raw); err != nil {
+		return nil, fmt.Errorf("invalid filter policy: %w", err)
+	}
+
+	p := make(filterPolicy, len(raw))
+	for attribute, value := range raw {
+		var values []any
+		if err := json.Unmarshal(value, &values); err != nil {
+			return nil, fmt.Errorf("invalid filter policy: the conditions of %q must be an array", attribute)
+		}
+
+		for _, v := range values {
+			c, err := parseCondition(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter policy: attribute %q: %w", attribute, err)
+			}
+			p[attribute] = append(p[attribute], c)
+		}
+	}
+	return p, nil
+}
+
+func parseCondition(v any) (condition, error) {
+	switch v := v.(type) {
+	case string:
+		return condition{kind: conditionString, str: v}, nil
+	case float64:
+		return condition{kind: conditionNumber, num: v}, nil
+	case map[string]any:
+		if len(v) != 1 {
+			return condition{}, fmt.Errorf("an operator object must have a single key, got %d", len(v))
+		}
+		for op, arg := range v {
+			return parseOperator(op, arg)
+		}
+	}
+	return condition{}, fmt.Errorf("unsupported condition %v", v)
+}
+
+func parseOperator(op string, arg any) (condition, error) {
+	switch op {
+	case "prefix", "suffix", "equals-ignore-case":
+		s, ok := arg.(string)
+		if !ok {
+			return condition{}, fmt.Errorf("%s needs a string", op)
+		}
+		kind := map[string]conditionKind{
+			"prefix":             conditionPrefix,
+			"suffix":             conditionSuffix,
+			"equals-ignore-case": conditionEqualsIgnoreCase,
+		}[op]
+		return condition{kind: kind, str: s}, nil
+	case "exists":
+		b, ok := arg.(bool)
+		if !ok {
+			return condition{}, fmt.Errorf("exists needs a boolean")
+		}
+		return condition{kind: conditionExists, exists: b}, nil
+	case "numeric":
+		return parseNumeric(arg)
+	case "anything-but":
+		c := condition{kind: conditionAnythingBut}
+		values, ok := arg.([]any)
+		if !ok {
+			values = []any{arg}
+		}
+		for _, v := range values {
+			but, err := parseCondition(v)
+			if err != nil {
+				return condition{}, err
+			}
+			if but.kind != conditionString && but.kind != conditionNumber && but.kind != conditionPrefix {
+				return condition{}, fmt.Errorf("anything-but supports strings, numbers and a prefix")
+			}
+			c.but = append(c.but, but)
+		}
+		return c, nil
+	}
+	return condition{}, fmt.Errorf("unsupported operator %q", op)
+}
+
+func parseNumeric(arg any) (condition, error) {
+	values, ok := arg.([]any)
+	if !ok || len(values) == 0 || len(values)%2 != 0 || len(values) > 4 {
+		return condition{}, fmt.Errorf("numeric needs one or two operator and number pairs")
+	}
+
+	c := condition{kind: conditionNumeric}
+	for i := 0; i < len(values); i += 2 {
+		op, ok := values[i].(string)
+		if !ok || (op != "=" && op != "<" && op != "<=" && op != ">" && op != ">=") {
+			return condition{}, fmt.Errorf("unsupported numeric operator %v", values[i])
+		}
+		n, ok := values[i+1].(float64)
+		if !ok {
+			return condition{}, fmt.Errorf("numeric operator %s needs a number", op)
+		}
+		c.bounds = append(c.bounds, bound{op: op, value: n})
+	}
+	return c, nil
+}
+
+// match reports whether the message attributes match the policy, a nil policy matches all of them
+func (p filterPolicy) match(attributes map[string]types.MessageAttributeValue) bool {
+	for name, conditions := range p {
+		values, present := attributeValues(attributes, name)
+
+		matched := false
+		for _, c := range conditions {
+			if c.kind == conditionExists {
+				matched = c.exists == present
+			} else {
+				for _, v := range values {
+					if c.match(v) {
+						matched = true
+						break
+					}
+				}
+			}
+			if matched {
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) match(v attributeValue) bool {
+	switch c.kind {
+	case conditionString:
+		return !v.isNumber && v.str == c.str
+	case conditionNumber:
+		return v.isNumber && v.num == c.num
+	case conditionPrefix:
+		return !v.isNumber && strings.HasPrefix(v.str, c.str)
+	case conditionSuffix:
+		return !v.isNumber && strings.HasSuffix(v.str, c.str)
+	case conditionEqualsIgnoreCase:
+		return !v.isNumber && strings.EqualFold(v.str, c.str)
+	case conditionNumeric:
+		if !v.isNumber {
+			return false
+		}
+		for _, b := range c.bounds {
+			if !b.match(v.num) {
+				return false
+			}
+		}
+		return true
+	case conditionAnythingBut:
+		for _, but := range c.but {
+			if but.match(v) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (b bound) match(n float64) bool {
+	switch b.op {
+	case "=":
+		return n == b.value
+	case "<":
+		return n < b.value
+	case "<=":
+		return n <= b.value
+	case ">":
+		return n > b.value
+	default:
+		return n >= b.value
+	}
+}
+
+// attributeValues returns the values of the attribute the conditions compare, and whether it is present.
+// Binary attributes are present but never match a value condition.
+func attributeValues(attributes map[string]types.MessageAttributeValue, name string) ([]attributeValue, bool) {
+	a, ok := attributes[name]
+	if !ok {
+		return nil, false
+	}
+
+	value := deref(a.StringValue)
+	switch dataType := deref(a.DataType); {
+	case strings.HasPrefix(dataType, "Number"):
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, true
+		}
+		return []attributeValue{{num: n, isNumber: true}}, true
+	case dataType == "String.Array":
+		var elements []any
+		if err := json.Unmarshal([]byte(value), &elements); err != nil {
+			return nil, true
+		}
+		values := make([]attributeValue, 0, len(elements))
+		for _, e := range elements {
+			switch e := e.(type) {
+			case string:
+				values = append(values, attributeValue{str: e})
+			case float64:
+				values = append(values, attributeValue{num: e, isNumber: true})
+			}
+		}
+		return values, true
+	case strings.HasPrefix(dataType, "String"):
+		return []attributeValue{{str: value}}, true
+	}
+	return nil, true
+}
@@ -0,0 +1,71 @@
+package loafertest
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterPolicy(t *testing.T) {
+	attributes := map[string]types.MessageAttributeValue{
+		"kind":   {DataType: aws.String("String"), StringValue: aws.String("order.created")},
+		"amount": {DataType: aws.String("Number"), StringValue: aws.String("42")},
+		"tags":   {DataType: aws.String("String.Array"), StringValue: aws.String(`["a","b"]`)},
+		"blob":   {DataType: aws.String("Binary"), BinaryValue: []byte("x")},
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		match  bool
+	}{
+		{name: "exact string", policy: `{"kind":["order.created"]}`, match: true},
+		{name: "any of the strings", policy: `{"kind":["x","order.created"]}`, match: true},
+		{name: "other string", policy: `{"kind":["order"]}`, match: false},
+		{name: "string against number", policy: `{"amount":["42"]}`, match: false},
+		{name: "exact number", policy: `{"amount":[42]}`, match: true},
+		{name: "prefix", policy: `{"kind":[{"prefix":"order."}]}`, match: true},
+		{name: "suffix", policy: `{"kind":[{"suffix":".deleted"}]}`, match: false},
+		{name: "equals ignore case", policy: `{"kind":[{"equals-ignore-case":"ORDER.CREATED"}]}`, match: true},
+		{name: "anything but", policy: `{"kind":[{"anything-but":["order.deleted"]}]}`, match: true},
+		{name: "anything but the value", policy: `{"kind":[{"anything-but":"order.created"}]}`, match: false},
+		{name: "anything but a prefix", policy: `{"kind":[{"anything-but":{"prefix":"order"}}]}`, match: false},
+		{name: "exists", policy: `{"kind":[{"exists":true}]}`, match: true},
+		{name: "not exists", policy: `{"missing":[{"exists":false}]}`, match: true},
+		{name: "missing attribute", policy: `{"missing":["x"]}`, match: false},
+		{name: "numeric range", policy: `{"amount":[{"numeric":[">",10,"<=",42]}]}`, match: true},
+		{name: "numeric out of range", policy: `{"amount":[{"numeric":["<",10]}]}`, match: false},
+		{name: "array element", policy: `{"tags":["b"]}`, match: true},
+		{name: "binary", policy: `{"blob":["x"]}`, match: false},
+		{name: "all the attributes", policy: `{"kind":["order.created"],"amount":[1]}`, match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseFilterPolicy(tt.policy)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.match, p.match(attributes))
+		})
+	}
+
+	t.Run("Should match everything without a policy", func(t *testing.T) {
+		var p filterPolicy
+		assert.True(t, p.match(nil))
+	})
+
+	t.Run("Should reject the invalid policies", func(t *testing.T) {
+		for _, policy := range []string{
+			`[]`,
+			`{"kind":"order"}`,
+			`{"kind":[{"wildcard":"*"}]}`,
+			`{"kind":[{"prefix":1}]}`,
+			`{"amount":[{"numeric":["!",1]}]}`,
+			`{"kind":[{"prefix":"a","suffix":"b"}]}`,
+		} {
+			_, err := parseFilterPolicy(policy)
+			assert.Error(t, err, policy)
+		}
+	})
+}
@@ -0,0 +1,302 @@
+package loafertest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+// Result reports what happened to a message fed to RunRoute or RunHandler
+type Result struct {
+	Message *Message
+	// Err is the error the route or the handler returned, nil when it succeeded.
+	// A panic is reported as a *loafergo.PanicError.
+	Err error
+	// Committed is true when the Manager committed the message and it was not backed off,
+	// so it is deleted from the queue
+	Committed bool
+	// DeadLettered is true when the message was dead-lettered through the route, see loafergo.DeadLetterRouter
+	DeadLettered     bool
+	DeadLetterReason string
+	// Backoffs are the delays the message was backed off by, the last one is applied
+	Backoffs []time.Duration
+	// Dispatched is the number of times the visibility extension of the message was stopped
+	Dispatched int
+}
+
+// Outcome returns the outcome the Manager settled the message with
+func (r *Result) Outcome() loafergo.OutcomeKind {
+	return loafergo.OutcomeOf(r.Err).Kind()
+}
+
+// BackedOff returns true if the message was backed off
+func (r *Result) BackedOff() bool {
+	return len(r.Backoffs) > 0
+}
+
+// Delay returns the delay the message was backed off by, zero when it was not
+func (r *Result) Delay() time.Duration {
+	if len(r.Backoffs) == 0 {
+		return 0
+	}
+	return r.Backoffs[len(r.Backoffs)-1]
+}
+
+// Retried returns true when the message was left in the queue to be received again
+func (r *Result) Retried() bool {
+	return !r.Committed && !r.DeadLettered
+}
+
+// Report holds the results of the messages fed to RunRoute or RunHandler
+type Report struct {
+	results []*Result
+}
+
+// Results returns the results in the order of the messages
+func (r *Report) Results() []*Result {
+	return r.results
+}
+
+// Result returns the result of the message, nil when it was not fed
+func (r *Report) Result(msg *Message) *Result {
+	for _, result := range r.results {
+		if result.Message == msg {
+			return result
+		}
+	}
+	return nil
+}
+
+// RunHandler runs the handler on the messages through a Manager, one at a time and in order,
+// and reports what happened to each of them once handled, see RunRoute.
+func RunHandler(ctx context.Context, h loafergo.Handler, msgs ...*Message) *Report {
+	return RunRoute(ctx, &handlerRoute{handler: h}, msgs...)
+}
+
+// RunRoute runs the route on the messages through a Manager and reports what happened to each
+// of them once they are all handled and settled: committed, backed off, left for a retry or dead-lettered.
+//
+// The messages are received once, instead of the ones of the route queue: the route is not configured,
+// its commits and dead-letters are recorded instead of performed, and it does not extend the visibility
+// of the messages, use a Broker to test them end to end. Its handler, middlewares, batch handler, outcome
+// translation and retry policy run as they do in production.
+//
+// RunRoute returns early, with the results so far, when ctx is done.
+func RunRoute(ctx context.Context, r loafergo.Router, msgs ...*Message) *Report {
+	h := newHarnessRoute(r, msgs)
+	if len(msgs) > 0 {
+		manager := loafergo.NewManager(&loafergo.Config{SlogHandler: slog.DiscardHandler})
+		manager.RegisterRoute(h)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = manager.Run(ctx)
+		}()
+
+		select {
+		case <-h.idle:
+		case <-ctx.Done():
+		}
+		_ = manager.Shutdown(ctx)
+		<-done
+	}
+
+	return h.report()
+}
+
+// harnessRoute feeds the messages to the route it wraps, and records what happens to them
+type harnessRoute struct {
+	loafergo.Router
+	msgs    []loafergo.Message
+	mu      sync.Mutex
+	fed     bool
+	pending int
+	// idle is closed once all the messages are handled
+	idle    chan struct{}
+	results map[string]*Result
+	ordered []*Result
+}
+
+func newHarnessRoute(r loafergo.Router, msgs []*Message) *harnessRoute {
+	h := &harnessRoute{
+		Router:  r,
+		msgs:    make([]loafergo.Message, len(msgs)),
+		pending: len(msgs),
+		idle:    make(chan struct{}),
+		results: make(map[string]*Result, len(msgs)),
+		ordered: make([]*Result, len(msgs)),
+	}
+	for i, msg := range msgs {
+		h.msgs[i] = msg
+		h.ordered[i] = &Result{Message: msg}
+		h.results[msg.Identifier()] = h.ordered[i]
+	}
+	if h.pending == 0 {
+		close(h.idle)
+	}
+	return h
+}
+
+// Configure does not configure the route, which does not receive from its queue
+func (h *harnessRoute) Configure(context.Context) error {
+	return nil
+}
+
+// GetMessages returns the messages on the first call, then waits for them to be handled
+func (h *harnessRoute) GetMessages(ctx context.Context, _ loafergo.Logger) ([]loafergo.Message, error) {
+	h.mu.Lock()
+	if !h.fed {
+		h.fed = true
+		h.mu.Unlock()
+		return h.msgs, nil
+	}
+	h.mu.Unlock()
+
+	select {
+	case <-h.idle:
+	case <-ctx.Done():
+	}
+	return nil, nil
+}
+
+// HandlerMessage calls the route handler and records its error
+func (h *harnessRoute) HandlerMessage(ctx context.Context, msg loafergo.Message) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			h.handled(msg, &loafergo.PanicError{Value: v})
+			panic(v)
+		}
+		h.handled(msg, err)
+	}()
+	return h.Router.HandlerMessage(ctx, msg)
+}
+
+// BatchMode reports whether the route handles the messages in batches
+func (h *harnessRoute) BatchMode(ctx context.Context) bool {
+	br, ok := h.Router.(loafergo.BatchRouter)
+	return ok && br.BatchMode(ctx)
+}
+
+// HandlerBatch calls the route batch handler and records the error of each message
+func (h *harnessRoute) HandlerBatch(ctx context.Context, msgs []loafergo.Message) (result loafergo.BatchResult) {
+	defer func() {
+		v := recover()
+		if v != nil {
+			result = loafergo.FailAll(msgs, &loafergo.PanicError{Value: v})
+		}
+		for _, msg := range msgs {
+			h.handled(msg, result.Err(msg))
+		}
+		if v != nil {
+			panic(v)
+		}
+	}()
+	return h.Router.(loafergo.BatchRouter).HandlerBatch(ctx, msgs)
+}
+
+// Commit records the commit, a backed off message is not deleted from the queue
+func (h *harnessRoute) Commit(_ context.Context, msg loafergo.Message) error {
+	h.record(msg, func(r *Result) {
+		r.Committed = !msg.BackedOff()
+	})
+	return nil
+}
+
+// DeadLetter records the dead-lettering, when the route supports it
+func (h *harnessRoute) DeadLetter(_ context.Context, msg loafergo.Message, reason string) error {
+	if _, ok := h.Router.(loafergo.DeadLetterRouter); !ok {
+		return errors.New("route does not support dead-lettering")
+	}
+
+	h.record(msg, func(r *Result) {
+		r.DeadLettered = true
+		r.DeadLetterReason = reason
+	})
+	return nil
+}
+
+// Name returns the name of the wrapped route
+func (h *harnessRoute) Name(ctx context.Context) string {
+	if nr, ok := h.Router.(loafergo.NamedRouter); ok {
+		return nr.Name(ctx)
+	}
+	return ""
+}
+
+func (h *harnessRoute) handled(msg loafergo.Message, err error) {
+	h.record(msg, func(r *Result) {
+		r.Err = err
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending--
+	if h.pending == 0 {
+		close(h.idle)
+	}
+}
+
+func (h *harnessRoute) record(msg loafergo.Message, fn func(r *Result)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if r, ok := h.results[msg.Identifier()]; ok {
+		fn(r)
+	}
+}
+
+func (h *harnessRoute) report() *Report {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, r := range h.ordered {
+		r.Backoffs = r.Message.Backoffs()
+		r.Dispatched = r.Message.Dispatched()
+	}
+	return &Report{results: h.ordered}
+}
+
+// handlerRoute is the route of RunHandler, handling the messages one at a time
+type handlerRoute struct {
+	handler loafergo.Handler
+}
+
+func (r *handlerRoute) Configure(context.Context) error {
+	return nil
+}
+
+func (r *handlerRoute) GetMessages(context.Context, loafergo.Logger) ([]loafergo.Message, error) {
+	return nil, nil
+}
+
+func (r *handlerRoute) HandlerMessage(ctx context.Context, msg loafergo.Message) error {
+	return r.handler(ctx, msg)
+}
+
+func (r *handlerRoute) Commit(context.Context, loafergo.Message) error {
+	return nil
+}
+
+func (r *handlerRoute) DeadLetter(context.Context, loafergo.Message, string) error {
+	return nil
+}
+
+func (r *handlerRoute) WorkerPoolSize(context.Context) int32 {
+	return 1
+}
+
+func (r *handlerRoute) VisibilityTimeout(context.Context) int32 {
+	return 0
+}
+
+func (r *handlerRoute) RunMode(context.Context) loafergo.Mode {
+	return loafergo.Parallel
+}
+
+func (r *handlerRoute) CustomGroupFields(context.Context) []string {
+	return nil
+}
@@ -0,0 +1,222 @@
+package loafertest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/aws/sqs"
+	"github.com/justcodes/loafer-go/v2/loafertest"
+)
+
+var _ loafergo.Message = (*loafertest.Message)(nil)
+
+func TestNewMessage(t *testing.T) {
+	t.Run("Should read the body and the attributes", func(t *testing.T) {
+		msg := loafertest.NewMessage(`{"id":1}`,
+			map[string]string{"kind": "order"},
+			map[string]string{"ApproximateReceiveCount": "2", "SentTimestamp": "1700000000000"},
+		)
+
+		var out struct{ ID int }
+		assert.NoError(t, msg.Decode(&out))
+		assert.Equal(t, 1, out.ID)
+		assert.Equal(t, `{"id":1}`, msg.Message())
+		assert.Equal(t, "order", msg.Attribute("kind"))
+		assert.Equal(t, loafergo.MessageAttribute{DataType: "String", StringValue: "order"}, msg.NativeAttributes()["kind"])
+		assert.Equal(t, "2", msg.SystemAttributeByKey("ApproximateReceiveCount"))
+		assert.Equal(t, time.UnixMilli(1700000000000), msg.TimeStamp())
+		assert.NotEmpty(t, msg.MessageID())
+		assert.NotEqual(t, msg.MessageID(), msg.Identifier())
+	})
+
+	t.Run("Should read an SNS envelope", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{
+			"Type":              "Notification",
+			"MessageId":         "id-1",
+			"Message":           `{"id":2}`,
+			"Timestamp":         "2024-01-02T03:04:05.000Z",
+			"MessageAttributes": map[string]any{"kind": map[string]string{"Type": "String", "Value": "refund"}},
+		})
+		msg := loafertest.NewMessage(string(body), map[string]string{"kind": "order"}, nil)
+
+		var out struct{ ID int }
+		assert.NoError(t, msg.DecodeMessage(&out))
+		assert.Equal(t, 2, out.ID)
+		assert.Equal(t, "id-1", msg.MessageID())
+		assert.Equal(t, "refund", msg.Attribute("kind"))
+		assert.Equal(t, map[string]string{"kind": "refund"}, msg.Metadata())
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), msg.TimeStamp().UTC())
+	})
+}
+
+func TestRunHandler(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+
+	msgs := map[string]*loafertest.Message{}
+	for _, body := range []string{"ack", "nack", "retry", "dead", "drop", "panic"} {
+		msgs[body] = loafertest.NewMessage(body, nil, nil)
+	}
+
+	report := loafertest.RunHandler(ctx, func(_ context.Context, msg loafergo.Message) error {
+		switch string(msg.Body()) {
+		case "nack":
+			return errFailed
+		case "retry":
+			return loafergo.RetryAfter(5 * time.Second)
+		case "dead":
+			return loafergo.DeadLetter("invalid")
+		case "drop":
+			return loafergo.Drop()
+		case "panic":
+			panic("boom")
+		}
+		return nil
+	}, msgs["ack"], msgs["nack"], msgs["retry"], msgs["dead"], msgs["drop"], msgs["panic"])
+
+	assert.Len(t, report.Results(), 6)
+
+	ack := report.Result(msgs["ack"])
+	assert.NoError(t, ack.Err)
+	assert.True(t, ack.Committed)
+	assert.False(t, ack.Retried())
+
+	nack := report.Result(msgs["nack"])
+	assert.ErrorIs(t, nack.Err, errFailed)
+	assert.Equal(t, loafergo.OutcomeNack, nack.Outcome())
+	assert.True(t, nack.Retried())
+
+	retry := report.Result(msgs["retry"])
+	assert.Equal(t, loafergo.OutcomeRetryAfter, retry.Outcome())
+	assert.True(t, retry.BackedOff())
+	assert.Equal(t, 5*time.Second, retry.Delay())
+	assert.True(t, retry.Retried())
+
+	dead := report.Result(msgs["dead"])
+	assert.True(t, dead.DeadLettered)
+	assert.Equal(t, "invalid", dead.DeadLetterReason)
+	assert.True(t, dead.Committed)
+
+	drop := report.Result(msgs["drop"])
+	assert.Equal(t, loafergo.OutcomeDrop, drop.Outcome())
+	assert.True(t, drop.Committed)
+
+	panicked := report.Result(msgs["panic"])
+	var panicErr *loafergo.PanicError
+	assert.ErrorAs(t, panicked.Err, &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.True(t, panicked.Retried())
+
+	assert.Nil(t, report.Result(loafertest.NewMessage("other", nil, nil)))
+	assert.Empty(t, loafertest.RunHandler(ctx, func(context.Context, loafergo.Message) error { return nil }).Results())
+}
+
+func TestRunRoute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should back off the failed messages following the retry policy", func(t *testing.T) {
+		route := sqs.NewRoute(&sqs.Config{
+			SQSClient: loafertest.NewBroker(),
+			QueueName: "orders",
+			Handler: func(context.Context, loafergo.Message) error {
+				return errors.New("failed")
+			},
+		}, sqs.RouteWithRetryPolicy(loafergo.NewRetryPolicy(time.Second, time.Minute, loafergo.RetryWithJitter(0))))
+
+		first := loafertest.NewMessage("a", nil, map[string]string{"ApproximateReceiveCount": "1"})
+		third := loafertest.NewMessage("b", nil, map[string]string{"ApproximateReceiveCount": "3"})
+		report := loafertest.RunRoute(ctx, route, first, third)
+
+		assert.Equal(t, time.Second, report.Result(first).Delay())
+		assert.Equal(t, 4*time.Second, report.Result(third).Delay())
+		assert.True(t, report.Result(third).Retried())
+	})
+
+	t.Run("Should dispatch the failed messages without retry policy", func(t *testing.T) {
+		route := sqs.NewRoute(&sqs.Config{
+			SQSClient: loafertest.NewBroker(),
+			QueueName: "orders",
+			Handler: func(context.Context, loafergo.Message) error {
+				return errors.New("failed")
+			},
+		})
+
+		msg := loafertest.NewMessage("a", nil, nil)
+		result := loafertest.RunRoute(ctx, route, msg).Result(msg)
+		assert.False(t, result.BackedOff())
+		assert.Equal(t, 1, result.Dispatched)
+		assert.True(t, result.Retried())
+	})
+
+	t.Run("Should dead-letter the messages received too many times", func(t *testing.T) {
+		var deadLettered []string
+		route := sqs.NewRoute(&sqs.Config{
+			SQSClient: loafertest.NewBroker(),
+			QueueName: "orders",
+			Handler: func(context.Context, loafergo.Message) error {
+				return nil
+			},
+		},
+			sqs.RouteWithMaxReceiveCount(3, "orders-dlq"),
+			sqs.RouteWithDeadLetterFunc(func(_ context.Context, m loafergo.Message, _ string) error {
+				deadLettered = append(deadLettered, string(m.Body()))
+				return nil
+			}),
+		)
+
+		report := loafertest.RunRoute(ctx, route,
+			loafertest.NewMessage("fresh", nil, map[string]string{"ApproximateReceiveCount": "1"}),
+			loafertest.NewMessage("poison", nil, map[string]string{"ApproximateReceiveCount": "4"}),
+		)
+
+		assert.Equal(t, []string{"poison"}, deadLettered)
+		for _, r := range report.Results() {
+			assert.True(t, r.Committed)
+		}
+	})
+
+	t.Run("Should record the dead-letter outcomes", func(t *testing.T) {
+		route := sqs.NewRoute(&sqs.Config{
+			SQSClient: loafertest.NewBroker(),
+			QueueName: "orders",
+			Handler: func(context.Context, loafergo.Message) error {
+				return loafergo.DeadLetter("unknown type")
+			},
+		})
+
+		msg := loafertest.NewMessage("a", nil, nil)
+		result := loafertest.RunRoute(ctx, route, msg).Result(msg)
+		assert.True(t, result.DeadLettered)
+		assert.Equal(t, "unknown type", result.DeadLetterReason)
+	})
+
+	t.Run("Should report the messages of a batch handler", func(t *testing.T) {
+		route := sqs.NewRoute(&sqs.Config{
+			SQSClient: loafertest.NewBroker(),
+			QueueName: "orders",
+			BatchHandler: func(_ context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+				result := loafergo.BatchResult{}
+				for _, msg := range msgs {
+					if string(msg.Body()) == "bad" {
+						result.Fail(msg, errors.New("bad"))
+					}
+				}
+				return result
+			},
+		})
+
+		good := loafertest.NewMessage("good", nil, nil)
+		bad := loafertest.NewMessage("bad", nil, nil)
+		report := loafertest.RunRoute(ctx, route, good, bad)
+
+		assert.True(t, report.Result(good).Committed)
+		assert.EqualError(t, report.Result(bad).Err, "bad")
+		assert.True(t, report.Result(bad).Retried())
+	})
+}
@@ -0,0 +1,204 @@
+package loafertest_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/aws/sns"
+	"github.com/justcodes/loafer-go/v2/aws/sqs"
+	"github.com/justcodes/loafer-go/v2/cloudevents"
+	"github.com/justcodes/loafer-go/v2/loafertest"
+)
+
+// run runs the manager with the route until done returns true, then shuts it down
+func run(t *testing.T, route loafergo.Router, done func() bool) {
+	manager := loafergo.NewManager(&loafergo.Config{})
+	manager.RegisterRoute(route)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- manager.Run(context.Background())
+	}()
+
+	assert.Eventually(t, done, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, manager.Shutdown(context.Background()))
+	assert.NoError(t, <-errCh)
+}
+
+func TestIntegration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should handle the messages published to the topic and delete them", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		arn := b.CreateTopic("events")
+		assert.NoError(t, b.Subscribe(arn, b.CreateQueue("orders")))
+
+		producer, err := sns.NewProducer(&sns.Config{SNSClient: b})
+		assert.NoError(t, err)
+
+		type order struct {
+			ID int `json:"id"`
+		}
+		for i := range 3 {
+			_, err = producer.Produce(ctx, &sns.PublishInput{
+				TopicARN:   arn,
+				Payload:    order{ID: i},
+				Attributes: map[string]string{"kind": "order"},
+			})
+			assert.NoError(t, err)
+		}
+
+		var mu sync.Mutex
+		var handled []int
+		route := sqs.NewRoute(&sqs.Config{
+			SQSClient: b,
+			QueueName: "orders",
+			Handler: func(_ context.Context, msg loafergo.Message) error {
+				var o order
+				if err := msg.DecodeMessage(&o); err != nil {
+					return err
+				}
+				if msg.Attribute("kind") != "order" {
+					return fmt.Errorf("unexpected kind %q", msg.Attribute("kind"))
+				}
+
+				mu.Lock()
+				handled = append(handled, o.ID)
+				mu.Unlock()
+				return nil
+			},
+		}, sqs.RouteWithWaitTimeSeconds(1))
+
+		run(t, route, func() bool { return b.Len("orders") == 0 })
+		assert.ElementsMatch(t, []int{0, 1, 2}, handled)
+	})
+
+	t.Run("Should handle each FIFO group in order", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders.fifo", loafertest.QueueWithContentBasedDeduplication())
+
+		producer, err := sqs.NewProducer(&sqs.ProducerConfig{SQSClient: b})
+		assert.NoError(t, err)
+
+		for i := range 10 {
+			_, err = producer.Produce(ctx, &sqs.SendInput{
+				QueueURL: url,
+				Message:  fmt.Sprintf("%d", i),
+				GroupID:  fmt.Sprintf("g%d", i%2),
+			})
+			assert.NoError(t, err)
+		}
+
+		var mu sync.Mutex
+		handled := make(map[string][]string)
+		route := sqs.NewRoute(&sqs.Config{
+			SQSClient: b,
+			QueueName: "orders.fifo",
+			Handler: func(_ context.Context, msg loafergo.Message) error {
+				mu.Lock()
+				defer mu.Unlock()
+				group := msg.SystemAttributeByKey("MessageGroupId")
+				handled[group] = append(handled[group], string(msg.Body()))
+				return nil
+			},
+		},
+			sqs.RouteWithWaitTimeSeconds(1),
+			sqs.RouteWithMaxMessages(3),
+			sqs.RouteWithRunMode(loafergo.PerGroupID),
+			sqs.RouteWithBatchCommit(10, 10*time.Millisecond),
+		)
+
+		run(t, route, func() bool { return b.Len("orders.fifo") == 0 })
+		assert.Equal(t, map[string][]string{
+			"g0": {"0", "2", "4", "6", "8"},
+			"g1": {"1", "3", "5", "7", "9"},
+		}, handled)
+	})
+
+	t.Run("Should decode the CloudEvents sent in structured and binary mode", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("events")
+
+		producer, err := sqs.NewProducer(&sqs.ProducerConfig{SQSClient: b})
+		assert.NoError(t, err)
+
+		type order struct {
+			ID int `json:"id"`
+		}
+		for i, mode := range []cloudevents.Mode{cloudevents.Structured, cloudevents.Binary} {
+			event, err := cloudevents.New("/orders", "order.created", order{ID: i})
+			assert.NoError(t, err)
+			_, err = producer.ProduceEvent(ctx, &sqs.SendEventInput{QueueURL: url, Event: event, Mode: mode})
+			assert.NoError(t, err)
+		}
+
+		var mu sync.Mutex
+		var handled []int
+		route := sqs.NewRoute(&sqs.Config{
+			SQSClient: b,
+			QueueName: "events",
+			Handler: func(_ context.Context, msg loafergo.Message) error {
+				if _, err := cloudevents.FromMessage(msg); err != nil {
+					return err
+				}
+
+				// a binary message carries the event data only, a structured one the whole event
+				var o order
+				var err error
+				if msg.Attribute(cloudevents.AttributePrefix+"id") != "" {
+					err = msg.Decode(&o)
+				} else {
+					var envelope struct {
+						Data order `json:"data"`
+					}
+					err = msg.Decode(&envelope)
+					o = envelope.Data
+				}
+				if err != nil {
+					return err
+				}
+
+				mu.Lock()
+				handled = append(handled, o.ID)
+				mu.Unlock()
+				return nil
+			},
+		}, sqs.RouteWithWaitTimeSeconds(1))
+
+		run(t, route, func() bool { return b.Len("events") == 0 })
+		assert.ElementsMatch(t, []int{0, 1}, handled)
+	})
+
+	t.Run("Should redrive the message the handler keeps retrying", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders", loafertest.QueueWithRedrive("orders-dlq", 3))
+		b.CreateQueue("orders-dlq")
+
+		producer, err := sqs.NewProducer(&sqs.ProducerConfig{SQSClient: b})
+		assert.NoError(t, err)
+		_, err = producer.Produce(ctx, &sqs.SendInput{QueueURL: url, Message: "poison"})
+		assert.NoError(t, err)
+
+		var attempts atomic.Int32
+		route := sqs.NewRoute(&sqs.Config{
+			SQSClient: b,
+			QueueName: "orders",
+			Handler: func(context.Context, loafergo.Message) error {
+				attempts.Add(1)
+				return loafergo.RetryAfter(0)
+			},
+		}, sqs.RouteWithWaitTimeSeconds(1))
+
+		run(t, route, func() bool { return b.Len("orders-dlq") == 1 })
+		assert.Equal(t, int32(3), attempts.Load())
+		assert.Equal(t, 0, b.Len("orders"))
+		assert.Equal(t, []string{"poison"}, b.Bodies("orders-dlq"))
+	})
+}
@@ -0,0 +1,230 @@
+package loafertest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/codec"
+)
+
+const notification = "Notification"
+
+// Message is a loafergo.Message built by NewMessage to be fed to a handler or a route under test.
+// It records the backoffs and dispatches the route and the Manager apply to it.
+type Message struct {
+	mu               sync.Mutex
+	id               string
+	identifier       string
+	body             string
+	attributes       map[string]string
+	systemAttributes map[string]string
+	// envelope is the SNS notification the body holds, if any
+	envelope   *envelope
+	timestamp  time.Time
+	backoffs   []time.Duration
+	dispatched int
+}
+
+// NewMessage builds a message with the body, the String message attributes and the system
+// attributes, like ApproximateReceiveCount or MessageGroupId.
+//
+// A body holding an SNS notification envelope is read as the sqs routes read it: Message and
+// DecodeMessage return the published message, and the envelope attributes take precedence.
+func NewMessage(body string, attributes, systemAttributes map[string]string) *Message {
+	m := &Message{
+		id:               newID(),
+		identifier:       newID(),
+		body:             body,
+		attributes:       maps.Clone(attributes),
+		systemAttributes: maps.Clone(systemAttributes),
+		timestamp:        time.Now(),
+	}
+	if m.attributes == nil {
+		m.attributes = map[string]string{}
+	}
+	if m.systemAttributes == nil {
+		m.systemAttributes = map[string]string{}
+	}
+
+	if ms, err := strconv.ParseInt(m.systemAttributes["SentTimestamp"], 10, 64); err == nil {
+		m.timestamp = time.UnixMilli(ms)
+	}
+
+	var e envelope
+	if json.Unmarshal([]byte(body), &e) == nil && e.Type == notification {
+		m.envelope = &e
+		if ts, err := time.Parse(time.RFC3339, e.Timestamp); err == nil {
+			m.timestamp = ts
+		}
+		if e.MessageID != "" {
+			m.id = e.MessageID
+		}
+	}
+	return m
+}
+
+// Decode will unmarshal the body into a supplied output,
+// using the codec named by the content-type attribute, JSON by default
+func (m *Message) Decode(out interface{}) error {
+	return codec.Decode([]byte(m.body), m.codecAttributes(m.attributes), out, nil)
+}
+
+// DecodeMessage will unmarshal the message into a supplied output,
+// the published one when the body is an SNS envelope, using the codec named by its content-type attribute
+func (m *Message) DecodeMessage(out any) error {
+	if m.envelope == nil {
+		return m.Decode(out)
+	}
+
+	attributes := make(map[string]string, len(m.envelope.MessageAttributes))
+	for k, v := range m.envelope.MessageAttributes {
+		attributes[k] = v.Value
+	}
+	return codec.Decode([]byte(m.envelope.Message), m.codecAttributes(attributes), out, nil)
+}
+
+func (m *Message) codecAttributes(attributes map[string]string) map[string]string {
+	attrs := make(map[string]string, 2)
+	for _, k := range []string{codec.ContentTypeAttribute, codec.ContentEncodingAttribute} {
+		if v, ok := attributes[k]; ok {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
+// Attribute returns the attribute, looking up the SNS envelope attributes first
+func (m *Message) Attribute(key string) string {
+	if m.envelope != nil {
+		if a, ok := m.envelope.MessageAttributes[key]; ok {
+			return a.Value
+		}
+	}
+	return m.attributes[key]
+}
+
+// Attributes returns the message attributes merged with the SNS envelope ones, the latter taking precedence
+func (m *Message) Attributes() map[string]string {
+	a := maps.Clone(m.attributes)
+	maps.Copy(a, m.Metadata())
+	return a
+}
+
+// NativeAttributes returns the message attributes, all of them String ones
+func (m *Message) NativeAttributes() map[string]loafergo.MessageAttribute {
+	a := make(map[string]loafergo.MessageAttribute, len(m.attributes))
+	for k, v := range m.attributes {
+		a[k] = loafergo.MessageAttribute{DataType: "String", StringValue: v}
+	}
+	return a
+}
+
+// EnvelopeAttributes returns the attributes of the SNS envelope, Binary values decoded into BinaryValue
+func (m *Message) EnvelopeAttributes() map[string]loafergo.MessageAttribute {
+	a := make(map[string]loafergo.MessageAttribute)
+	if m.envelope == nil {
+		return a
+	}
+
+	for k, v := range m.envelope.MessageAttributes {
+		attr := loafergo.MessageAttribute{DataType: v.Type, StringValue: v.Value}
+		if strings.HasPrefix(v.Type, "Binary") {
+			if b, err := base64.StdEncoding.DecodeString(v.Value); err == nil {
+				attr = loafergo.MessageAttribute{DataType: v.Type, BinaryValue: b}
+			}
+		}
+		a[k] = attr
+	}
+	return a
+}
+
+// SystemAttributeByKey returns the system attribute
+func (m *Message) SystemAttributeByKey(key string) string {
+	return m.systemAttributes[key]
+}
+
+// SystemAttributes returns the system attributes
+func (m *Message) SystemAttributes() map[string]string {
+	return maps.Clone(m.systemAttributes)
+}
+
+// Metadata returns the attributes of the SNS envelope
+func (m *Message) Metadata() map[string]string {
+	a := map[string]string{}
+	if m.envelope != nil {
+		for k, v := range m.envelope.MessageAttributes {
+			a[k] = v.Value
+		}
+	}
+	return a
+}
+
+// Identifier returns the receipt handle generated for the message
+func (m *Message) Identifier() string {
+	return m.identifier
+}
+
+// MessageID returns the ID generated for the message, or the one of its SNS envelope
+func (m *Message) MessageID() string {
+	return m.id
+}
+
+// Dispatch records that the message was dispatched
+func (m *Message) Dispatch() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dispatched++
+}
+
+// Backoff records the delay the message was backed off by
+func (m *Message) Backoff(delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.backoffs = append(m.backoffs, delay)
+}
+
+// BackedOff returns true if the message was backed off
+func (m *Message) BackedOff() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.backoffs) > 0
+}
+
+// Body returns the message body
+func (m *Message) Body() []byte {
+	return []byte(m.body)
+}
+
+// Message returns the published message when the body is an SNS envelope, the body otherwise
+func (m *Message) Message() string {
+	if m.envelope != nil {
+		return m.envelope.Message
+	}
+	return m.body
+}
+
+// TimeStamp returns the SNS envelope timestamp, the SentTimestamp system attribute,
+// or the time the message was built
+func (m *Message) TimeStamp() time.Time {
+	return m.timestamp
+}
+
+// Backoffs returns the delays the message was backed off by, in order
+func (m *Message) Backoffs() []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Duration(nil), m.backoffs...)
+}
+
+// Dispatched returns the number of times the message was dispatched
+func (m *Message) Dispatched() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.dispatched
+}
@@ -0,0 +1,315 @@
+package loafertest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// QueueConfig are a discrete set of options that are valid for loading the queue configuration
+type QueueConfig struct {
+	visibilityTimeout         time.Duration
+	delay                     time.Duration
+	receiveWaitTime           time.Duration
+	contentBasedDeduplication bool
+	deadLetterQueue           string
+	maxReceiveCount           int
+}
+
+// LoadQueueConfigFunc is a type alias for QueueConfig functional config
+type LoadQueueConfigFunc func(config *QueueConfig)
+
+// QueueWithVisibilityTimeout sets the default visibility timeout of the queue.
+// The default is 30 seconds.
+func QueueWithVisibilityTimeout(d time.Duration) LoadQueueConfigFunc {
+	return func(c *QueueConfig) {
+		c.visibilityTimeout = d
+	}
+}
+
+// QueueWithDelay sets the delay of the messages sent without their own DelaySeconds
+func QueueWithDelay(d time.Duration) LoadQueueConfigFunc {
+	return func(c *QueueConfig) {
+		c.delay = d
+	}
+}
+
+// QueueWithReceiveWaitTime sets the long polling time of the receive calls without their own WaitTimeSeconds
+func QueueWithReceiveWaitTime(d time.Duration) LoadQueueConfigFunc {
+	return func(c *QueueConfig) {
+		c.receiveWaitTime = d
+	}
+}
+
+// QueueWithContentBasedDeduplication deduplicates the messages of a FIFO queue
+// sent without a MessageDeduplicationId by the SHA-256 of their body.
+func QueueWithContentBasedDeduplication() LoadQueueConfigFunc {
+	return func(c *QueueConfig) {
+		c.contentBasedDeduplication = true
+	}
+}
+
+// QueueWithRedrive moves the messages received more than maxReceiveCount times to the
+// deadLetterQueue, named as in CreateQueue. The dead-letter queue is resolved on each move,
+// it may be created after the source queue.
+func QueueWithRedrive(deadLetterQueue string, maxReceiveCount int) LoadQueueConfigFunc {
+	return func(c *QueueConfig) {
+		c.deadLetterQueue = deadLetterQueue
+		c.maxReceiveCount = maxReceiveCount
+	}
+}
+
+type queue struct {
+	name     string
+	url      string
+	arn      string
+	fifo     bool
+	config   QueueConfig
+	messages []*storedMessage
+	// handles maps each receipt handle ever issued to its message
+	handles map[string]*storedMessage
+	dedup   map[string]dedupRecord
+}
+
+type dedupRecord struct {
+	messageID      string
+	sequenceNumber string
+	expiresAt      time.Time
+}
+
+type storedMessage struct {
+	id              string
+	body            string
+	attributes      map[string]types.MessageAttributeValue
+	groupID         string
+	deduplicationID string
+	sequenceNumber  string
+	sentAt          time.Time
+	firstReceivedAt time.Time
+	visibleAt       time.Time
+	receiveCount    int
+	receiptHandle   string
+	deleted         bool
+}
+
+// inFlight reports whether the message was received and is not visible again yet
+func (m *storedMessage) inFlight(now time.Time) bool {
+	return m.receiptHandle != "" && m.visibleAt.After(now)
+}
+
+// CreateQueue creates the queue and returns its URL. A name ending with .fifo creates a FIFO queue.
+// Creating an existing queue returns its URL and leaves it unchanged.
+func (b *Broker) CreateQueue(name string, optFns ...LoadQueueConfigFunc) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if q, ok := b.queues[name]; ok {
+		return q.url
+	}
+
+	cfg := QueueConfig{visibilityTimeout: defaultVisibilityTimeout}
+	for _, optFn := range optFns {
+		optFn(&cfg)
+	}
+
+	q := &queue{
+		name:    name,
+		url:     fmt.Sprintf("https://sqs.%s.amazonaws.com/%s/%s", b.region, b.accountID, name),
+		arn:     fmt.Sprintf("arn:aws:sqs:%s:%s:%s", b.region, b.accountID, name),
+		fifo:    strings.HasSuffix(name, fifoSuffix),
+		config:  cfg,
+		handles: make(map[string]*storedMessage),
+		dedup:   make(map[string]dedupRecord),
+	}
+	b.queues[name] = q
+	return q.url
+}
+
+// sendEntry is a message sent to a queue, directly or delivered by a topic
+type sendEntry struct {
+	body            string
+	attributes      map[string]types.MessageAttributeValue
+	groupID         string
+	deduplicationID string
+	delaySeconds    int32
+}
+
+// send validates and enqueues the entry, b.mu must be held.
+// A FIFO duplicate is not enqueued, the message and sequence number of the original are returned.
+func (b *Broker) send(q *queue, e sendEntry) (messageID, sequenceNumber string, err error) {
+	if err := validateSendEntry(q, &e); err != nil {
+		return "", "", err
+	}
+
+	now := b.now()
+	if q.fifo {
+		if e.deduplicationID == "" {
+			sum := sha256.Sum256([]byte(e.body))
+			e.deduplicationID = hex.EncodeToString(sum[:])
+		}
+		if r, ok := q.dedup[e.deduplicationID]; ok && r.expiresAt.After(now) {
+			return r.messageID, r.sequenceNumber, nil
+		}
+	}
+
+	delay := q.config.delay
+	if e.delaySeconds > 0 {
+		delay = time.Duration(e.delaySeconds) * time.Second
+	}
+
+	m := &storedMessage{
+		id:              newID(),
+		body:            e.body,
+		attributes:      e.attributes,
+		groupID:         e.groupID,
+		deduplicationID: e.deduplicationID,
+		sentAt:          now,
+		visibleAt:       now.Add(delay),
+	}
+	if q.fifo {
+		m.sequenceNumber = b.nextSequenceNumber()
+		b.purgeDeduplication(q, now)
+		q.dedup[e.deduplicationID] = dedupRecord{
+			messageID:      m.id,
+			sequenceNumber: m.sequenceNumber,
+			expiresAt:      now.Add(deduplicationInterval),
+		}
+	}
+
+	q.messages = append(q.messages, m)
+	b.notify()
+	return m.id, m.sequenceNumber, nil
+}
+
+func (b *Broker) purgeDeduplication(q *queue, now time.Time) {
+	for id, r := range q.dedup {
+		if !r.expiresAt.After(now) {
+			delete(q.dedup, id)
+		}
+	}
+}
+
+// receive returns up to max visible messages and hides them for visibility, b.mu must be held.
+// The messages received more than the max receive count of the queue are moved to its dead-letter queue.
+// The messages of a FIFO group are received in order, and not while an earlier one is in flight.
+func (b *Broker) receive(q *queue, maxMessages int, visibility time.Duration) []*storedMessage {
+	now := b.now()
+	blocked := make(map[string]bool)
+	remaining := make([]*storedMessage, 0, len(q.messages))
+	var received []*storedMessage
+
+	for _, m := range q.messages {
+		if len(received) == maxMessages || (q.fifo && blocked[m.groupID]) {
+			remaining = append(remaining, m)
+			continue
+		}
+
+		if m.visibleAt.After(now) {
+			blocked[m.groupID] = true
+			remaining = append(remaining, m)
+			continue
+		}
+
+		if dlq, ok := b.queues[q.config.deadLetterQueue]; ok && m.receiveCount >= q.config.maxReceiveCount {
+			b.redrive(dlq, m, now)
+			continue
+		}
+
+		m.receiveCount++
+		if m.firstReceivedAt.IsZero() {
+			m.firstReceivedAt = now
+		}
+		m.visibleAt = now.Add(visibility)
+		m.receiptHandle = newID()
+		q.handles[m.receiptHandle] = m
+
+		received = append(received, m)
+		remaining = append(remaining, m)
+	}
+
+	q.messages = remaining
+	return received
+}
+
+// redrive moves the message to the dead-letter queue, keeping its ID, body, attributes and sent time
+func (b *Broker) redrive(dlq *queue, m *storedMessage, now time.Time) {
+	m.deleted = true
+
+	moved := &storedMessage{
+		id:              m.id,
+		body:            m.body,
+		attributes:      m.attributes,
+		groupID:         m.groupID,
+		deduplicationID: m.deduplicationID,
+		sentAt:          m.sentAt,
+		visibleAt:       now,
+	}
+	if dlq.fifo {
+		moved.sequenceNumber = b.nextSequenceNumber()
+	}
+
+	dlq.messages = append(dlq.messages, moved)
+	b.notify()
+}
+
+// delete removes the message received with the handle, b.mu must be held.
+// Deleting a message already deleted succeeds, as it does on SQS.
+func (b *Broker) delete(q *queue, handle string) error {
+	m, ok := q.handles[handle]
+	if !ok || (!m.deleted && m.receiptHandle != handle) {
+		return receiptHandleIsInvalid(handle)
+	}
+	if m.deleted {
+		return nil
+	}
+
+	m.deleted = true
+	for i, stored := range q.messages {
+		if stored == m {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			break
+		}
+	}
+	b.notify()
+	return nil
+}
+
+// changeVisibility hides the in flight message received with the handle for timeout from now, b.mu must be held
+func (b *Broker) changeVisibility(q *queue, handle string, timeout time.Duration) error {
+	m, ok := q.handles[handle]
+	if !ok {
+		return receiptHandleIsInvalid(handle)
+	}
+
+	now := b.now()
+	if m.deleted || m.receiptHandle != handle || !m.inFlight(now) {
+		return &types.MessageNotInflight{Message: stringPtr("The message referred to isn't in flight.")}
+	}
+
+	m.visibleAt = now.Add(timeout)
+	b.notify()
+	return nil
+}
+
+// nextVisibleIn returns the time until the next hidden message of the queue becomes visible,
+// zero when there is none, b.mu must be held
+func (b *Broker) nextVisibleIn(q *queue) time.Duration {
+	now := b.now()
+	var next time.Duration
+	for _, m := range q.messages {
+		if d := m.visibleAt.Sub(now); d > 0 && (next == 0 || d < next) {
+			next = d
+		}
+	}
+	return next
+}
+
+func receiptHandleIsInvalid(handle string) error {
+	return &types.ReceiptHandleIsInvalid{
+		Message: stringPtr(fmt.Sprintf("The input receipt handle %q is not a valid receipt handle.", handle)),
+	}
+}
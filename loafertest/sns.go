@@ -0,0 +1,459 @@
+package loafertest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+)
+
+// TopicConfig are a discrete set of options that are valid for loading the topic configuration
+type TopicConfig struct {
+	contentBasedDeduplication bool
+}
+
+// LoadTopicConfigFunc is a type alias for TopicConfig functional config
+type LoadTopicConfigFunc func(config *TopicConfig)
+
+// TopicWithContentBasedDeduplication deduplicates the messages of a FIFO topic
+// published without a MessageDeduplicationId by the SHA-256 of their body.
+func TopicWithContentBasedDeduplication() LoadTopicConfigFunc {
+	return func(c *TopicConfig) {
+		c.contentBasedDeduplication = true
+	}
+}
+
+// SubscriptionConfig are a discrete set of options that are valid for loading the subscription configuration
+type SubscriptionConfig struct {
+	rawMessageDelivery bool
+	filterPolicy       string
+}
+
+// LoadSubscriptionConfigFunc is a type alias for SubscriptionConfig functional config
+type LoadSubscriptionConfigFunc func(config *SubscriptionConfig)
+
+// SubscriptionWithRawMessageDelivery delivers the published message as the queue message body,
+// with the published attributes as its message attributes, instead of the SNS notification envelope.
+func SubscriptionWithRawMessageDelivery() LoadSubscriptionConfigFunc {
+	return func(c *SubscriptionConfig) {
+		c.rawMessageDelivery = true
+	}
+}
+
+// SubscriptionWithFilterPolicy only delivers the messages whose attributes match the JSON filter policy.
+// The exact strings and numbers, prefix, suffix, equals-ignore-case, anything-but, exists and numeric
+// operators are supported.
+func SubscriptionWithFilterPolicy(policy string) LoadSubscriptionConfigFunc {
+	return func(c *SubscriptionConfig) {
+		c.filterPolicy = policy
+	}
+}
+
+type topic struct {
+	name          string
+	arn           string
+	fifo          bool
+	config        TopicConfig
+	subscriptions []*subscription
+	dedup         map[string]dedupRecord
+}
+
+type subscription struct {
+	queue  *queue
+	raw    bool
+	filter filterPolicy
+}
+
+// envelope is the SNS notification delivered to the subscriptions without raw message delivery
+type envelope struct {
+	Type              string                       `json:"Type"`
+	MessageID         string                       `json:"MessageId"`
+	SequenceNumber    string                       `json:"SequenceNumber,omitempty"`
+	TopicArn          string                       `json:"TopicArn"`
+	Subject           string                       `json:"Subject,omitempty"`
+	Message           string                       `json:"Message"`
+	Timestamp         string                       `json:"Timestamp"`
+	SignatureVersion  string                       `json:"SignatureVersion"`
+	Signature         string                       `json:"Signature"`
+	SigningCertURL    string                       `json:"SigningCertURL"`
+	UnsubscribeURL    string                       `json:"UnsubscribeURL"`
+	MessageAttributes map[string]envelopeAttribute `json:"MessageAttributes,omitempty"`
+}
+
+type envelopeAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// publishEntry is a message published to a topic, alone or in a batch
+type publishEntry struct {
+	message          string
+	messageStructure string
+	subject          string
+	attributes       map[string]types.MessageAttributeValue
+	groupID          string
+	deduplicationID  string
+}
+
+// CreateTopic creates the topic and returns its ARN. A name ending with .fifo creates a FIFO topic.
+// Creating an existing topic returns its ARN and leaves it unchanged.
+func (b *Broker) CreateTopic(name string, optFns ...LoadTopicConfigFunc) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, t := range b.topics {
+		if t.name == name {
+			return t.arn
+		}
+	}
+
+	var cfg TopicConfig
+	for _, optFn := range optFns {
+		optFn(&cfg)
+	}
+
+	t := &topic{
+		name:   name,
+		arn:    fmt.Sprintf("arn:aws:sns:%s:%s:%s", b.region, b.accountID, name),
+		fifo:   strings.HasSuffix(name, fifoSuffix),
+		config: cfg,
+		dedup:  make(map[string]dedupRecord),
+	}
+	b.topics[t.arn] = t
+	return t.arn
+}
+
+// Subscribe subscribes the queue to the topic. A FIFO topic only delivers to FIFO queues,
+// and a standard topic to standard queues.
+func (b *Broker) Subscribe(topicARN, queueURL string, optFns ...LoadSubscriptionConfigFunc) error {
+	var cfg SubscriptionConfig
+	for _, optFn := range optFns {
+		optFn(&cfg)
+	}
+
+	var filter filterPolicy
+	if cfg.filterPolicy != "" {
+		var err error
+		if filter, err = parseFilterPolicy(cfg.filterPolicy); err != nil {
+			return &types.InvalidParameterException{Message: stringPtr(err.Error())}
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, err := b.topic(&topicARN)
+	if err != nil {
+		return err
+	}
+
+	q, err := b.queueByURL(&queueURL)
+	if err != nil {
+		return &types.InvalidParameterException{Message: stringPtr("Invalid parameter: Endpoint " + err.Error())}
+	}
+	if q.fifo != t.fifo {
+		return &types.InvalidParameterException{
+			Message: stringPtr("Invalid parameter: Endpoint FIFO topics only deliver to FIFO queues and " +
+				"standard topics to standard queues"),
+		}
+	}
+
+	t.subscriptions = append(t.subscriptions, &subscription{queue: q, raw: cfg.rawMessageDelivery, filter: filter})
+	return nil
+}
+
+// Publish publishes a message to the topic, delivering it to the matching subscriptions
+func (b *Broker) Publish(
+	_ context.Context,
+	params *sns.PublishInput,
+	_ ...func(*sns.Options),
+) (*sns.PublishOutput, error) {
+	if params == nil {
+		return nil, &types.InvalidParameterException{Message: stringPtr("Invalid parameter: TopicArn")}
+	}
+
+	arn := params.TopicArn
+	if arn == nil {
+		arn = params.TargetArn
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, err := b.topic(arn)
+	if err != nil {
+		return nil, err
+	}
+
+	id, seq, err := b.publish(t, publishEntry{
+		message:          deref(params.Message),
+		messageStructure: deref(params.MessageStructure),
+		subject:          deref(params.Subject),
+		attributes:       params.MessageAttributes,
+		groupID:          deref(params.MessageGroupId),
+		deduplicationID:  deref(params.MessageDeduplicationId),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sns.PublishOutput{MessageId: stringPtr(id), SequenceNumber: optional(seq)}, nil
+}
+
+// PublishBatch publishes up to ten messages to the topic, the entries fail individually
+func (b *Broker) PublishBatch(
+	_ context.Context,
+	params *sns.PublishBatchInput,
+	_ ...func(*sns.Options),
+) (*sns.PublishBatchOutput, error) {
+	if params == nil {
+		return nil, &types.InvalidParameterException{Message: stringPtr("Invalid parameter: TopicArn")}
+	}
+	if err := validatePublishBatch(params.PublishBatchRequestEntries); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, err := b.topic(params.TopicArn)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &sns.PublishBatchOutput{}
+	for _, e := range params.PublishBatchRequestEntries {
+		id, seq, err := b.publish(t, publishEntry{
+			message:          deref(e.Message),
+			messageStructure: deref(e.MessageStructure),
+			subject:          deref(e.Subject),
+			attributes:       e.MessageAttributes,
+			groupID:          deref(e.MessageGroupId),
+			deduplicationID:  deref(e.MessageDeduplicationId),
+		})
+		if err != nil {
+			code := "InternalError"
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) {
+				code = apiErr.ErrorCode()
+			}
+			out.Failed = append(out.Failed, types.BatchResultErrorEntry{
+				Id:          e.Id,
+				Code:        stringPtr(code),
+				Message:     stringPtr(err.Error()),
+				SenderFault: true,
+			})
+			continue
+		}
+
+		out.Successful = append(out.Successful, types.PublishBatchResultEntry{
+			Id:             e.Id,
+			MessageId:      stringPtr(id),
+			SequenceNumber: optional(seq),
+		})
+	}
+	return out, nil
+}
+
+// topic returns the topic with the arn, b.mu must be held
+func (b *Broker) topic(arn *string) (*topic, error) {
+	if arn != nil {
+		if t, ok := b.topics[*arn]; ok {
+			return t, nil
+		}
+	}
+	return nil, &types.NotFoundException{Message: stringPtr("Topic does not exist")}
+}
+
+// publish validates the entry and delivers it to the subscriptions whose filter policy matches, b.mu must be held.
+// A FIFO duplicate is not delivered, the message and sequence number of the original are returned.
+// As on SNS, a delivery failing, because the queue rejects the message, does not fail the publish.
+func (b *Broker) publish(t *topic, e publishEntry) (messageID, sequenceNumber string, err error) {
+	message, err := validatePublishEntry(t, &e)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := b.now()
+	if t.fifo {
+		if e.deduplicationID == "" {
+			sum := sha256.Sum256([]byte(e.message))
+			e.deduplicationID = hex.EncodeToString(sum[:])
+		}
+		if r, ok := t.dedup[e.deduplicationID]; ok && r.expiresAt.After(now) {
+			return r.messageID, r.sequenceNumber, nil
+		}
+	}
+
+	messageID = newID()
+	if t.fifo {
+		sequenceNumber = b.nextSequenceNumber()
+		for id, r := range t.dedup {
+			if !r.expiresAt.After(now) {
+				delete(t.dedup, id)
+			}
+		}
+		t.dedup[e.deduplicationID] = dedupRecord{
+			messageID:      messageID,
+			sequenceNumber: sequenceNumber,
+			expiresAt:      now.Add(deduplicationInterval),
+		}
+	}
+
+	for _, s := range t.subscriptions {
+		if !s.filter.match(e.attributes) {
+			continue
+		}
+
+		entry := sendEntry{groupID: e.groupID, deduplicationID: e.deduplicationID}
+		if !t.fifo {
+			entry.deduplicationID = ""
+		}
+
+		if s.raw {
+			entry.body = message
+			entry.attributes = queueAttributes(e.attributes)
+		} else {
+			body, _ := json.Marshal(envelope{
+				Type:              "Notification",
+				MessageID:         messageID,
+				SequenceNumber:    sequenceNumber,
+				TopicArn:          t.arn,
+				Subject:           e.subject,
+				Message:           message,
+				Timestamp:         now.UTC().Format("2006-01-02T15:04:05.000Z"),
+				SignatureVersion:  "1",
+				SigningCertURL:    fmt.Sprintf("https://sns.%s.amazonaws.com/SimpleNotificationService.pem", b.region),
+				UnsubscribeURL:    fmt.Sprintf("https://sns.%s.amazonaws.com/?Action=Unsubscribe", b.region),
+				MessageAttributes: envelopeAttributes(e.attributes),
+			})
+			entry.body = string(body)
+		}
+
+		_, _, _ = b.send(s.queue, entry)
+	}
+	return messageID, sequenceNumber, nil
+}
+
+// validatePublishEntry validates the entry and returns the message delivered to the queues,
+// the sqs or default one of a json message structure.
+func validatePublishEntry(t *topic, e *publishEntry) (string, error) {
+	if e.message == "" {
+		return "", invalidParameter("Empty message")
+	}
+	if len(e.attributes) > maxAttributes {
+		return "", invalidParameter(fmt.Sprintf("Number of message attributes [%d] exceeds the allowed maximum [%d].",
+			len(e.attributes), maxAttributes))
+	}
+	for k, v := range e.attributes {
+		if deref(v.DataType) == "" || (v.StringValue == nil && v.BinaryValue == nil) {
+			return "", &types.InvalidParameterValueException{
+				Message: stringPtr(fmt.Sprintf("The message attribute '%s' must contain a non-empty value and type.", k)),
+			}
+		}
+	}
+
+	if t.fifo {
+		if e.groupID == "" {
+			return "", invalidParameter("The MessageGroupId parameter is required for FIFO topics")
+		}
+		if e.deduplicationID == "" && !t.config.contentBasedDeduplication {
+			return "", invalidParameter("The topic should either have ContentBasedDeduplication enabled " +
+				"or MessageDeduplicationId provided explicitly")
+		}
+	} else if e.deduplicationID != "" {
+		return "", invalidParameter("The request includes MessageDeduplicationId parameter that is not valid " +
+			"for this topic type")
+	}
+
+	if e.messageStructure == "" {
+		return e.message, nil
+	}
+	if e.messageStructure != "json" {
+		return "", invalidParameter("Invalid parameter: MessageStructure")
+	}
+
+	var structure map[string]string
+	if err := json.Unmarshal([]byte(e.message), &structure); err != nil {
+		return "", invalidParameter("Invalid parameter: Message Structure - JSON message body failed to parse")
+	}
+	if _, ok := structure["default"]; !ok {
+		return "", invalidParameter("Invalid parameter: Message Structure - No default entry in JSON message body")
+	}
+	if m, ok := structure["sqs"]; ok {
+		return m, nil
+	}
+	return structure["default"], nil
+}
+
+func validatePublishBatch(entries []types.PublishBatchRequestEntry) error {
+	if len(entries) == 0 {
+		return &types.EmptyBatchRequestException{Message: stringPtr("The batch request doesn't contain any entries")}
+	}
+	if len(entries) > maxBatchEntries {
+		return &types.TooManyEntriesInBatchRequestException{
+			Message: stringPtr(fmt.Sprintf("The batch request contains more entries than permissible (%d)", maxBatchEntries)),
+		}
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		id := deref(e.Id)
+		if !batchEntryID.MatchString(id) {
+			return &types.InvalidBatchEntryIdException{Message: stringPtr("The Id of a batch entry in a batch request " +
+				"doesn't abide by the specification")}
+		}
+		if seen[id] {
+			return &types.BatchEntryIdsNotDistinctException{Message: stringPtr("Two or more batch entries in the " +
+				"request have the same Id")}
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// queueAttributes converts the published attributes into the attributes of a raw delivery
+func queueAttributes(attributes map[string]types.MessageAttributeValue) map[string]sqstypes.MessageAttributeValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	out := make(map[string]sqstypes.MessageAttributeValue, len(attributes))
+	for k, v := range attributes {
+		out[k] = sqstypes.MessageAttributeValue{
+			DataType:    v.DataType,
+			StringValue: v.StringValue,
+			BinaryValue: v.BinaryValue,
+		}
+	}
+	return out
+}
+
+// envelopeAttributes converts the published attributes into the envelope ones, Binary values are base64 encoded
+func envelopeAttributes(attributes map[string]types.MessageAttributeValue) map[string]envelopeAttribute {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	out := make(map[string]envelopeAttribute, len(attributes))
+	for k, v := range attributes {
+		value := deref(v.StringValue)
+		if v.BinaryValue != nil {
+			value = base64.StdEncoding.EncodeToString(v.BinaryValue)
+		}
+		out[k] = envelopeAttribute{Type: deref(v.DataType), Value: value}
+	}
+	return out
+}
+
+func invalidParameter(message string) error {
+	return &types.InvalidParameterException{Message: stringPtr(message)}
+}
@@ -0,0 +1,200 @@
+package loafertest_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/loafertest"
+)
+
+var _ loafergo.SNSClient = (*loafertest.Broker)(nil)
+
+func stringAttribute(v string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+}
+
+func TestBroker_Publish(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should deliver the notification envelope", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		arn := b.CreateTopic("events")
+		url := b.CreateQueue("orders")
+		assert.NoError(t, b.Subscribe(arn, url))
+
+		out, err := b.Publish(ctx, &sns.PublishInput{
+			TopicArn:          aws.String(arn),
+			Message:           aws.String(`{"id":1}`),
+			Subject:           aws.String("created"),
+			MessageAttributes: map[string]types.MessageAttributeValue{"kind": stringAttribute("order")},
+		})
+		assert.NoError(t, err)
+
+		msgs := receive(t, b, url, 1)
+		assert.Len(t, msgs, 1)
+		assert.Empty(t, msgs[0].MessageAttributes)
+
+		var envelope struct {
+			Type              string
+			MessageID         string `json:"MessageId"`
+			TopicArn          string
+			Subject           string
+			Message           string
+			Timestamp         string
+			MessageAttributes map[string]struct{ Type, Value string }
+		}
+		assert.NoError(t, json.Unmarshal([]byte(aws.ToString(msgs[0].Body)), &envelope))
+		assert.Equal(t, "Notification", envelope.Type)
+		assert.Equal(t, aws.ToString(out.MessageId), envelope.MessageID)
+		assert.Equal(t, arn, envelope.TopicArn)
+		assert.Equal(t, "created", envelope.Subject)
+		assert.Equal(t, `{"id":1}`, envelope.Message)
+		assert.NotEmpty(t, envelope.Timestamp)
+		assert.Equal(t, "String", envelope.MessageAttributes["kind"].Type)
+		assert.Equal(t, "order", envelope.MessageAttributes["kind"].Value)
+	})
+
+	t.Run("Should deliver the raw message with its attributes", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		arn := b.CreateTopic("events")
+		url := b.CreateQueue("orders")
+		assert.NoError(t, b.Subscribe(arn, url, loafertest.SubscriptionWithRawMessageDelivery()))
+
+		_, err := b.Publish(ctx, &sns.PublishInput{
+			TopicArn:          aws.String(arn),
+			Message:           aws.String("hello"),
+			MessageAttributes: map[string]types.MessageAttributeValue{"kind": stringAttribute("order")},
+		})
+		assert.NoError(t, err)
+
+		msgs := receive(t, b, url, 1)
+		assert.Equal(t, []string{"hello"}, bodies(msgs))
+		assert.Equal(t, "order", aws.ToString(msgs[0].MessageAttributes["kind"].StringValue))
+	})
+
+	t.Run("Should fan out to the subscriptions matching their filter policy", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		arn := b.CreateTopic("events")
+		orders := b.CreateQueue("orders")
+		refunds := b.CreateQueue("refunds")
+		all := b.CreateQueue("all")
+		assert.NoError(t, b.Subscribe(arn, orders, loafertest.SubscriptionWithFilterPolicy(`{"kind":["order"]}`)))
+		assert.NoError(t, b.Subscribe(arn, refunds, loafertest.SubscriptionWithFilterPolicy(`{"kind":[{"prefix":"refund"}]}`)))
+		assert.NoError(t, b.Subscribe(arn, all))
+
+		for _, kind := range []string{"order", "refund.partial", "other"} {
+			_, err := b.Publish(ctx, &sns.PublishInput{
+				TopicArn:          aws.String(arn),
+				Message:           aws.String(kind),
+				MessageAttributes: map[string]types.MessageAttributeValue{"kind": stringAttribute(kind)},
+			})
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, 1, b.Len("orders"))
+		assert.Equal(t, 1, b.Len("refunds"))
+		assert.Equal(t, 3, b.Len("all"))
+	})
+
+	t.Run("Should deliver the sqs entry of a json message structure", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		arn := b.CreateTopic("events")
+		url := b.CreateQueue("orders")
+		assert.NoError(t, b.Subscribe(arn, url, loafertest.SubscriptionWithRawMessageDelivery()))
+
+		_, err := b.Publish(ctx, &sns.PublishInput{
+			TopicArn:         aws.String(arn),
+			Message:          aws.String(`{"default":"d","sqs":"s"}`),
+			MessageStructure: aws.String("json"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"s"}, b.Bodies("orders"))
+
+		_, err = b.Publish(ctx, &sns.PublishInput{
+			TopicArn:         aws.String(arn),
+			Message:          aws.String(`{"sqs":"s"}`),
+			MessageStructure: aws.String("json"),
+		})
+		var invalid *types.InvalidParameterException
+		assert.ErrorAs(t, err, &invalid)
+	})
+
+	t.Run("Should fail for an unknown topic", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		_, err := b.Publish(ctx, &sns.PublishInput{TopicArn: aws.String("arn:aws:sns:us-east-1:000000000000:x"),
+			Message: aws.String("hello")})
+		var notFound *types.NotFoundException
+		assert.ErrorAs(t, err, &notFound)
+	})
+}
+
+func TestBroker_PublishFIFO(t *testing.T) {
+	ctx := context.Background()
+
+	b := loafertest.NewBroker()
+	arn := b.CreateTopic("events.fifo")
+	url := b.CreateQueue("orders.fifo")
+	assert.ErrorAs(t, b.Subscribe(arn, b.CreateQueue("standard")), new(*types.InvalidParameterException))
+	assert.NoError(t, b.Subscribe(arn, url, loafertest.SubscriptionWithRawMessageDelivery()))
+
+	input := func(body, dedup string) *sns.PublishInput {
+		return &sns.PublishInput{
+			TopicArn:               aws.String(arn),
+			Message:                aws.String(body),
+			MessageGroupId:         aws.String("g"),
+			MessageDeduplicationId: aws.String(dedup),
+		}
+	}
+
+	first, err := b.Publish(ctx, input("1", "a"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, aws.ToString(first.SequenceNumber))
+
+	duplicate, err := b.Publish(ctx, input("1", "a"))
+	assert.NoError(t, err)
+	assert.Equal(t, aws.ToString(first.MessageId), aws.ToString(duplicate.MessageId))
+
+	_, err = b.Publish(ctx, input("2", "b"))
+	assert.NoError(t, err)
+
+	msgs := receive(t, b, url, 10)
+	assert.Equal(t, []string{"1", "2"}, bodies(msgs))
+	assert.Equal(t, "g", msgs[0].Attributes["MessageGroupId"])
+	assert.Equal(t, "a", msgs[0].Attributes["MessageDeduplicationId"])
+
+	_, err = b.Publish(ctx, &sns.PublishInput{TopicArn: aws.String(arn), Message: aws.String("x")})
+	assert.ErrorAs(t, err, new(*types.InvalidParameterException))
+}
+
+func TestBroker_PublishBatch(t *testing.T) {
+	ctx := context.Background()
+	b := loafertest.NewBroker()
+	arn := b.CreateTopic("events")
+	url := b.CreateQueue("orders")
+	assert.NoError(t, b.Subscribe(arn, url, loafertest.SubscriptionWithRawMessageDelivery()))
+
+	out, err := b.PublishBatch(ctx, &sns.PublishBatchInput{
+		TopicArn: aws.String(arn),
+		PublishBatchRequestEntries: []types.PublishBatchRequestEntry{
+			{Id: aws.String("1"), Message: aws.String("one")},
+			{Id: aws.String("2")},
+			{Id: aws.String("3"), Message: aws.String("three")},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, out.Successful, 2)
+	assert.Len(t, out.Failed, 1)
+	assert.Equal(t, "2", aws.ToString(out.Failed[0].Id))
+	assert.Equal(t, "InvalidParameter", aws.ToString(out.Failed[0].Code))
+	assert.Equal(t, []string{"one", "three"}, b.Bodies("orders"))
+
+	_, err = b.PublishBatch(ctx, &sns.PublishBatchInput{TopicArn: aws.String(arn)})
+	assert.ErrorAs(t, err, new(*types.EmptyBatchRequestException))
+}
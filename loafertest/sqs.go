@@ -0,0 +1,585 @@
+package loafertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	maxBatchEntries    = 10
+	maxAttributes      = 10
+	maxMessageSize     = 256 * 1024
+	maxDelaySeconds    = 900
+	maxWaitTimeSeconds = 20
+	maxVisibility      = 12 * time.Hour
+	allAttributes      = "All"
+)
+
+var batchEntryID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,80}$`)
+
+// GetQueueUrl returns the URL of a queue created with CreateQueue
+//
+//nolint:revive // the name is set by the SQS API
+func (b *Broker) GetQueueUrl(
+	_ context.Context,
+	params *sqs.GetQueueUrlInput,
+	_ ...func(*sqs.Options),
+) (*sqs.GetQueueUrlOutput, error) {
+	if params == nil || params.QueueName == nil {
+		return nil, missingParameter("QueueName")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[*params.QueueName]
+	if !ok {
+		return nil, &types.QueueDoesNotExist{Message: stringPtr("The specified queue does not exist.")}
+	}
+	return &sqs.GetQueueUrlOutput{QueueUrl: stringPtr(q.url)}, nil
+}
+
+// SendMessage enqueues a message, validated as SQS does
+func (b *Broker) SendMessage(
+	_ context.Context,
+	params *sqs.SendMessageInput,
+	_ ...func(*sqs.Options),
+) (*sqs.SendMessageOutput, error) {
+	if params == nil {
+		return nil, missingParameter("QueueUrl")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, err := b.queueByURL(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	id, seq, err := b.send(q, sendEntry{
+		body:            deref(params.MessageBody),
+		attributes:      params.MessageAttributes,
+		groupID:         deref(params.MessageGroupId),
+		deduplicationID: deref(params.MessageDeduplicationId),
+		delaySeconds:    params.DelaySeconds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqs.SendMessageOutput{
+		MessageId:        stringPtr(id),
+		MD5OfMessageBody: stringPtr(md5Hex(deref(params.MessageBody))),
+		SequenceNumber:   optional(seq),
+	}, nil
+}
+
+// SendMessageBatch enqueues up to ten messages, the entries fail individually
+func (b *Broker) SendMessageBatch(
+	_ context.Context,
+	params *sqs.SendMessageBatchInput,
+	_ ...func(*sqs.Options),
+) (*sqs.SendMessageBatchOutput, error) {
+	if params == nil {
+		return nil, missingParameter("QueueUrl")
+	}
+
+	ids := make([]*string, len(params.Entries))
+	var size int
+	for i, e := range params.Entries {
+		ids[i] = e.Id
+		size += len(deref(e.MessageBody)) + attributesSize(e.MessageAttributes)
+	}
+	if err := validateBatch(ids); err != nil {
+		return nil, err
+	}
+	if size > maxMessageSize {
+		return nil, &types.BatchRequestTooLong{
+			Message: stringPtr(fmt.Sprintf("Batch requests cannot be longer than %d bytes.", maxMessageSize)),
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, err := b.queueByURL(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &sqs.SendMessageBatchOutput{}
+	for _, e := range params.Entries {
+		id, seq, err := b.send(q, sendEntry{
+			body:            deref(e.MessageBody),
+			attributes:      e.MessageAttributes,
+			groupID:         deref(e.MessageGroupId),
+			deduplicationID: deref(e.MessageDeduplicationId),
+			delaySeconds:    e.DelaySeconds,
+		})
+		if err != nil {
+			out.Failed = append(out.Failed, batchError(e.Id, err))
+			continue
+		}
+
+		out.Successful = append(out.Successful, types.SendMessageBatchResultEntry{
+			Id:               e.Id,
+			MessageId:        stringPtr(id),
+			MD5OfMessageBody: stringPtr(md5Hex(deref(e.MessageBody))),
+			SequenceNumber:   optional(seq),
+		})
+	}
+	return out, nil
+}
+
+// ReceiveMessage receives up to MaxNumberOfMessages visible messages, hiding them for the
+// visibility timeout. When none is visible, it waits up to WaitTimeSeconds for one (long polling).
+func (b *Broker) ReceiveMessage(
+	ctx context.Context,
+	params *sqs.ReceiveMessageInput,
+	_ ...func(*sqs.Options),
+) (*sqs.ReceiveMessageOutput, error) {
+	if params == nil {
+		return nil, missingParameter("QueueUrl")
+	}
+
+	maxMessages := int(params.MaxNumberOfMessages)
+	if maxMessages == 0 {
+		maxMessages = 1
+	}
+	if maxMessages < 1 || maxMessages > maxBatchEntries {
+		return nil, invalidParameterValue("MaxNumberOfMessages", strconv.Itoa(maxMessages), "Must be between 1 and 10")
+	}
+	if params.WaitTimeSeconds < 0 || params.WaitTimeSeconds > maxWaitTimeSeconds {
+		return nil, invalidParameterValue("WaitTimeSeconds", strconv.Itoa(int(params.WaitTimeSeconds)),
+			"Must be between 0 and 20")
+	}
+	if params.VisibilityTimeout < 0 || time.Duration(params.VisibilityTimeout)*time.Second > maxVisibility {
+		return nil, invalidParameterValue("VisibilityTimeout", strconv.Itoa(int(params.VisibilityTimeout)),
+			"Must be between 0 and 43200")
+	}
+
+	var deadline time.Time
+	for {
+		b.mu.Lock()
+		q, err := b.queueByURL(params.QueueUrl)
+		if err != nil {
+			b.mu.Unlock()
+			return nil, err
+		}
+
+		if deadline.IsZero() {
+			wait := q.config.receiveWaitTime
+			if params.WaitTimeSeconds > 0 {
+				wait = time.Duration(params.WaitTimeSeconds) * time.Second
+			}
+			deadline = time.Now().Add(wait)
+		}
+
+		visibility := q.config.visibilityTimeout
+		if params.VisibilityTimeout > 0 {
+			visibility = time.Duration(params.VisibilityTimeout) * time.Second
+		}
+
+		received := b.receive(q, maxMessages, visibility)
+		if len(received) > 0 {
+			out := &sqs.ReceiveMessageOutput{Messages: make([]types.Message, len(received))}
+			for i, m := range received {
+				out.Messages[i] = b.output(q, m, params)
+			}
+			b.mu.Unlock()
+			return out, nil
+		}
+
+		changed, next := b.changed, b.nextVisibleIn(q)
+		b.mu.Unlock()
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return &sqs.ReceiveMessageOutput{}, nil
+		}
+		if next > 0 && next < wait {
+			wait = next
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// DeleteMessage deletes the message received with the receipt handle
+func (b *Broker) DeleteMessage(
+	_ context.Context,
+	params *sqs.DeleteMessageInput,
+	_ ...func(*sqs.Options),
+) (*sqs.DeleteMessageOutput, error) {
+	if params == nil {
+		return nil, missingParameter("QueueUrl")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, err := b.queueByURL(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.delete(q, deref(params.ReceiptHandle)); err != nil {
+		return nil, err
+	}
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+// DeleteMessageBatch deletes up to ten messages, the entries fail individually
+func (b *Broker) DeleteMessageBatch(
+	_ context.Context,
+	params *sqs.DeleteMessageBatchInput,
+	_ ...func(*sqs.Options),
+) (*sqs.DeleteMessageBatchOutput, error) {
+	if params == nil {
+		return nil, missingParameter("QueueUrl")
+	}
+
+	ids := make([]*string, len(params.Entries))
+	for i, e := range params.Entries {
+		ids[i] = e.Id
+	}
+	if err := validateBatch(ids); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, err := b.queueByURL(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &sqs.DeleteMessageBatchOutput{}
+	for _, e := range params.Entries {
+		if err := b.delete(q, deref(e.ReceiptHandle)); err != nil {
+			out.Failed = append(out.Failed, batchError(e.Id, err))
+			continue
+		}
+		out.Successful = append(out.Successful, types.DeleteMessageBatchResultEntry{Id: e.Id})
+	}
+	return out, nil
+}
+
+// ChangeMessageVisibility hides the in flight message received with the receipt handle
+// for VisibilityTimeout seconds from now, zero makes it visible again immediately.
+func (b *Broker) ChangeMessageVisibility(
+	_ context.Context,
+	params *sqs.ChangeMessageVisibilityInput,
+	_ ...func(*sqs.Options),
+) (*sqs.ChangeMessageVisibilityOutput, error) {
+	if params == nil {
+		return nil, missingParameter("QueueUrl")
+	}
+
+	timeout := time.Duration(params.VisibilityTimeout) * time.Second
+	if timeout < 0 || timeout > maxVisibility {
+		return nil, invalidParameterValue("VisibilityTimeout", strconv.Itoa(int(params.VisibilityTimeout)),
+			"Must be between 0 and 43200")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, err := b.queueByURL(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.changeVisibility(q, deref(params.ReceiptHandle), timeout); err != nil {
+		return nil, err
+	}
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+// output builds the received message with the system and message attributes requested, b.mu must be held
+func (b *Broker) output(q *queue, m *storedMessage, params *sqs.ReceiveMessageInput) types.Message {
+	system := map[string]string{
+		string(types.MessageSystemAttributeNameSenderId):                         b.accountID,
+		string(types.MessageSystemAttributeNameSentTimestamp):                    millis(m.sentAt),
+		string(types.MessageSystemAttributeNameApproximateReceiveCount):          strconv.Itoa(m.receiveCount),
+		string(types.MessageSystemAttributeNameApproximateFirstReceiveTimestamp): millis(m.firstReceivedAt),
+	}
+	if q.fifo {
+		system[string(types.MessageSystemAttributeNameMessageGroupId)] = m.groupID
+		system[string(types.MessageSystemAttributeNameMessageDeduplicationId)] = m.deduplicationID
+		system[string(types.MessageSystemAttributeNameSequenceNumber)] = m.sequenceNumber
+	}
+
+	names := make([]string, 0, len(params.AttributeNames)+len(params.MessageSystemAttributeNames))
+	for _, n := range params.AttributeNames {
+		names = append(names, string(n))
+	}
+	for _, n := range params.MessageSystemAttributeNames {
+		names = append(names, string(n))
+	}
+
+	out := types.Message{
+		MessageId:     stringPtr(m.id),
+		ReceiptHandle: stringPtr(m.receiptHandle),
+		Body:          stringPtr(m.body),
+		MD5OfBody:     stringPtr(md5Hex(m.body)),
+	}
+
+	for k, v := range system {
+		if requested(names, k) {
+			if out.Attributes == nil {
+				out.Attributes = make(map[string]string)
+			}
+			out.Attributes[k] = v
+		}
+	}
+
+	for k, v := range m.attributes {
+		if requested(params.MessageAttributeNames, k) {
+			if out.MessageAttributes == nil {
+				out.MessageAttributes = make(map[string]types.MessageAttributeValue)
+			}
+			out.MessageAttributes[k] = v
+		}
+	}
+	return out
+}
+
+// requested reports whether the attribute is selected by the names of a receive call:
+// All or .* select all of them, a name ending with .* selects a prefix.
+func requested(names []string, attribute string) bool {
+	for _, n := range names {
+		switch {
+		case n == allAttributes || n == ".*" || n == attribute:
+			return true
+		case strings.HasSuffix(n, ".*") && strings.HasPrefix(attribute, strings.TrimSuffix(n, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+func validateSendEntry(q *queue, e *sendEntry) error {
+	if e.body == "" {
+		return missingParameter("MessageBody")
+	}
+	if size := len(e.body) + attributesSize(e.attributes); size > maxMessageSize {
+		return invalidParameterValue("MessageBody", "", fmt.Sprintf("Message must be shorter than %d bytes.", maxMessageSize))
+	}
+	if len(e.attributes) > maxAttributes {
+		return invalidParameterValue("MessageAttributes", "", fmt.Sprintf(
+			"Number of message attributes [%d] exceeds the allowed maximum [%d].", len(e.attributes), maxAttributes))
+	}
+	for k, v := range e.attributes {
+		if deref(v.DataType) == "" {
+			return invalidParameterValue("MessageAttributes", k, "The message attribute must have a data type.")
+		}
+		if v.StringValue == nil && v.BinaryValue == nil {
+			return invalidParameterValue("MessageAttributes", k, "The message attribute must have a value.")
+		}
+	}
+	if e.delaySeconds < 0 || e.delaySeconds > maxDelaySeconds {
+		return invalidParameterValue("DelaySeconds", strconv.Itoa(int(e.delaySeconds)), "Must be between 0 and 900")
+	}
+
+	if !q.fifo {
+		if e.deduplicationID != "" {
+			return invalidParameterValue("MessageDeduplicationId", e.deduplicationID,
+				"The request includes a parameter that is not valid for this queue type.")
+		}
+		return nil
+	}
+
+	if e.groupID == "" {
+		return missingParameter("MessageGroupId")
+	}
+	if e.delaySeconds != 0 {
+		return invalidParameterValue("DelaySeconds", strconv.Itoa(int(e.delaySeconds)),
+			"The request includes a parameter that is not valid for this queue type.")
+	}
+	if e.deduplicationID == "" && !q.config.contentBasedDeduplication {
+		return invalidParameterValue("MessageDeduplicationId", "",
+			"The queue should either have ContentBasedDeduplication enabled or MessageDeduplicationId provided explicitly")
+	}
+	return nil
+}
+
+func validateBatch(ids []*string) error {
+	if len(ids) == 0 {
+		return &types.EmptyBatchRequest{Message: stringPtr("There should be at least one entry in the request.")}
+	}
+	if len(ids) > maxBatchEntries {
+		return &types.TooManyEntriesInBatchRequest{
+			Message: stringPtr(fmt.Sprintf("Maximum number of entries per request are %d.", maxBatchEntries)),
+		}
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !batchEntryID.MatchString(deref(id)) {
+			return &types.InvalidBatchEntryId{Message: stringPtr("A batch entry id can only contain alphanumeric " +
+				"characters, hyphens and underscores. It can be at most 80 letters long.")}
+		}
+		if seen[*id] {
+			return &types.BatchEntryIdsNotDistinct{Message: stringPtr(fmt.Sprintf("Id %s repeated.", *id))}
+		}
+		seen[*id] = true
+	}
+	return nil
+}
+
+func batchError(id *string, err error) types.BatchResultErrorEntry {
+	code := "InternalError"
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code = apiErr.ErrorCode()
+	}
+
+	return types.BatchResultErrorEntry{
+		Id:          id,
+		Code:        stringPtr(code),
+		Message:     stringPtr(err.Error()),
+		SenderFault: true,
+	}
+}
+
+func attributesSize(attributes map[string]types.MessageAttributeValue) int {
+	var size int
+	for k, v := range attributes {
+		size += len(k) + len(deref(v.DataType)) + len(deref(v.StringValue)) + len(v.BinaryValue)
+	}
+	return size
+}
+
+func missingParameter(name string) error {
+	return &smithy.GenericAPIError{
+		Code:    "MissingParameter",
+		Message: fmt.Sprintf("The request must contain the parameter %s.", name),
+		Fault:   smithy.FaultClient,
+	}
+}
+
+func invalidParameterValue(name, value, reason string) error {
+	return &smithy.GenericAPIError{
+		Code:    "InvalidParameterValue",
+		Message: fmt.Sprintf("Value %s for parameter %s is invalid. Reason: %s", value, name, reason),
+		Fault:   smithy.FaultClient,
+	}
+}
+
+func millis(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// optional returns nil for the empty string, as the AWS outputs omit the fields they do not set
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// GetQueueAttributes returns the attributes of the queue, the approximate message counts included.
+// The names are the ones of types.QueueAttributeName, All returning every attribute the broker supports.
+func (b *Broker) GetQueueAttributes(
+	_ context.Context,
+	params *sqs.GetQueueAttributesInput,
+	_ ...func(*sqs.Options),
+) (*sqs.GetQueueAttributesOutput, error) {
+	if params == nil {
+		return nil, missingParameter("QueueUrl")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, err := b.queueByURL(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := b.queueAttributes(q)
+	out := &sqs.GetQueueAttributesOutput{Attributes: make(map[string]string)}
+	for _, name := range params.AttributeNames {
+		if name == types.QueueAttributeNameAll {
+			out.Attributes = attributes
+			continue
+		}
+		v, ok := attributes[string(name)]
+		if !ok && !slices.Contains(name.Values(), name) {
+			return nil, &types.InvalidAttributeName{Message: stringPtr("Unknown Attribute " + string(name) + ".")}
+		}
+		if ok {
+			out.Attributes[string(name)] = v
+		}
+	}
+	return out, nil
+}
+
+// queueAttributes returns the attributes of the queue by name, b.mu must be held
+func (b *Broker) queueAttributes(q *queue) map[string]string {
+	now := b.now()
+	var visible, inFlight, delayed int
+	for _, m := range q.messages {
+		switch {
+		case m.inFlight(now):
+			inFlight++
+		case m.visibleAt.After(now):
+			delayed++
+		default:
+			visible++
+		}
+	}
+
+	seconds := func(d time.Duration) string {
+		return strconv.Itoa(int(d.Seconds()))
+	}
+	attributes := map[string]string{
+		string(types.QueueAttributeNameApproximateNumberOfMessages):           strconv.Itoa(visible),
+		string(types.QueueAttributeNameApproximateNumberOfMessagesNotVisible): strconv.Itoa(inFlight),
+		string(types.QueueAttributeNameApproximateNumberOfMessagesDelayed):    strconv.Itoa(delayed),
+		string(types.QueueAttributeNameQueueArn):                              q.arn,
+		string(types.QueueAttributeNameVisibilityTimeout):                     seconds(q.config.visibilityTimeout),
+		string(types.QueueAttributeNameDelaySeconds):                          seconds(q.config.delay),
+		string(types.QueueAttributeNameReceiveMessageWaitTimeSeconds):         seconds(q.config.receiveWaitTime),
+	}
+	if q.fifo {
+		attributes[string(types.QueueAttributeNameFifoQueue)] = "true"
+		attributes[string(types.QueueAttributeNameContentBasedDeduplication)] = strconv.FormatBool(q.config.contentBasedDeduplication)
+	}
+	if dlq, ok := b.queues[q.config.deadLetterQueue]; ok {
+		attributes[string(types.QueueAttributeNameRedrivePolicy)] = fmt.Sprintf(
+			`{"deadLetterTargetArn":%q,"maxReceiveCount":%d}`, dlq.arn, q.config.maxReceiveCount)
+	}
+	return attributes
+}
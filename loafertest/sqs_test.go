@@ -0,0 +1,422 @@
+package loafertest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/loafertest"
+)
+
+var _ loafergo.SQSClient = (*loafertest.Broker)(nil)
+
+func send(t *testing.T, b *loafertest.Broker, url string, input *sqs.SendMessageInput) string {
+	input.QueueUrl = aws.String(url)
+	out, err := b.SendMessage(context.Background(), input)
+	assert.NoError(t, err)
+	return aws.ToString(out.MessageId)
+}
+
+func receive(t *testing.T, b *loafertest.Broker, url string, max int32) []types.Message {
+	out, err := b.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+		QueueUrl:                    aws.String(url),
+		MaxNumberOfMessages:         max,
+		MessageAttributeNames:       []string{"All"},
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
+	})
+	assert.NoError(t, err)
+	return out.Messages
+}
+
+func bodies(msgs []types.Message) []string {
+	out := make([]string, len(msgs))
+	for i, m := range msgs {
+		out[i] = aws.ToString(m.Body)
+	}
+	return out
+}
+
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
+
+func TestBroker_GetQueueUrl(t *testing.T) {
+	b := loafertest.NewBroker(loafertest.WithRegion("eu-west-1"), loafertest.WithAccountID("123456789012"))
+	url := b.CreateQueue("orders")
+	assert.Equal(t, "https://sqs.eu-west-1.amazonaws.com/123456789012/orders", url)
+	assert.Equal(t, url, b.CreateQueue("orders"))
+
+	out, err := b.GetQueueUrl(context.Background(), &sqs.GetQueueUrlInput{QueueName: aws.String("orders")})
+	assert.NoError(t, err)
+	assert.Equal(t, url, aws.ToString(out.QueueUrl))
+
+	_, err = b.GetQueueUrl(context.Background(), &sqs.GetQueueUrlInput{QueueName: aws.String("missing")})
+	var notFound *types.QueueDoesNotExist
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestBroker_SendReceiveDelete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should receive the message with its attributes and delete it", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders")
+		id := send(t, b, url, &sqs.SendMessageInput{
+			MessageBody: aws.String("hello"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"kind": {DataType: aws.String("String"), StringValue: aws.String("order")},
+			},
+		})
+
+		msgs := receive(t, b, url, 10)
+		assert.Len(t, msgs, 1)
+		assert.Equal(t, id, aws.ToString(msgs[0].MessageId))
+		assert.Equal(t, "hello", aws.ToString(msgs[0].Body))
+		assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", aws.ToString(msgs[0].MD5OfBody))
+		assert.Equal(t, "order", aws.ToString(msgs[0].MessageAttributes["kind"].StringValue))
+		assert.Equal(t, "1", msgs[0].Attributes["ApproximateReceiveCount"])
+		assert.NotEmpty(t, msgs[0].Attributes["SentTimestamp"])
+		assert.Equal(t, 1, b.InFlight("orders"))
+
+		assert.Empty(t, receive(t, b, url, 10), "an in flight message is not received again")
+
+		_, err := b.DeleteMessage(ctx, &sqs.DeleteMessageInput{QueueUrl: aws.String(url), ReceiptHandle: msgs[0].ReceiptHandle})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, b.Len("orders"))
+
+		_, err = b.DeleteMessage(ctx, &sqs.DeleteMessageInput{QueueUrl: aws.String(url), ReceiptHandle: msgs[0].ReceiptHandle})
+		assert.NoError(t, err, "deleting a deleted message succeeds")
+	})
+
+	t.Run("Should only return the requested attributes", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders")
+		send(t, b, url, &sqs.SendMessageInput{
+			MessageBody: aws.String("hello"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"trace.id":   {DataType: aws.String("String"), StringValue: aws.String("1")},
+				"trace.span": {DataType: aws.String("String"), StringValue: aws.String("2")},
+				"kind":       {DataType: aws.String("String"), StringValue: aws.String("order")},
+			},
+		})
+
+		out, err := b.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:                    aws.String(url),
+			MessageAttributeNames:       []string{"trace.*"},
+			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameApproximateReceiveCount},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, out.Messages[0].MessageAttributes, 2)
+		assert.Contains(t, out.Messages[0].MessageAttributes, "trace.id")
+		assert.Equal(t, map[string]string{"ApproximateReceiveCount": "1"}, out.Messages[0].Attributes)
+	})
+
+	t.Run("Should reject the invalid messages", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders")
+
+		_, err := b.SendMessage(ctx, &sqs.SendMessageInput{QueueUrl: aws.String(url)})
+		assert.Equal(t, "MissingParameter", errorCode(err))
+
+		_, err = b.SendMessage(ctx, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("x"),
+			MessageDeduplicationId: aws.String("d")})
+		assert.Equal(t, "InvalidParameterValue", errorCode(err))
+
+		attributes := make(map[string]types.MessageAttributeValue)
+		for _, k := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"} {
+			attributes[k] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(k)}
+		}
+		_, err = b.SendMessage(ctx, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("x"),
+			MessageAttributes: attributes})
+		assert.Equal(t, "InvalidParameterValue", errorCode(err))
+
+		_, err = b.SendMessage(ctx, &sqs.SendMessageInput{QueueUrl: aws.String(url + "-missing"), MessageBody: aws.String("x")})
+		var notFound *types.QueueDoesNotExist
+		assert.ErrorAs(t, err, &notFound)
+	})
+
+	t.Run("Should delay the messages", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders")
+		send(t, b, url, &sqs.SendMessageInput{MessageBody: aws.String("later"), DelaySeconds: 60})
+
+		assert.Empty(t, receive(t, b, url, 1))
+		b.Advance(time.Minute)
+		assert.Equal(t, []string{"later"}, bodies(receive(t, b, url, 1)))
+	})
+}
+
+func TestBroker_GetQueueAttributes(t *testing.T) {
+	ctx := context.Background()
+	b := loafertest.NewBroker()
+	b.CreateQueue("orders-dlq")
+	url := b.CreateQueue("orders", loafertest.QueueWithVisibilityTimeout(time.Minute), loafertest.QueueWithRedrive("orders-dlq", 3))
+
+	for _, body := range []string{"a", "b", "c"} {
+		send(t, b, url, &sqs.SendMessageInput{MessageBody: aws.String(body)})
+	}
+	send(t, b, url, &sqs.SendMessageInput{MessageBody: aws.String("later"), DelaySeconds: 60})
+	receive(t, b, url, 1)
+
+	out, err := b.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl: aws.String(url),
+		AttributeNames: []types.QueueAttributeName{
+			types.QueueAttributeNameApproximateNumberOfMessages,
+			types.QueueAttributeNameApproximateNumberOfMessagesNotVisible,
+			types.QueueAttributeNameApproximateNumberOfMessagesDelayed,
+			types.QueueAttributeNamePolicy,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ApproximateNumberOfMessages":           "2",
+		"ApproximateNumberOfMessagesNotVisible": "1",
+		"ApproximateNumberOfMessagesDelayed":    "1",
+	}, out.Attributes)
+
+	out, err = b.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(url),
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameAll},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "60", out.Attributes["VisibilityTimeout"])
+	assert.Equal(t, `{"deadLetterTargetArn":"arn:aws:sqs:us-east-1:000000000000:orders-dlq","maxReceiveCount":3}`,
+		out.Attributes["RedrivePolicy"])
+	assert.NotContains(t, out.Attributes, "FifoQueue")
+
+	_, err = b.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(url),
+		AttributeNames: []types.QueueAttributeName{"Unknown"},
+	})
+	var invalid *types.InvalidAttributeName
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestBroker_Visibility(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should redeliver the message once its visibility timeout expires", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders", loafertest.QueueWithVisibilityTimeout(10*time.Second))
+		send(t, b, url, &sqs.SendMessageInput{MessageBody: aws.String("hello")})
+
+		first := receive(t, b, url, 1)
+		b.Advance(10 * time.Second)
+		second := receive(t, b, url, 1)
+		assert.Len(t, second, 1)
+		assert.Equal(t, "2", second[0].Attributes["ApproximateReceiveCount"])
+		assert.NotEqual(t, aws.ToString(first[0].ReceiptHandle), aws.ToString(second[0].ReceiptHandle))
+
+		_, err := b.DeleteMessage(ctx, &sqs.DeleteMessageInput{QueueUrl: aws.String(url), ReceiptHandle: first[0].ReceiptHandle})
+		var invalid *types.ReceiptHandleIsInvalid
+		assert.ErrorAs(t, err, &invalid, "only the last receipt handle is valid")
+	})
+
+	t.Run("Should change the visibility of an in flight message", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders")
+		send(t, b, url, &sqs.SendMessageInput{MessageBody: aws.String("hello")})
+		msgs := receive(t, b, url, 1)
+
+		_, err := b.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+			QueueUrl: aws.String(url), ReceiptHandle: msgs[0].ReceiptHandle, VisibilityTimeout: 0,
+		})
+		assert.NoError(t, err)
+
+		msgs = receive(t, b, url, 1)
+		assert.Len(t, msgs, 1)
+
+		b.Advance(time.Minute)
+		_, err = b.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+			QueueUrl: aws.String(url), ReceiptHandle: msgs[0].ReceiptHandle, VisibilityTimeout: 30,
+		})
+		var notInFlight *types.MessageNotInflight
+		assert.ErrorAs(t, err, &notInFlight)
+
+		_, err = b.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+			QueueUrl: aws.String(url), ReceiptHandle: aws.String("unknown"), VisibilityTimeout: 30,
+		})
+		var invalid *types.ReceiptHandleIsInvalid
+		assert.ErrorAs(t, err, &invalid)
+	})
+}
+
+func TestBroker_LongPolling(t *testing.T) {
+	t.Run("Should return as soon as a message is sent", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders")
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			send(t, b, url, &sqs.SendMessageInput{MessageBody: aws.String("hello")})
+		}()
+
+		start := time.Now()
+		out, err := b.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+			QueueUrl: aws.String(url), WaitTimeSeconds: 5,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hello"}, bodies(out.Messages))
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("Should return no messages once the wait time elapses", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders")
+
+		start := time.Now()
+		out, err := b.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+			QueueUrl: aws.String(url), WaitTimeSeconds: 1,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Messages)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Should stop waiting when the context is cancelled", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := b.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{QueueUrl: aws.String(url), WaitTimeSeconds: 20})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestBroker_FIFO(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should deliver a group in order, one receive at a time", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders.fifo", loafertest.QueueWithContentBasedDeduplication())
+		for _, m := range []struct{ group, body string }{{"a", "a1"}, {"b", "b1"}, {"a", "a2"}, {"b", "b2"}} {
+			send(t, b, url, &sqs.SendMessageInput{MessageBody: aws.String(m.body), MessageGroupId: aws.String(m.group)})
+		}
+
+		first := receive(t, b, url, 1)
+		assert.Equal(t, []string{"a1"}, bodies(first))
+		assert.Equal(t, "a", first[0].Attributes["MessageGroupId"])
+		assert.NotEmpty(t, first[0].Attributes["SequenceNumber"])
+
+		assert.Equal(t, []string{"b1", "b2"}, bodies(receive(t, b, url, 10)), "group a is locked by a1")
+
+		_, err := b.DeleteMessage(ctx, &sqs.DeleteMessageInput{QueueUrl: aws.String(url), ReceiptHandle: first[0].ReceiptHandle})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a2"}, bodies(receive(t, b, url, 10)))
+	})
+
+	t.Run("Should drop the duplicates within the deduplication interval", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders.fifo")
+		input := func() *sqs.SendMessageInput {
+			return &sqs.SendMessageInput{MessageBody: aws.String("x"), MessageGroupId: aws.String("g"),
+				MessageDeduplicationId: aws.String("d")}
+		}
+
+		id := send(t, b, url, input())
+		assert.Equal(t, id, send(t, b, url, input()))
+		assert.Equal(t, 1, b.Len("orders.fifo"))
+
+		b.Advance(5 * time.Minute)
+		assert.NotEqual(t, id, send(t, b, url, input()))
+		assert.Equal(t, 2, b.Len("orders.fifo"))
+	})
+
+	t.Run("Should require a group and a deduplication ID", func(t *testing.T) {
+		b := loafertest.NewBroker()
+		url := b.CreateQueue("orders.fifo")
+
+		_, err := b.SendMessage(ctx, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("x")})
+		assert.Equal(t, "MissingParameter", errorCode(err))
+
+		_, err = b.SendMessage(ctx, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("x"),
+			MessageGroupId: aws.String("g")})
+		assert.Equal(t, "InvalidParameterValue", errorCode(err))
+
+		_, err = b.SendMessage(ctx, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("x"),
+			MessageGroupId: aws.String("g"), MessageDeduplicationId: aws.String("d"), DelaySeconds: 1})
+		assert.Equal(t, "InvalidParameterValue", errorCode(err))
+	})
+}
+
+func TestBroker_Redrive(t *testing.T) {
+	b := loafertest.NewBroker()
+	url := b.CreateQueue("orders", loafertest.QueueWithRedrive("orders-dlq", 2))
+	dlq := b.CreateQueue("orders-dlq")
+	id := send(t, b, url, &sqs.SendMessageInput{MessageBody: aws.String("poison")})
+
+	for range 2 {
+		assert.Len(t, receive(t, b, url, 1), 1)
+		b.Advance(30 * time.Second)
+	}
+
+	assert.Empty(t, receive(t, b, url, 1))
+	assert.Equal(t, 0, b.Len("orders"))
+
+	msgs := receive(t, b, dlq, 1)
+	assert.Equal(t, []string{"poison"}, bodies(msgs))
+	assert.Equal(t, id, aws.ToString(msgs[0].MessageId))
+	assert.Equal(t, "1", msgs[0].Attributes["ApproximateReceiveCount"])
+}
+
+func TestBroker_Batch(t *testing.T) {
+	ctx := context.Background()
+	b := loafertest.NewBroker()
+	url := b.CreateQueue("orders")
+
+	out, err := b.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(url),
+		Entries: []types.SendMessageBatchRequestEntry{
+			{Id: aws.String("1"), MessageBody: aws.String("one")},
+			{Id: aws.String("2")},
+			{Id: aws.String("3"), MessageBody: aws.String("three")},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, out.Successful, 2)
+	assert.Len(t, out.Failed, 1)
+	assert.Equal(t, "2", aws.ToString(out.Failed[0].Id))
+	assert.Equal(t, "MissingParameter", aws.ToString(out.Failed[0].Code))
+	assert.True(t, out.Failed[0].SenderFault)
+
+	msgs := receive(t, b, url, 10)
+	assert.Equal(t, []string{"one", "three"}, bodies(msgs))
+
+	del, err := b.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(url),
+		Entries: []types.DeleteMessageBatchRequestEntry{
+			{Id: aws.String("a"), ReceiptHandle: msgs[0].ReceiptHandle},
+			{Id: aws.String("b"), ReceiptHandle: aws.String("unknown")},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, del.Successful, 1)
+	assert.Equal(t, "ReceiptHandleIsInvalid", aws.ToString(del.Failed[0].Code))
+
+	_, err = b.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{QueueUrl: aws.String(url)})
+	var empty *types.EmptyBatchRequest
+	assert.ErrorAs(t, err, &empty)
+
+	_, err = b.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(url),
+		Entries: []types.DeleteMessageBatchRequestEntry{
+			{Id: aws.String("a"), ReceiptHandle: msgs[1].ReceiptHandle},
+			{Id: aws.String("a"), ReceiptHandle: msgs[1].ReceiptHandle},
+		},
+	})
+	var notDistinct *types.BatchEntryIdsNotDistinct
+	assert.ErrorAs(t, err, &notDistinct)
+}
@@ -1,8 +1,12 @@
 package loafergo
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"strings"
 )
 
 // A Logger is a minimalistic interface for the loafer to log messages to. Should
@@ -50,3 +54,98 @@ type NoOpLogger struct{}
 
 // Log implements Logger but does nothing.
 func (NoOpLogger) Log(args ...any) {}
+
+// NewSlogLogger returns a Logger writing to the slog handler.
+// The Manager and routes recognize it and emit structured records with levels
+// and attributes instead of plain lines.
+func NewSlogLogger(h slog.Handler) Logger {
+	return &slogLogger{logger: slog.New(h)}
+}
+
+// A slogLogger satisfies the Logger interface on top of a *slog.Logger.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// Log logs the parameters as an info record. See fmt.Sprintln.
+func (l *slogLogger) Log(args ...any) {
+	l.logger.Info(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+// StructuredLogger returns a *slog.Logger writing to l.
+//
+// When l was created by NewSlogLogger, its slog.Logger is returned. Any other Logger
+// receives each record formatted as a single line, such as "msg; key: value; key: value".
+func StructuredLogger(l Logger) *slog.Logger {
+	if sl, ok := l.(*slogLogger); ok {
+		return sl.logger
+	}
+	return slog.New(&loggerHandler{logger: l})
+}
+
+// loggerHandler is a slog.Handler writing records to a Logger.
+// All levels are enabled, the Logger implementation decides what to keep.
+type loggerHandler struct {
+	logger Logger
+	prefix string
+	attrs  []slog.Attr
+}
+
+// Enabled implements slog.Handler.
+func (h *loggerHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle implements slog.Handler.
+func (h *loggerHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Message)
+
+	for _, a := range h.attrs {
+		writeAttr(&b, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.prefix, a)
+		return true
+	})
+
+	h.logger.Log(b.String())
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *loggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefixed := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	prefixed = append(prefixed, h.attrs...)
+	for _, a := range attrs {
+		prefixed = append(prefixed, slog.Attr{Key: h.prefix + a.Key, Value: a.Value})
+	}
+	return &loggerHandler{logger: h.logger, prefix: h.prefix, attrs: prefixed}
+}
+
+// WithGroup implements slog.Handler.
+func (h *loggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &loggerHandler{logger: h.logger, prefix: h.prefix + name + ".", attrs: h.attrs}
+}
+
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, prefix, ga)
+		}
+		return
+	}
+
+	fmt.Fprintf(b, "; %s%s: %v", prefix, a.Key, a.Value.Any())
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -60,3 +62,27 @@ func TestNoOpLogger_Log(t *testing.T) {
 	logger := loafergo.NoOpLogger{}
 	logger.Log("This should not appear anywhere")
 }
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := loafergo.NewSlogLogger(slog.NewTextHandler(&buf, nil))
+
+	logger.Log("hello", "slog")
+	assert.Contains(t, buf.String(), `level=INFO msg="hello slog"`)
+
+	buf.Reset()
+	loafergo.StructuredLogger(logger).Warn("structured", "queue", "example-1")
+	assert.Contains(t, buf.String(), `level=WARN msg=structured queue=example-1`)
+}
+
+func TestStructuredLogger(t *testing.T) {
+	var logged []any
+	logger := loafergo.LoggerFunc(func(args ...any) {
+		logged = args
+	})
+
+	sl := loafergo.StructuredLogger(logger).With("queue", "example-1").WithGroup("msg")
+	sl.Debug("change_visibility_timeout", "id", "42", slog.Group("attrs", "foo", "bar"), "timeout", 30*time.Second)
+
+	assert.Equal(t, []any{"change_visibility_timeout; queue: example-1; msg.id: 42; msg.attrs.foo: bar; msg.timeout: 30s"}, logged)
+}
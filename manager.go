@@ -2,24 +2,37 @@ package loafergo
 
 import (
 	"context"
-	"fmt"
-	"math/rand"
+	"errors"
+	"log/slog"
+	"runtime/debug"
 	"sync"
 	"time"
 )
 
-const messageGroupID = "MessageGroupId"
+const (
+	messageGroupID          = "MessageGroupId"
+	approximateReceiveCount = "ApproximateReceiveCount"
+)
 
 // Manager coordinates multiple routes and startWorker pools.
 type Manager struct {
 	config *Config
+	log    *slog.Logger
+	done   chan struct{}
+	stop   context.CancelFunc
+	abort  context.CancelFunc
 	routes []Router
+	mu     sync.Mutex
+	// shutdown is set by a Shutdown called before Run
+	shutdown bool
 }
 
 // NewManager creates a new Manager with the provided configuration.
 func NewManager(config *Config) *Manager {
+	cfg := loadConfig(config)
 	return &Manager{
-		config: loadConfig(config),
+		config: cfg,
+		log:    StructuredLogger(cfg.Logger),
 	}
 }
 
@@ -40,11 +53,33 @@ func (m *Manager) GetRoutes() []Router {
 
 // Run the Manager distributing the startWorker pool by the number of routes.
 // Returns an error if no routes are registered.
+//
+// Cancelling ctx stops the Manager immediately, aborting the handlers in progress.
+// Use Shutdown to stop it gracefully.
 func (m *Manager) Run(ctx context.Context) error {
 	if len(m.routes) == 0 {
 		return ErrNoRoute
 	}
 
+	// workCtx is handed to the workers and only cancelled on a hard stop,
+	// pollCtx stops the routes from receiving new messages. Both carry
+	// Config.Metrics down to the routes.
+	workCtx, abort := context.WithCancel(ContextWithMetrics(ctx, m.config.Metrics))
+	defer abort()
+	pollCtx, stop := context.WithCancel(workCtx)
+	defer stop()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	m.mu.Lock()
+	if m.shutdown {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stop, m.abort, m.done = stop, abort, done
+	m.mu.Unlock()
+
 	var wg sync.WaitGroup
 	wg.Add(len(m.routes))
 
@@ -52,13 +87,13 @@ func (m *Manager) Run(ctx context.Context) error {
 		route := r // avoid closure over loop variable
 
 		if err := route.Configure(ctx); err != nil {
-			m.config.Logger.Log("route configuration failed:", err)
+			m.log.Error("route_configuration_failed", "queue", routeName(ctx, route), "error", err)
 			return err
 		}
 
 		go func() {
 			defer wg.Done()
-			m.runRoute(ctx, route)
+			m.runRoute(workCtx, pollCtx, route)
 		}()
 	}
 
@@ -66,81 +101,318 @@ func (m *Manager) Run(ctx context.Context) error {
 	return nil
 }
 
-func (m *Manager) runRoute(ctx context.Context, r Router) {
+// Shutdown gracefully stops a running Manager.
+//
+// It stops the routes from receiving new messages, waits for the workers to handle
+// and commit the messages already received, and returns once Run has returned.
+// If ctx is done before the draining completes, the handlers still in progress are
+// cancelled and the ctx error is returned.
+//
+// Shutdown returns nil when the Manager is not running yet, Run then returns
+// right away without receiving any message, e.g. when Shutdown wins the race
+// with a Run started in a goroutine.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	stop, abort, done := m.stop, m.abort, m.done
+	if done == nil {
+		m.shutdown = true
+	}
+	m.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	stop()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		abort()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) runRoute(ctx, pollCtx context.Context, r Router) {
+	name := routeName(ctx, r)
+	log := m.log.With("queue", name)
 	workerCount := int(r.WorkerPoolSize(ctx))
-	messageChs := make([]chan Message, workerCount)
+	capacity := workerCount
+
+	// the workers receive batches, holding a single message unless the route is in batch mode
+	br, batchMode := r.(BatchRouter)
+	batchMode = batchMode && br.BatchMode(ctx)
+
+	var process func(msgs []Message)
+	if batchMode {
+		process = func(msgs []Message) {
+			m.pickUp(ctx, r, msgs)
+			m.processBatch(ctx, r, br, name, log, msgs)
+		}
+	} else {
+		handler := Chain(m.config.Middlewares...)(r.HandlerMessage)
+		process = func(msgs []Message) {
+			for _, msg := range msgs {
+				m.pickUp(ctx, r, []Message{msg})
+				m.processMessage(ctx, r, name, log, handler, msg)
+			}
+		}
+	}
+
+	sr, autoscaled := r.(ScalingRouter)
+	var policy *ScalingPolicy
+	if autoscaled {
+		policy = sr.ScalingPolicy(ctx)
+	}
+	if policy != nil {
+		workerCount = policy.clamp(workerCount)
+		capacity = policy.maxWorkers
+	}
+
+	// each batch sent to a worker holds a slot until it is processed,
+	// so the route only receives the messages its workers are free to handle
+	pool := newWorkerPool(ctx, workerCount, capacity, r.RunMode(ctx) == PerGroupID, process)
 
-	for i := 0; i < workerCount; i++ {
-		messageChs[i] = make(chan Message)
-		go m.startWorker(ctx, r, messageChs[i])
+	scaleCtx, stopScaling := context.WithCancel(pollCtx)
+	var scaling sync.WaitGroup
+	if policy != nil {
+		scaling.Go(func() {
+			m.autoscale(scaleCtx, sr, policy, pool, log)
+		})
 	}
 
+	// closing the channels lets the workers finish the messages they hold
 	defer func() {
-		for _, ch := range messageChs {
-			close(ch)
+		stopScaling()
+		scaling.Wait()
+		pool.close()
+		if fr, ok := r.(FlushRouter); ok {
+			fr.Flush(context.WithoutCancel(ctx))
 		}
 	}()
 
-	m.config.Logger.Log("Route consumer ready...")
+	log.Info("route_consumer_ready", "workers", workerCount)
+
+	groupKey := func(msg Message) string {
+		return m.buildGroupKey(ctx, msg, r)
+	}
+	for {
+		free := pool.wait(pollCtx)
+		if free == 0 {
+			log.Info("route_shutting_down")
+			return
+		}
+
+		// the receive call uses the workers context, so the messages it returns
+		// are still handled when the polling stops while it is in progress
+		msgs, err := m.receive(ctx, r, batchMode, free)
+		if err != nil {
+			m.config.Metrics.ReceiveError(name, err)
+			log.Warn("receive_messages_failed", "error", ErrGetMessage.Context(err), "retry_in", m.config.RetryTimeout)
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-time.After(m.config.RetryTimeout):
+				continue
+			}
+		}
+		m.config.Metrics.MessagesReceived(name, len(msgs))
+
+		if !pool.dispatch(ctx, msgs, batchMode, groupKey) {
+			log.Info("route_shutting_down")
+			return
+		}
+	}
+}
+
+// autoscale resizes the worker pool of the route following the policy, until ctx is done
+func (m *Manager) autoscale(ctx context.Context, r ScalingRouter, policy *ScalingPolicy, pool *workerPool, log *slog.Logger) {
+	ticker := time.NewTicker(policy.interval)
+	defer ticker.Stop()
+	scaledAt := time.Now()
 
 	for {
 		select {
 		case <-ctx.Done():
-			m.config.Logger.Log("Context canceled; shutting down route.")
 			return
-		default:
-			msgs, err := r.GetMessages(ctx, m.config.Logger)
-			if err != nil {
-				m.config.Logger.Log(fmt.Sprintf("%s, retrying in %.2fs", ErrGetMessage.Context(err).Error(), m.config.RetryTimeout.Seconds()))
-				select {
-				case <-ctx.Done():
-					return
-				case <-time.After(m.config.RetryTimeout):
-					continue
-				}
-			}
+		case <-ticker.C:
+		}
 
-			for _, msg := range msgs {
-				index := m.assignWorkerIndex(ctx, msg, r, workerCount)
-				select {
-				case messageChs[index] <- msg:
-				case <-ctx.Done():
-					m.config.Logger.Log("Context done; shutting down route.")
-					return
-				}
+		backlog, err := r.Backlog(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
 			}
+			log.Warn("queue_backlog_failed", "error", ErrQueueBacklog.Context(err))
+			backlog = -1
+		}
+
+		workers := int(pool.size.Load())
+		utilization := pool.utilization()
+		desired := policy.Workers(workers, backlog, utilization)
+		if desired == workers || time.Since(scaledAt) < policy.cooldown(workers, desired) {
+			continue
+		}
+
+		if !pool.scale(ctx, desired) {
+			return
 		}
+		scaledAt = time.Now()
+		log.Info("route_scaled", "workers", desired, "previous_workers", workers, "backlog", backlog, "utilization", utilization)
+	}
+}
+
+// receive gets the next messages of the route.
+// Outside batch mode, a BoundedRouter receives at most one message per free worker.
+func (m *Manager) receive(ctx context.Context, r Router, batchMode bool, free int) ([]Message, error) {
+	if b, ok := r.(BoundedRouter); ok && !batchMode {
+		return b.GetMessagesUpTo(ctx, m.config.Logger, int32(free))
 	}
+	return r.GetMessages(ctx, m.config.Logger)
 }
 
-func (m *Manager) assignWorkerIndex(ctx context.Context, msg Message, r Router, size int) int {
-	if r.RunMode(ctx) == PerGroupID {
-		key := m.buildGroupKey(ctx, msg, r)
-		return hashGroupID(key) % size
+// pickUp tells a PickUpRouter its messages are about to be handled
+func (m *Manager) pickUp(ctx context.Context, r Router, msgs []Message) {
+	pr, ok := r.(PickUpRouter)
+	if !ok {
+		return
+	}
+	for _, msg := range msgs {
+		pr.PickUp(ctx, msg)
 	}
-	return rand.Intn(size)
 }
 
-func (m *Manager) startWorker(ctx context.Context, r Router, msgCh <-chan Message) {
-	for msg := range msgCh {
-		if err := r.HandlerMessage(ctx, msg); err != nil {
-			logMsg := fmt.Sprintf(
-				"handler_message_error: %v; message: %s; group_id: %s; identifier: %s",
-				err, msg.Body(), msg.SystemAttributeByKey(messageGroupID), msg.Identifier(),
-			)
-			m.config.Logger.Log(logMsg)
+func (m *Manager) processMessage(ctx context.Context, r Router, name string, log *slog.Logger, h Handler, msg Message) {
+	start := time.Now()
+	err := m.handleMessage(ctx, r, h, msg)
+	m.config.Metrics.HandlerDuration(name, time.Since(start))
+	m.settle(ctx, r, name, log, msg, err)
+}
+
+// processBatch hands the messages to the batch handler and commits the successful ones concurrently,
+// so they can be gathered by a batching Commit.
+func (m *Manager) processBatch(ctx context.Context, r Router, br BatchRouter, name string, log *slog.Logger, msgs []Message) {
+	start := time.Now()
+	result := m.handleBatch(ctx, r, br, msgs)
+	// each message of the batch is reported, so the batch and per-message routes count the same
+	elapsed := time.Since(start)
+	for range msgs {
+		m.config.Metrics.HandlerDuration(name, elapsed)
+	}
+
+	var commits sync.WaitGroup
+	for _, msg := range msgs {
+		err := result.Err(msg)
+		if OutcomeOf(err).Kind() == OutcomeNack {
+			m.settle(ctx, r, name, log, msg, err)
 			continue
 		}
-		if err := r.Commit(ctx, msg); err != nil {
-			logMsg := fmt.Sprintf(
-				"commit_message_error: %v; message: %s; group_id: %s; identifier: %s",
-				err, msg.Body(), msg.SystemAttributeByKey(messageGroupID), msg.Identifier(),
-			)
-			m.config.Logger.Log(logMsg)
+		commits.Go(func() {
+			m.settle(ctx, r, name, log, msg, err)
+		})
+	}
+	commits.Wait()
+}
+
+// settle translates the outcome of a handled message, see Outcome:
+// the acked and dropped messages are committed, the dead-lettered ones are handed over
+// to the route before being committed, and the others are left in the queue.
+func (m *Manager) settle(ctx context.Context, r Router, name string, log *slog.Logger, msg Message, err error) {
+	outcome := OutcomeOf(err)
+	switch outcome.Kind() {
+	case OutcomeAck:
+		m.commit(ctx, r, name, log, msg)
+	case OutcomeDrop:
+		log.Debug("message_dropped", "message_id", msg.MessageID(), "identifier", msg.Identifier())
+		m.commit(ctx, r, name, log, msg)
+	case OutcomeRetryAfter:
+		// the routes supporting it already changed the message visibility
+		if !msg.BackedOff() {
+			msg.Backoff(outcome.Delay())
 		}
+		log.Info("message_retry_scheduled", "message_id", msg.MessageID(), "identifier", msg.Identifier(), "delay", outcome.Delay())
+	case OutcomeDeadLetter:
+		dr, ok := r.(DeadLetterRouter)
+		if !ok {
+			err = ErrDeadLetter.Context(errors.New("route does not support dead-lettering"))
+		} else if err = dr.DeadLetter(context.WithoutCancel(ctx), msg, outcome.Reason()); err != nil {
+			err = ErrDeadLetter.Context(err)
+		}
+		if err != nil {
+			m.config.Metrics.HandlerError(name, err)
+			log.Error("dead_letter_message_error", m.messageAttrs(msg, err)...)
+			return
+		}
+		log.Info("message_dead_lettered", "message_id", msg.MessageID(), "identifier", msg.Identifier(), "reason", outcome.Reason())
+		m.commit(ctx, r, name, log, msg)
+	default:
+		m.config.Metrics.HandlerError(name, err)
+		log.Error("handler_message_error", m.messageAttrs(msg, err)...)
+	}
+}
+
+func (m *Manager) commit(ctx context.Context, r Router, name string, log *slog.Logger, msg Message) {
+	// the handler succeeded, so the commit must not be aborted by a cancelled context
+	if err := r.Commit(context.WithoutCancel(ctx), msg); err != nil {
+		m.config.Metrics.CommitError(name, err)
+		log.Error("commit_message_error", m.messageAttrs(msg, err)...)
 	}
 }
 
+// messageAttrs returns the log attributes identifying the message.
+// The body is only added when Config.LogMessageBody is set.
+func (m *Manager) messageAttrs(msg Message, err error) []any {
+	attrs := []any{
+		"error", err,
+		"message_id", msg.MessageID(),
+		"group_id", msg.SystemAttributeByKey(messageGroupID),
+		"receive_count", msg.SystemAttributeByKey(approximateReceiveCount),
+		"identifier", msg.Identifier(),
+	}
+	if m.config.LogMessageBody {
+		attrs = append(attrs, "body", string(msg.Body()))
+	}
+	return attrs
+}
+
+// handleMessage calls the route handler, recovering a panic into a *PanicError
+// so that one bad message does not take the whole process down.
+func (m *Manager) handleMessage(ctx context.Context, r Router, h Handler, msg Message) (err error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+
+		err = &PanicError{Value: v, Stack: debug.Stack()}
+		if m.config.OnPanic != nil {
+			m.config.OnPanic(ctx, r, msg, v)
+		}
+	}()
+
+	return h(ctx, msg)
+}
+
+// handleBatch calls the route batch handler. A panic fails every message of the batch with a *PanicError.
+func (m *Manager) handleBatch(ctx context.Context, r Router, br BatchRouter, msgs []Message) (result BatchResult) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+
+		result = FailAll(msgs, &PanicError{Value: v, Stack: debug.Stack()})
+		if m.config.OnPanic != nil {
+			for _, msg := range msgs {
+				m.config.OnPanic(ctx, r, msg, v)
+			}
+		}
+	}()
+
+	return br.HandlerBatch(ctx, msgs)
+}
+
 func (m *Manager) buildGroupKey(ctx context.Context, msg Message, r Router) string {
 	key := msg.SystemAttributeByKey(messageGroupID)
 	for _, field := range r.CustomGroupFields(ctx) {
@@ -151,17 +423,10 @@ func (m *Manager) buildGroupKey(ctx context.Context, msg Message, r Router) stri
 	return key
 }
 
-func hashGroupID(s string) int {
-	h := 0
-	for _, c := range s {
-		h = int(c) + ((h << 5) - h)
-	}
-	return abs(h)
-}
-
-func abs(n int) int {
-	if n < 0 {
-		return -n
+// routeName returns the queue name of routers implementing NamedRouter
+func routeName(ctx context.Context, r Router) string {
+	if nr, ok := r.(NamedRouter); ok {
+		return nr.Name(ctx)
 	}
-	return n
+	return ""
 }
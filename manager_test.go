@@ -1,8 +1,14 @@
 package loafergo_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -120,7 +126,7 @@ func TestManager_Run_RouteConfigurationError(t *testing.T) {
 	router.On("Configure", mock.Anything).Return(errors.New("config error"))
 
 	logger := new(fake.Logger)
-	logger.On("Log", mock.Anything, mock.Anything).Return()
+	logger.On("Log", mock.Anything).Return()
 
 	manager := loafergo.NewManager(&loafergo.Config{
 		Logger: logger,
@@ -151,6 +157,8 @@ func TestManager_Run_HandlerMessageError(t *testing.T) {
 	message.On("SystemAttributeByKey", "MessageGroupId").Return("group1").Maybe()
 	message.On("Body").Return([]byte("body")).Maybe()
 	message.On("Identifier").Return("id").Maybe()
+	message.On("MessageID").Return("message-id").Maybe()
+	message.On("SystemAttributeByKey", "ApproximateReceiveCount").Return("1").Maybe()
 
 	manager := loafergo.NewManager(&loafergo.Config{
 		Logger:       logger,
@@ -187,6 +195,8 @@ func TestManager_Run_CommitError(t *testing.T) {
 	message.On("SystemAttributeByKey", "MessageGroupId").Return("group1").Maybe()
 	message.On("Body").Return([]byte("body")).Maybe()
 	message.On("Identifier").Return("id").Maybe()
+	message.On("MessageID").Return("message-id").Maybe()
+	message.On("SystemAttributeByKey", "ApproximateReceiveCount").Return("1").Maybe()
 
 	manager := loafergo.NewManager(&loafergo.Config{
 		Logger:       logger,
@@ -233,3 +243,813 @@ func TestManager_Run_GetMessagesTemporaryError(t *testing.T) {
 	assert.NoError(t, err)
 	router.AssertExpectations(t)
 }
+
+func TestManager_Shutdown_NotRunning(t *testing.T) {
+	m := loafergo.NewManager(nil)
+	assert.NoError(t, m.Shutdown(context.Background()))
+}
+
+func TestManager_Shutdown_BeforeRun(t *testing.T) {
+	// the route must not be configured nor polled
+	router := fake.NewRouter(t)
+
+	manager := loafergo.NewManager(nil)
+	manager.RegisterRoute(router)
+
+	assert.NoError(t, manager.Shutdown(context.Background()))
+	assert.NoError(t, manager.Run(context.Background()))
+}
+
+func TestManager_Shutdown_DrainsInFlightMessages(t *testing.T) {
+	logger := new(fake.Logger)
+	logger.On("Log", mock.Anything).Return()
+
+	message := new(fake.Message)
+	started := make(chan struct{})
+
+	router := new(fake.Router)
+	router.On("Configure", mock.Anything).Return(nil)
+	router.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+	router.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+	router.On("GetMessages", mock.Anything, logger).Return([]loafergo.Message{message}, nil).Once()
+	router.On("GetMessages", mock.Anything, logger).Return(nil, nil).Maybe()
+	router.On("HandlerMessage", mock.Anything, message).
+		Run(func(args mock.Arguments) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+		}).
+		Return(nil).Once()
+	router.On("Commit", mock.Anything, message).
+		Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			assert.NoError(t, ctx.Err())
+		}).
+		Return(nil).Once()
+
+	manager := loafergo.NewManager(&loafergo.Config{
+		Logger:       logger,
+		RetryTimeout: time.Second,
+	})
+	manager.RegisterRoute(router)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- manager.Run(context.Background())
+	}()
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, manager.Shutdown(ctx))
+	assert.NoError(t, <-runErr)
+	router.AssertExpectations(t)
+}
+
+// flushRouter is a fake.Router implementing loafergo.FlushRouter
+type flushRouter struct {
+	*fake.Router
+	*fake.FlushRouter
+}
+
+func TestManager_Shutdown_FlushesCommits(t *testing.T) {
+	message := new(fake.Message)
+	message.On("SystemAttributeByKey", mock.Anything).Return("").Maybe()
+	var committed atomic.Bool
+
+	base := new(fake.Router)
+	base.On("Configure", mock.Anything).Return(nil)
+	base.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+	base.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+	base.On("GetMessages", mock.Anything, mock.Anything).Return([]loafergo.Message{message}, nil).Once()
+	base.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	base.On("HandlerMessage", mock.Anything, message).Return(nil).Once()
+	base.On("Commit", mock.Anything, message).Run(func(mock.Arguments) { committed.Store(true) }).Return(nil).Once()
+
+	flush := new(fake.FlushRouter)
+	flush.On("Flush", mock.Anything).Run(func(args mock.Arguments) {
+		assert.True(t, committed.Load(), "the route is flushed once its workers stopped")
+		assert.NoError(t, args.Get(0).(context.Context).Err())
+	}).Return().Once()
+
+	manager := loafergo.NewManager(&loafergo.Config{SlogHandler: slog.DiscardHandler})
+	manager.RegisterRoute(&flushRouter{Router: base, FlushRouter: flush})
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- manager.Run(context.Background())
+	}()
+
+	assert.Eventually(t, committed.Load, time.Second, 5*time.Millisecond)
+	assert.NoError(t, manager.Shutdown(context.Background()))
+	assert.NoError(t, <-runErr)
+	base.AssertExpectations(t)
+	flush.AssertExpectations(t)
+}
+
+func TestManager_Shutdown_DeadlineExceeded(t *testing.T) {
+	logger := new(fake.Logger)
+	logger.On("Log", mock.Anything).Return()
+
+	message := new(fake.Message)
+	message.On("SystemAttributeByKey", "MessageGroupId").Return("").Maybe()
+	message.On("Body").Return([]byte("body")).Maybe()
+	message.On("Identifier").Return("id").Maybe()
+	message.On("MessageID").Return("message-id").Maybe()
+	message.On("SystemAttributeByKey", "ApproximateReceiveCount").Return("1").Maybe()
+	started := make(chan struct{})
+
+	router := new(fake.Router)
+	router.On("Configure", mock.Anything).Return(nil)
+	router.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+	router.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+	router.On("GetMessages", mock.Anything, logger).Return([]loafergo.Message{message}, nil).Once()
+	router.On("GetMessages", mock.Anything, logger).Return(nil, nil).Maybe()
+	router.On("HandlerMessage", mock.Anything, message).
+		Return(func(ctx context.Context, _ loafergo.Message) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}).Once()
+
+	manager := loafergo.NewManager(&loafergo.Config{
+		Logger:       logger,
+		RetryTimeout: time.Second,
+	})
+	manager.RegisterRoute(router)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- manager.Run(context.Background())
+	}()
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, manager.Shutdown(ctx), context.DeadlineExceeded)
+	assert.NoError(t, <-runErr)
+	router.AssertNotCalled(t, "Commit", mock.Anything, message)
+}
+
+func TestManager_Run_HandlerPanic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	message := new(fake.Message)
+	message.On("SystemAttributeByKey", "MessageGroupId").Return("group1").Maybe()
+	message.On("Body").Return([]byte("body")).Maybe()
+	message.On("Identifier").Return("id").Maybe()
+	message.On("MessageID").Return("message-id").Maybe()
+	message.On("SystemAttributeByKey", "ApproximateReceiveCount").Return("1").Maybe()
+
+	var logged []string
+	var mu sync.Mutex
+	logger := loafergo.LoggerFunc(func(args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		logged = append(logged, fmt.Sprint(args...))
+	})
+
+	router := new(fake.Router)
+	router.On("Configure", mock.Anything).Return(nil)
+	router.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+	router.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+	router.On("GetMessages", mock.Anything, mock.Anything).Return([]loafergo.Message{message}, nil).Once()
+	router.On("GetMessages", mock.Anything, mock.Anything).Return(nil, context.Canceled).Maybe()
+	router.On("HandlerMessage", mock.Anything, message).
+		Run(func(args mock.Arguments) {
+			panic("boom")
+		}).
+		Return(nil)
+
+	recovered := make(chan any, 1)
+	manager := loafergo.NewManager(&loafergo.Config{
+		Logger:       logger,
+		RetryTimeout: time.Second,
+		OnPanic: func(ctx context.Context, r loafergo.Router, msg loafergo.Message, v any) {
+			assert.Equal(t, router, r)
+			assert.Equal(t, message, msg)
+			recovered <- v
+		},
+	})
+	manager.RegisterRoute(router)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	err := manager.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "boom", <-recovered)
+	router.AssertNotCalled(t, "Commit", mock.Anything, message)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, strings.Join(logged, "\n"), "handler_message_error; queue: ; error: handler panic: boom")
+}
+
+func TestManager_Run_Middlewares(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	message := new(fake.Message)
+	logger := new(fake.Logger)
+	logger.On("Log", mock.Anything).Return()
+
+	router := new(fake.Router)
+	router.On("Configure", mock.Anything).Return(nil)
+	router.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+	router.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+	router.On("GetMessages", mock.Anything, logger).Return([]loafergo.Message{message}, nil).Once()
+	router.On("GetMessages", mock.Anything, logger).Return(nil, context.Canceled).Maybe()
+	router.On("HandlerMessage", mock.Anything, message).Return(nil).Once()
+	router.On("Commit", mock.Anything, message).Return(nil).Once()
+
+	wrapped := make(chan loafergo.Message, 1)
+	manager := loafergo.NewManager(&loafergo.Config{
+		Logger:       logger,
+		RetryTimeout: time.Second,
+		Middlewares: []loafergo.Middleware{
+			func(next loafergo.Handler) loafergo.Handler {
+				return func(ctx context.Context, m loafergo.Message) error {
+					wrapped <- m
+					return next(ctx, m)
+				}
+			},
+		},
+	})
+	manager.RegisterRoute(router)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	err := manager.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, message, <-wrapped)
+	router.AssertExpectations(t)
+}
+
+func TestManager_Run_SlogHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		logBody    bool
+		expectBody bool
+	}{
+		{name: "Without message body", logBody: false, expectBody: false},
+		{name: "With message body", logBody: true, expectBody: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			message := new(fake.Message)
+			message.On("MessageID").Return("message-id")
+			message.On("SystemAttributeByKey", "MessageGroupId").Return("group1")
+			message.On("SystemAttributeByKey", "ApproximateReceiveCount").Return("2")
+			message.On("Identifier").Return("id")
+			message.On("Body").Return([]byte("secret body")).Maybe()
+
+			router := new(fake.Router)
+			router.On("Configure", mock.Anything).Return(nil)
+			router.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+			router.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+			router.On("GetMessages", mock.Anything, mock.Anything).Return([]loafergo.Message{message}, nil).Once()
+			router.On("GetMessages", mock.Anything, mock.Anything).Return(nil, context.Canceled).Maybe()
+			router.On("HandlerMessage", mock.Anything, message).Return(errors.New("handler failed"))
+
+			var buf safeBuffer
+			manager := loafergo.NewManager(&loafergo.Config{
+				SlogHandler:    slog.NewJSONHandler(&buf, nil),
+				LogMessageBody: tc.logBody,
+				RetryTimeout:   time.Second,
+			})
+			manager.RegisterRoute(router)
+
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				cancel()
+			}()
+
+			err := manager.Run(ctx)
+			assert.NoError(t, err)
+
+			logs := buf.String()
+			assert.Contains(t, logs, `"level":"ERROR","msg":"handler_message_error","queue":"","error":"handler failed",`+
+				`"message_id":"message-id","group_id":"group1","receive_count":"2","identifier":"id"`)
+			assert.Equal(t, tc.expectBody, strings.Contains(logs, "secret body"))
+		})
+	}
+}
+
+// safeBuffer is a bytes.Buffer safe for concurrent use
+type safeBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestManager_Run_Metrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	failing := new(fake.Message)
+	uncommitted := new(fake.Message)
+	for _, message := range []*fake.Message{failing, uncommitted} {
+		message.On("SystemAttributeByKey", mock.Anything).Return("").Maybe()
+		message.On("Identifier").Return("id").Maybe()
+		message.On("MessageID").Return("message-id").Maybe()
+	}
+	logger := new(fake.Logger)
+	logger.On("Log", mock.Anything).Return()
+
+	router := new(fake.Router)
+	router.On("Configure", mock.Anything).Return(nil)
+	router.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+	router.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+	router.On("GetMessages", mock.Anything, logger).Return(nil, errors.New("temporary error")).Once()
+	router.On("GetMessages", mock.Anything, logger).Return([]loafergo.Message{failing, uncommitted}, nil).Once()
+	router.On("GetMessages", mock.Anything, logger).Return(nil, context.Canceled).Maybe()
+	router.On("HandlerMessage", mock.Anything, failing).Return(errors.New("handler failed")).Once()
+	router.On("HandlerMessage", mock.Anything, uncommitted).Return(nil).Once()
+	router.On("Commit", mock.Anything, uncommitted).Return(errors.New("commit failed")).Once()
+
+	metrics := loafergo.NewInMemoryMetrics()
+	manager := loafergo.NewManager(&loafergo.Config{
+		Logger:       logger,
+		Metrics:      metrics,
+		RetryTimeout: 10 * time.Millisecond,
+	})
+	manager.RegisterRoute(router)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		cancel()
+	}()
+
+	err := manager.Run(ctx)
+	assert.NoError(t, err)
+	router.AssertExpectations(t)
+
+	// fake.Router does not implement NamedRouter
+	rm := metrics.Snapshot()[""]
+	assert.Equal(t, int64(2), rm.MessagesReceived)
+	assert.GreaterOrEqual(t, rm.ReceiveErrors, int64(1))
+	assert.Equal(t, int64(2), rm.HandledMessages)
+	assert.Equal(t, int64(1), rm.HandlerErrors)
+	assert.Equal(t, int64(1), rm.CommitErrors)
+}
+
+// batchRouter adds the BatchRouter methods to fake.Router
+type batchRouter struct {
+	*fake.Router
+	batches [][]loafergo.Message
+	fail    loafergo.Message
+	panics  bool
+	mu      sync.Mutex
+}
+
+func (r *batchRouter) BatchMode(context.Context) bool { return true }
+
+func (r *batchRouter) HandlerBatch(_ context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, msgs)
+	if r.panics {
+		panic("boom")
+	}
+
+	result := loafergo.BatchResult{}
+	for _, msg := range msgs {
+		if msg == r.fail {
+			result.Fail(msg, errors.New("handler failed"))
+		}
+	}
+	return result
+}
+
+func TestManager_Run_BatchMode(t *testing.T) {
+	newMessage := func(id, groupID string) *fake.Message {
+		message := new(fake.Message)
+		message.On("Identifier").Return(id)
+		message.On("MessageID").Return(id).Maybe()
+		message.On("SystemAttributeByKey", "MessageGroupId").Return(groupID).Maybe()
+		message.On("SystemAttributeByKey", "ApproximateReceiveCount").Return("1").Maybe()
+		return message
+	}
+
+	testCases := []struct {
+		name    string
+		mode    loafergo.Mode
+		batches [][]int
+	}{
+		{
+			name:    "Parallel mode hands the whole receive result to one worker",
+			mode:    loafergo.Parallel,
+			batches: [][]int{{0, 1, 2}},
+		},
+		{
+			name:    "PerGroupID mode hands each worker the messages of its groups in order",
+			mode:    loafergo.PerGroupID,
+			batches: [][]int{{0, 2}, {1}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			messages := []loafergo.Message{
+				newMessage("id-1", "a"),
+				newMessage("id-2", "b"),
+				newMessage("id-3", "a"),
+			}
+			logger := new(fake.Logger)
+			logger.On("Log", mock.Anything).Return()
+
+			router := &batchRouter{Router: new(fake.Router), fail: messages[1]}
+			router.On("Configure", mock.Anything).Return(nil)
+			router.On("WorkerPoolSize", mock.Anything).Return(int32(4))
+			router.On("RunMode", mock.Anything).Return(tc.mode)
+			router.On("CustomGroupFields", mock.Anything).Return([]string(nil)).Maybe()
+			router.On("GetMessages", mock.Anything, logger).Return(messages, nil).Once()
+			router.On("GetMessages", mock.Anything, logger).Return(nil, context.Canceled).Maybe()
+			router.On("Commit", mock.Anything, messages[0]).Return(nil).Once()
+			router.On("Commit", mock.Anything, messages[2]).Return(nil).Once()
+
+			metrics := loafergo.NewInMemoryMetrics()
+			manager := loafergo.NewManager(&loafergo.Config{
+				Logger:       logger,
+				Metrics:      metrics,
+				RetryTimeout: time.Second,
+			})
+			manager.RegisterRoute(router)
+
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				cancel()
+			}()
+
+			err := manager.Run(ctx)
+			assert.NoError(t, err)
+			router.AssertExpectations(t)
+			router.AssertNotCalled(t, "HandlerMessage", mock.Anything, mock.Anything)
+
+			// every message of the batches is counted, as in per-message mode
+			rm := metrics.Snapshot()[""]
+			assert.Equal(t, int64(3), rm.MessagesReceived)
+			assert.Equal(t, int64(3), rm.HandledMessages)
+			assert.Equal(t, int64(1), rm.HandlerErrors)
+			router.AssertNotCalled(t, "Commit", mock.Anything, messages[1])
+
+			var expected [][]loafergo.Message
+			for _, batch := range tc.batches {
+				var msgs []loafergo.Message
+				for _, i := range batch {
+					msgs = append(msgs, messages[i])
+				}
+				expected = append(expected, msgs)
+			}
+			assert.ElementsMatch(t, expected, router.batches)
+		})
+	}
+}
+
+func TestManager_Run_BatchModePanic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	message := new(fake.Message)
+	message.On("Identifier").Return("id")
+	message.On("MessageID").Return("message-id").Maybe()
+	message.On("SystemAttributeByKey", mock.Anything).Return("").Maybe()
+	logger := new(fake.Logger)
+	logger.On("Log", mock.Anything).Return()
+
+	router := &batchRouter{Router: new(fake.Router), panics: true}
+	router.On("Configure", mock.Anything).Return(nil)
+	router.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+	router.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+	router.On("GetMessages", mock.Anything, logger).Return([]loafergo.Message{message}, nil).Once()
+	router.On("GetMessages", mock.Anything, logger).Return(nil, context.Canceled).Maybe()
+
+	panicked := make(chan any, 1)
+	manager := loafergo.NewManager(&loafergo.Config{
+		Logger:       logger,
+		RetryTimeout: time.Second,
+		OnPanic: func(ctx context.Context, r loafergo.Router, msg loafergo.Message, v any) {
+			panicked <- v
+		},
+	})
+	manager.RegisterRoute(router)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	err := manager.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "boom", <-panicked)
+	router.AssertNotCalled(t, "Commit", mock.Anything, mock.Anything)
+}
+
+// deadLetterRouter is a fake.Router implementing loafergo.DeadLetterRouter
+type deadLetterRouter struct {
+	*fake.Router
+	*fake.DeadLetterRouter
+}
+
+func TestManager_Run_Outcomes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		outcome       error
+		deadLetterErr error
+		plainRouter   bool
+		commit        bool
+		backoff       time.Duration
+		deadLettered  bool
+	}{
+		{name: "Ack commits the message", outcome: loafergo.Ack(), commit: true},
+		{name: "Drop commits the message", outcome: loafergo.Drop(), commit: true},
+		{name: "Nack leaves the message", outcome: loafergo.Nack(errors.New("boom"))},
+		{name: "RetryAfter backs the message off", outcome: loafergo.RetryAfter(time.Minute), backoff: time.Minute},
+		{
+			name:         "DeadLetter hands the message over and commits it",
+			outcome:      fmt.Errorf("wrapped: %w", loafergo.DeadLetter("invalid")),
+			commit:       true,
+			deadLettered: true,
+		},
+		{
+			name:          "DeadLetter leaves the message when the route fails to dead-letter it",
+			outcome:       loafergo.DeadLetter("invalid"),
+			deadLetterErr: errors.New("unavailable"),
+			deadLettered:  true,
+		},
+		{
+			name:        "DeadLetter leaves the message when the route does not support it",
+			outcome:     loafergo.DeadLetter("invalid"),
+			plainRouter: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			message := new(fake.Message)
+			message.On("Identifier").Return("id").Maybe()
+			message.On("MessageID").Return("message-id").Maybe()
+			message.On("SystemAttributeByKey", mock.Anything).Return("").Maybe()
+			if tc.backoff > 0 {
+				message.On("BackedOff").Return(false).Once()
+				message.On("Backoff", tc.backoff).Return().Once()
+			}
+
+			logger := new(fake.Logger)
+			logger.On("Log", mock.Anything).Return()
+
+			base := new(fake.Router)
+			base.On("Configure", mock.Anything).Return(nil)
+			base.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+			base.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+			base.On("GetMessages", mock.Anything, logger).Return([]loafergo.Message{message}, nil).Once()
+			base.On("GetMessages", mock.Anything, logger).Return(nil, context.Canceled).Maybe()
+			base.On("HandlerMessage", mock.Anything, message).Return(tc.outcome)
+			if tc.commit {
+				base.On("Commit", mock.Anything, message).Return(nil).Once()
+			}
+
+			deadLetter := new(fake.DeadLetterRouter)
+			if tc.deadLettered {
+				deadLetter.On("DeadLetter", mock.Anything, message, "invalid").Return(tc.deadLetterErr).Once()
+			}
+
+			router := &deadLetterRouter{Router: base, DeadLetterRouter: deadLetter}
+			manager := loafergo.NewManager(&loafergo.Config{Logger: logger, RetryTimeout: time.Second})
+			if tc.plainRouter {
+				manager.RegisterRoute(base)
+			} else {
+				manager.RegisterRoute(router)
+			}
+
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				cancel()
+			}()
+
+			assert.NoError(t, manager.Run(ctx))
+			base.AssertExpectations(t)
+			message.AssertExpectations(t)
+			if !tc.commit {
+				base.AssertNotCalled(t, "Commit", mock.Anything, mock.Anything)
+			}
+			deadLetter.AssertExpectations(t)
+			if !tc.deadLettered {
+				deadLetter.AssertNotCalled(t, "DeadLetter", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// boundedRouter is a fake.Router implementing loafergo.BoundedRouter and loafergo.PickUpRouter
+type boundedRouter struct {
+	*fake.Router
+	*fake.BoundedRouter
+	*fake.PickUpRouter
+}
+
+func TestManager_Run_Backpressure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newMessage := func(id string) *fake.Message {
+		message := new(fake.Message)
+		message.On("Identifier").Return(id).Maybe()
+		message.On("MessageID").Return(id).Maybe()
+		message.On("SystemAttributeByKey", mock.Anything).Return("").Maybe()
+		return message
+	}
+	first, second := newMessage("1"), newMessage("2")
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	base := new(fake.Router)
+	base.On("Configure", mock.Anything).Return(nil)
+	base.On("WorkerPoolSize", mock.Anything).Return(int32(2))
+	base.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+	base.On("HandlerMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		started <- struct{}{}
+		select {
+		case <-release:
+		case <-args.Get(0).(context.Context).Done():
+		}
+	}).Return(nil)
+	base.On("Commit", mock.Anything, mock.Anything).Return(nil)
+
+	receives := make(chan []loafergo.Message, 1)
+	receives <- []loafergo.Message{first, second}
+	var (
+		maxes []int32
+		mu    sync.Mutex
+	)
+	received := func() []int32 {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int32(nil), maxes...)
+	}
+
+	bounded := new(fake.BoundedRouter)
+	bounded.On("GetMessagesUpTo", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, _ loafergo.Logger, maxMessages int32) ([]loafergo.Message, error) {
+			mu.Lock()
+			maxes = append(maxes, maxMessages)
+			mu.Unlock()
+
+			select {
+			case msgs := <-receives:
+				return msgs, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		})
+
+	pickUp := new(fake.PickUpRouter)
+	pickUp.On("PickUp", mock.Anything, first).Return().Once()
+	pickUp.On("PickUp", mock.Anything, second).Return().Once()
+
+	router := &boundedRouter{Router: base, BoundedRouter: bounded, PickUpRouter: pickUp}
+
+	manager := loafergo.NewManager(&loafergo.Config{SlogHandler: slog.DiscardHandler})
+	manager.RegisterRoute(router)
+
+	done := make(chan error)
+	go func() {
+		done <- manager.Run(ctx)
+	}()
+
+	<-started
+	<-started
+	// both workers are busy, the route is not polled
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []int32{2}, received())
+
+	release <- struct{}{}
+	assert.Eventually(t, func() bool {
+		return len(received()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int32{2, 1}, received())
+
+	cancel()
+	assert.NoError(t, <-done)
+	base.AssertNotCalled(t, "GetMessages", mock.Anything, mock.Anything)
+	pickUp.AssertExpectations(t)
+}
+
+// scalingRouter is a fake.Router implementing loafergo.ScalingRouter, receiving a message per call while feeding
+type scalingRouter struct {
+	*fake.Router
+	*fake.ScalingRouter
+	message loafergo.Message
+	feeding atomic.Bool
+}
+
+func (r *scalingRouter) GetMessages(ctx context.Context, _ loafergo.Logger) ([]loafergo.Message, error) {
+	if r.feeding.Load() {
+		return []loafergo.Message{r.message}, nil
+	}
+	select {
+	case <-time.After(5 * time.Millisecond):
+	case <-ctx.Done():
+	}
+	return nil, nil
+}
+
+func TestManager_Run_Autoscaling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	message := new(fake.Message)
+	message.On("Identifier").Return("id").Maybe()
+	message.On("MessageID").Return("id").Maybe()
+	message.On("SystemAttributeByKey", mock.Anything).Return("").Maybe()
+
+	var running, peak atomic.Int64
+	release := make(chan struct{})
+
+	base := new(fake.Router)
+	base.On("Configure", mock.Anything).Return(nil)
+	base.On("WorkerPoolSize", mock.Anything).Return(int32(1))
+	base.On("RunMode", mock.Anything).Return(loafergo.Parallel)
+	base.On("HandlerMessage", mock.Anything, message).Run(func(args mock.Arguments) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		select {
+		case <-release:
+		case <-args.Get(0).(context.Context).Done():
+		}
+	}).Return(nil)
+	base.On("Commit", mock.Anything, message).Return(nil)
+
+	var backlog atomic.Int64
+	backlog.Store(100)
+	scaling := new(fake.ScalingRouter)
+	scaling.On("ScalingPolicy", mock.Anything).Return(loafergo.NewScalingPolicy(1, 3,
+		loafergo.ScaleWithInterval(10*time.Millisecond),
+		loafergo.ScaleWithCooldowns(0, 0),
+	))
+	scaling.On("Backlog", mock.Anything).Return(func(context.Context) (int, error) {
+		return int(backlog.Load()), nil
+	})
+
+	router := &scalingRouter{Router: base, ScalingRouter: scaling, message: message}
+	router.feeding.Store(true)
+
+	var buf safeBuffer
+	manager := loafergo.NewManager(&loafergo.Config{SlogHandler: slog.NewJSONHandler(&buf, nil)})
+	manager.RegisterRoute(router)
+
+	done := make(chan error)
+	go func() {
+		done <- manager.Run(ctx)
+	}()
+
+	// the backlog grows the pool up to the max workers
+	assert.Eventually(t, func() bool {
+		return peak.Load() == 3
+	}, time.Second, 5*time.Millisecond)
+
+	// idle workers shrink it back to the min
+	router.feeding.Store(false)
+	backlog.Store(0)
+	close(release)
+	assert.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), `"msg":"route_scaled","queue":"","workers":1,"previous_workers":2`)
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Equal(t, int64(3), peak.Load())
+	assert.Contains(t, buf.String(), `"msg":"route_scaled","queue":"","workers":3,"previous_workers":1,"backlog":100`)
+}
@@ -0,0 +1,244 @@
+package loafergo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics receives the observability events of the Manager and routes.
+// The route argument is the queue name of the route, see NamedRouter.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// MessagesReceived is called after each successful poll with the number of messages received
+	MessagesReceived(route string, n int)
+	// ReceiveError is called when a poll fails
+	ReceiveError(route string, err error)
+	// HandlerDuration is called after each handled message, whatever its outcome.
+	// In batch mode, it is called for each message of the batch with the duration of the batch handler.
+	HandlerDuration(route string, d time.Duration)
+	// HandlerError is called when the handler returns an error
+	HandlerError(route string, err error)
+	// CommitError is called when a handled message cannot be committed
+	CommitError(route string, err error)
+	// VisibilityExtended is called when the route extends the visibility timeout of a message in progress
+	VisibilityExtended(route string, timeout time.Duration)
+	// BackedOff is called when the visibility timeout of a message is changed by a backoff
+	BackedOff(route string, delay time.Duration)
+}
+
+type metricsCtxKey struct{}
+
+// ContextWithMetrics returns a copy of ctx carrying m.
+// The Manager uses it to hand Config.Metrics over to the routes.
+func ContextWithMetrics(ctx context.Context, m Metrics) context.Context {
+	return context.WithValue(ctx, metricsCtxKey{}, m)
+}
+
+// MetricsFromContext returns the Metrics carried by ctx, or NoOpMetrics when there is none.
+func MetricsFromContext(ctx context.Context) Metrics {
+	if m, ok := ctx.Value(metricsCtxKey{}).(Metrics); ok {
+		return m
+	}
+	return NoOpMetrics{}
+}
+
+// NoOpMetrics is a Metrics that does nothing.
+type NoOpMetrics struct{}
+
+// MessagesReceived implements Metrics but does nothing.
+func (NoOpMetrics) MessagesReceived(string, int) {}
+
+// ReceiveError implements Metrics but does nothing.
+func (NoOpMetrics) ReceiveError(string, error) {}
+
+// HandlerDuration implements Metrics but does nothing.
+func (NoOpMetrics) HandlerDuration(string, time.Duration) {}
+
+// HandlerError implements Metrics but does nothing.
+func (NoOpMetrics) HandlerError(string, error) {}
+
+// CommitError implements Metrics but does nothing.
+func (NoOpMetrics) CommitError(string, error) {}
+
+// VisibilityExtended implements Metrics but does nothing.
+func (NoOpMetrics) VisibilityExtended(string, time.Duration) {}
+
+// BackedOff implements Metrics but does nothing.
+func (NoOpMetrics) BackedOff(string, time.Duration) {}
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the handler duration histogram
+// kept by InMemoryMetrics. They are copied by NewInMemoryMetrics, changing them afterwards
+// has no effect on the metrics already created.
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// RouteMetrics holds the counters of a single route.
+type RouteMetrics struct {
+	// HandlerDurationBuckets holds the cumulative count of handled messages per
+	// DefaultDurationBuckets upper bound, as they were when the InMemoryMetrics was created
+	HandlerDurationBuckets []int64
+	Polls                  int64
+	MessagesReceived       int64
+	ReceiveErrors          int64
+	HandledMessages        int64
+	HandlerErrors          int64
+	CommitErrors           int64
+	VisibilityExtensions   int64
+	Backoffs               int64
+	HandlerDurationSum     time.Duration
+}
+
+// InMemoryMetrics is a Metrics keeping counters in memory.
+// They can be read with Snapshot or exposed with WritePrometheus.
+type InMemoryMetrics struct {
+	routes  map[string]*RouteMetrics
+	buckets []float64
+	mu      sync.Mutex
+}
+
+// NewInMemoryMetrics creates a new InMemoryMetrics.
+func NewInMemoryMetrics() *InMemoryMetrics {
+	return &InMemoryMetrics{routes: map[string]*RouteMetrics{}, buckets: slices.Clone(DefaultDurationBuckets)}
+}
+
+func (m *InMemoryMetrics) update(route string, fn func(rm *RouteMetrics)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rm, ok := m.routes[route]
+	if !ok {
+		rm = &RouteMetrics{HandlerDurationBuckets: make([]int64, len(m.buckets))}
+		m.routes[route] = rm
+	}
+	fn(rm)
+}
+
+// MessagesReceived implements Metrics.
+func (m *InMemoryMetrics) MessagesReceived(route string, n int) {
+	m.update(route, func(rm *RouteMetrics) {
+		rm.Polls++
+		rm.MessagesReceived += int64(n)
+	})
+}
+
+// ReceiveError implements Metrics.
+func (m *InMemoryMetrics) ReceiveError(route string, _ error) {
+	m.update(route, func(rm *RouteMetrics) {
+		rm.Polls++
+		rm.ReceiveErrors++
+	})
+}
+
+// HandlerDuration implements Metrics.
+func (m *InMemoryMetrics) HandlerDuration(route string, d time.Duration) {
+	m.update(route, func(rm *RouteMetrics) {
+		rm.HandledMessages++
+		rm.HandlerDurationSum += d
+		for i, upper := range m.buckets {
+			if d.Seconds() <= upper {
+				rm.HandlerDurationBuckets[i]++
+			}
+		}
+	})
+}
+
+// HandlerError implements Metrics.
+func (m *InMemoryMetrics) HandlerError(route string, _ error) {
+	m.update(route, func(rm *RouteMetrics) { rm.HandlerErrors++ })
+}
+
+// CommitError implements Metrics.
+func (m *InMemoryMetrics) CommitError(route string, _ error) {
+	m.update(route, func(rm *RouteMetrics) { rm.CommitErrors++ })
+}
+
+// VisibilityExtended implements Metrics.
+func (m *InMemoryMetrics) VisibilityExtended(route string, _ time.Duration) {
+	m.update(route, func(rm *RouteMetrics) { rm.VisibilityExtensions++ })
+}
+
+// BackedOff implements Metrics.
+func (m *InMemoryMetrics) BackedOff(route string, _ time.Duration) {
+	m.update(route, func(rm *RouteMetrics) { rm.Backoffs++ })
+}
+
+// Snapshot returns a copy of the counters of every route.
+func (m *InMemoryMetrics) Snapshot() map[string]RouteMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]RouteMetrics, len(m.routes))
+	for route, rm := range m.routes {
+		c := *rm
+		c.HandlerDurationBuckets = append([]int64(nil), rm.HandlerDurationBuckets...)
+		snapshot[route] = c
+	}
+	return snapshot
+}
+
+// WritePrometheus writes the counters of every route using the Prometheus text exposition format.
+// It can be served as is from a /metrics HTTP handler.
+func (m *InMemoryMetrics) WritePrometheus(w io.Writer) error {
+	snapshot := m.Snapshot()
+	routes := make([]string, 0, len(snapshot))
+	for route := range snapshot {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	counters := []struct {
+		value func(rm RouteMetrics) int64
+		name  string
+		help  string
+	}{
+		{name: "loafer_polls_total", help: "Number of receive calls.",
+			value: func(rm RouteMetrics) int64 { return rm.Polls }},
+		{name: "loafer_messages_received_total", help: "Number of messages received.",
+			value: func(rm RouteMetrics) int64 { return rm.MessagesReceived }},
+		{name: "loafer_receive_errors_total", help: "Number of failed receive calls.",
+			value: func(rm RouteMetrics) int64 { return rm.ReceiveErrors }},
+		{name: "loafer_handler_errors_total", help: "Number of messages the handler failed to handle.",
+			value: func(rm RouteMetrics) int64 { return rm.HandlerErrors }},
+		{name: "loafer_commit_errors_total", help: "Number of handled messages that failed to be committed.",
+			value: func(rm RouteMetrics) int64 { return rm.CommitErrors }},
+		{name: "loafer_visibility_extensions_total", help: "Number of visibility timeout extensions.",
+			value: func(rm RouteMetrics) int64 { return rm.VisibilityExtensions }},
+		{name: "loafer_backoffs_total", help: "Number of backed off messages.",
+			value: func(rm RouteMetrics) int64 { return rm.Backoffs }},
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, c := range counters {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+		for _, route := range routes {
+			fmt.Fprintf(bw, "%s{route=\"%s\"} %d\n", c.name, escapeLabel(route), c.value(snapshot[route]))
+		}
+	}
+
+	const histogram = "loafer_handler_duration_seconds"
+	fmt.Fprintf(bw, "# HELP %s Time spent handling a message.\n# TYPE %s histogram\n", histogram, histogram)
+	for _, route := range routes {
+		rm := snapshot[route]
+		label := escapeLabel(route)
+		for i, upper := range m.buckets {
+			fmt.Fprintf(bw, "%s_bucket{route=\"%s\",le=\"%g\"} %d\n", histogram, label, upper, rm.HandlerDurationBuckets[i])
+		}
+		fmt.Fprintf(bw, "%s_bucket{route=\"%s\",le=\"+Inf\"} %d\n", histogram, label, rm.HandledMessages)
+		fmt.Fprintf(bw, "%s_sum{route=\"%s\"} %g\n", histogram, label, rm.HandlerDurationSum.Seconds())
+		fmt.Fprintf(bw, "%s_count{route=\"%s\"} %d\n", histogram, label, rm.HandledMessages)
+	}
+
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
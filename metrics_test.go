@@ -0,0 +1,93 @@
+package loafergo_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+func TestMetricsFromContext(t *testing.T) {
+	assert.Equal(t, loafergo.NoOpMetrics{}, loafergo.MetricsFromContext(context.Background()))
+
+	metrics := loafergo.NewInMemoryMetrics()
+	ctx := loafergo.ContextWithMetrics(context.Background(), metrics)
+	assert.Same(t, metrics, loafergo.MetricsFromContext(ctx))
+}
+
+func TestInMemoryMetrics_Snapshot(t *testing.T) {
+	metrics := loafergo.NewInMemoryMetrics()
+	metrics.MessagesReceived("example-1", 3)
+	metrics.MessagesReceived("example-1", 0)
+	metrics.ReceiveError("example-1", errors.New("receive failed"))
+	metrics.HandlerDuration("example-1", 20*time.Millisecond)
+	metrics.HandlerDuration("example-1", 2*time.Second)
+	metrics.HandlerError("example-1", errors.New("handler failed"))
+	metrics.CommitError("example-1", errors.New("commit failed"))
+	metrics.VisibilityExtended("example-1", time.Minute)
+	metrics.BackedOff("example-1", time.Second)
+	metrics.MessagesReceived("example-2", 1)
+
+	snapshot := metrics.Snapshot()
+	assert.Len(t, snapshot, 2)
+
+	rm := snapshot["example-1"]
+	assert.Equal(t, int64(3), rm.Polls)
+	assert.Equal(t, int64(3), rm.MessagesReceived)
+	assert.Equal(t, int64(1), rm.ReceiveErrors)
+	assert.Equal(t, int64(2), rm.HandledMessages)
+	assert.Equal(t, 2020*time.Millisecond, rm.HandlerDurationSum)
+	assert.Equal(t, int64(1), rm.HandlerErrors)
+	assert.Equal(t, int64(1), rm.CommitErrors)
+	assert.Equal(t, int64(1), rm.VisibilityExtensions)
+	assert.Equal(t, int64(1), rm.Backoffs)
+	assert.Equal(t, []int64{0, 0, 1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 2}, rm.HandlerDurationBuckets)
+
+	// the snapshot is a copy
+	rm.HandlerDurationBuckets[0] = 42
+	assert.Equal(t, int64(0), metrics.Snapshot()["example-1"].HandlerDurationBuckets[0])
+}
+
+func TestInMemoryMetrics_DefaultDurationBuckets(t *testing.T) {
+	metrics := loafergo.NewInMemoryMetrics()
+	metrics.HandlerDuration("example-1", time.Second)
+
+	buckets := loafergo.DefaultDurationBuckets
+	defer func() { loafergo.DefaultDurationBuckets = buckets }()
+	loafergo.DefaultDurationBuckets = append(slices.Clone(buckets), 120)
+
+	// the metrics keep the buckets they were created with
+	metrics.HandlerDuration("example-1", time.Second)
+	metrics.HandlerDuration("example-2", time.Second)
+	assert.Len(t, metrics.Snapshot()["example-2"].HandlerDurationBuckets, len(buckets))
+	assert.NoError(t, metrics.WritePrometheus(io.Discard))
+}
+
+func TestInMemoryMetrics_WritePrometheus(t *testing.T) {
+	metrics := loafergo.NewInMemoryMetrics()
+	metrics.MessagesReceived("example-2", 1)
+	metrics.MessagesReceived(`example-"1"`, 4)
+	metrics.HandlerDuration(`example-"1"`, 500*time.Millisecond)
+
+	var buf bytes.Buffer
+	err := metrics.WritePrometheus(&buf)
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "# TYPE loafer_messages_received_total counter\n"+
+		"loafer_messages_received_total{route=\"example-\\\"1\\\"\"} 4\n"+
+		"loafer_messages_received_total{route=\"example-2\"} 1\n")
+	assert.Contains(t, out, "# TYPE loafer_handler_duration_seconds histogram\n")
+	assert.Contains(t, out, "loafer_handler_duration_seconds_bucket{route=\"example-\\\"1\\\"\",le=\"0.25\"} 0\n")
+	assert.Contains(t, out, "loafer_handler_duration_seconds_bucket{route=\"example-\\\"1\\\"\",le=\"0.5\"} 1\n")
+	assert.Contains(t, out, "loafer_handler_duration_seconds_bucket{route=\"example-\\\"1\\\"\",le=\"+Inf\"} 1\n")
+	assert.Contains(t, out, "loafer_handler_duration_seconds_sum{route=\"example-\\\"1\\\"\"} 0.5\n")
+	assert.Contains(t, out, "loafer_handler_duration_seconds_count{route=\"example-2\"} 0\n")
+}
@@ -0,0 +1,56 @@
+package loafergo
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+)
+
+// TimeoutMiddleware bounds the time a handler has to handle a single message.
+// The handler context is cancelled once d elapses.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// RecoverMiddleware recovers a panic raised by the handler and returns it as a *PanicError.
+func RecoverMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) (err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					err = &PanicError{Value: v, Stack: debug.Stack()}
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// LoggingMiddleware logs the outcome and the duration of every handled message.
+// The messages settled by an Ack, a Drop or a DeadLetter outcome are logged at info level,
+// the ones failing with a Nack or a RetryAfter outcome at error level.
+func LoggingMiddleware(logger Logger) Middleware {
+	log := StructuredLogger(logger)
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+			outcome := OutcomeOf(err)
+			if outcome.Failed() {
+				log.ErrorContext(ctx, "handler_message_failed",
+					"error", err, "identifier", msg.Identifier(), "duration", time.Since(start), "outcome", outcome.Kind().String())
+				return err
+			}
+
+			log.InfoContext(ctx, "handler_message_succeeded",
+				"identifier", msg.Identifier(), "duration", time.Since(start), "outcome", outcome.Kind().String())
+			return err
+		}
+	}
+}
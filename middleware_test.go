@@ -0,0 +1,113 @@
+package loafergo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	mw := func(name string) loafergo.Middleware {
+		return func(next loafergo.Handler) loafergo.Handler {
+			return func(ctx context.Context, m loafergo.Message) error {
+				calls = append(calls, name)
+				return next(ctx, m)
+			}
+		}
+	}
+
+	h := loafergo.Chain(mw("first"), mw("second"))(func(ctx context.Context, m loafergo.Message) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	err := h(context.Background(), new(fake.Message))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestChain_Empty(t *testing.T) {
+	h := loafergo.Chain()(func(ctx context.Context, m loafergo.Message) error {
+		return errors.New("handler error")
+	})
+
+	assert.EqualError(t, h(context.Background(), new(fake.Message)), "handler error")
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	h := loafergo.TimeoutMiddleware(10 * time.Millisecond)(func(ctx context.Context, m loafergo.Message) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := h(context.Background(), new(fake.Message))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	h := loafergo.RecoverMiddleware()(func(ctx context.Context, m loafergo.Message) error {
+		panic("boom")
+	})
+
+	err := h(context.Background(), new(fake.Message))
+
+	var panicErr *loafergo.PanicError
+	assert.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var logged []string
+	logger := loafergo.LoggerFunc(func(args ...any) {
+		logged = append(logged, fmt.Sprint(args...))
+	})
+
+	t.Run("Should log success", func(t *testing.T) {
+		message := new(fake.Message)
+		message.On("Identifier").Return("id")
+
+		h := loafergo.LoggingMiddleware(logger)(func(ctx context.Context, m loafergo.Message) error {
+			return nil
+		})
+
+		assert.NoError(t, h(context.Background(), message))
+		assert.Contains(t, logged[len(logged)-1], "handler_message_succeeded; identifier: id; duration:")
+	})
+
+	t.Run("Should log failure", func(t *testing.T) {
+		message := new(fake.Message)
+		message.On("Identifier").Return("id")
+
+		h := loafergo.LoggingMiddleware(logger)(func(ctx context.Context, m loafergo.Message) error {
+			return fmt.Errorf("got error")
+		})
+
+		assert.EqualError(t, h(context.Background(), message), "got error")
+		assert.Contains(t, logged[len(logged)-1], "handler_message_failed; error: got error; identifier: id; duration:")
+		assert.Contains(t, logged[len(logged)-1], "outcome: nack")
+	})
+
+	t.Run("Should log settled outcomes as success", func(t *testing.T) {
+		for _, outcome := range []error{loafergo.Ack(), loafergo.Drop(), loafergo.DeadLetter("invalid")} {
+			message := new(fake.Message)
+			message.On("Identifier").Return("id")
+
+			h := loafergo.LoggingMiddleware(logger)(func(ctx context.Context, m loafergo.Message) error {
+				return outcome
+			})
+
+			assert.ErrorIs(t, h(context.Background(), message), outcome)
+			assert.Contains(t, logged[len(logged)-1], "handler_message_succeeded; identifier: id; duration:")
+			assert.Contains(t, logged[len(logged)-1], "outcome: "+loafergo.OutcomeOf(outcome).Kind().String())
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package loafergo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MuxKeyFunc returns the type of a message, used by a Mux to pick its handler.
+// An empty type means the type is unknown.
+type MuxKeyFunc func(msg Message) string
+
+// MuxByAttribute reads the message type from the attribute name,
+// looking up the SNS envelope attributes first, then the native queue attributes.
+func MuxByAttribute(name string) MuxKeyFunc {
+	return func(msg Message) string {
+		return msg.Attribute(name)
+	}
+}
+
+// MuxByEnvelopeField reads the message type from a top-level field of the SNS notification envelope,
+// e.g. Subject or TopicArn.
+func MuxByEnvelopeField(field string) MuxKeyFunc {
+	return func(msg Message) string {
+		return lookupJSONPath(msg.Body(), []string{field})
+	}
+}
+
+// MuxByJSONPath reads the message type from a field of the JSON payload, given as a dotted path
+// with an optional "$." prefix, e.g. "$.detail.type" or "events.0.type".
+// The payload is the Message field of the SNS notification envelope when there is one, the body otherwise.
+// The field must be a string, a number or a boolean.
+func MuxByJSONPath(path string) MuxKeyFunc {
+	keys := strings.Split(strings.TrimPrefix(strings.TrimPrefix(path, "$"), "."), ".")
+	return func(msg Message) string {
+		if payload := msg.Message(); payload != "" {
+			return lookupJSONPath([]byte(payload), keys)
+		}
+		return lookupJSONPath(msg.Body(), keys)
+	}
+}
+
+func lookupJSONPath(data []byte, keys []string) string {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return ""
+	}
+
+	for _, key := range keys {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return ""
+			}
+			v = node[i]
+		default:
+			return ""
+		}
+	}
+
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64, bool:
+		return fmt.Sprint(value)
+	default:
+		return ""
+	}
+}
+
+// MuxConfig are a discrete set of options that are valid for loading a Mux
+type MuxConfig struct {
+	fallback Handler
+}
+
+// MuxWithFallback sets the handler called with the messages of an unknown type.
+// By default, they fail with ErrUnknownMessageType, so they are received again
+// until the queue redrive policy or sqs.RouteWithMaxReceiveCount moves them away.
+//
+// Use sqs.DeadLetterHandler to forward them to a dead-letter queue right away.
+func MuxWithFallback(h Handler) func(*MuxConfig) {
+	return func(c *MuxConfig) {
+		c.fallback = h
+	}
+}
+
+// MuxWithDrop drops the messages of an unknown type with a Drop outcome,
+// they are deleted from the queue without being handled.
+func MuxWithDrop() func(*MuxConfig) {
+	return MuxWithFallback(func(context.Context, Message) error { return Drop() })
+}
+
+// Mux dispatches the messages of a route to a handler according to their type.
+// The handlers must be registered before the route starts.
+//
+// Example:
+//
+//	mux := loafergo.NewMux(loafergo.MuxByAttribute("event_type"), loafergo.MuxWithDrop()).
+//		On("order.created", onOrderCreated).
+//		On("order.cancelled", loafergo.TypedHandler(onOrderCancelled))
+//
+//	sqs.NewRoute(&sqs.Config{
+//		SQSClient: sqsClient,
+//		QueueName: "orders",
+//		Handler:   mux.Handle,
+//	})
+type Mux struct {
+	key      MuxKeyFunc
+	handlers map[string]Handler
+	fallback Handler
+}
+
+// NewMux creates a Mux reading the message type with key
+func NewMux(key MuxKeyFunc, optFns ...func(*MuxConfig)) *Mux {
+	cfg := &MuxConfig{}
+	for _, optFn := range optFns {
+		optFn(cfg)
+	}
+
+	return &Mux{
+		key:      key,
+		handlers: map[string]Handler{},
+		fallback: cfg.fallback,
+	}
+}
+
+// On registers the handler of the messages of type t, replacing the previous one
+func (m *Mux) On(t string, h Handler) *Mux {
+	m.handlers[t] = h
+	return m
+}
+
+// Handle is the Handler dispatching msg to the handler registered for its type
+func (m *Mux) Handle(ctx context.Context, msg Message) error {
+	t := m.key(msg)
+	if h, ok := m.handlers[t]; ok {
+		return h(ctx, msg)
+	}
+
+	if m.fallback == nil {
+		return ErrUnknownMessageType.Context(fmt.Errorf("%q", t))
+	}
+	return m.fallback(ctx, msg)
+}
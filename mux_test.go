@@ -0,0 +1,113 @@
+package loafergo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+func recordingHandler(name string, calls *[]string) loafergo.Handler {
+	return func(ctx context.Context, m loafergo.Message) error {
+		*calls = append(*calls, name)
+		return nil
+	}
+}
+
+func TestMux(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should dispatch by attribute", func(t *testing.T) {
+		var calls []string
+		mux := loafergo.NewMux(loafergo.MuxByAttribute("event_type")).
+			On("created", recordingHandler("created", &calls)).
+			On("cancelled", recordingHandler("cancelled", &calls))
+
+		msg := fake.NewMessage(t)
+		msg.On("Attribute", "event_type").Return("cancelled").Once()
+
+		assert.NoError(t, mux.Handle(ctx, msg))
+		assert.Equal(t, []string{"cancelled"}, calls)
+	})
+
+	t.Run("Should dispatch by envelope field", func(t *testing.T) {
+		var calls []string
+		mux := loafergo.NewMux(loafergo.MuxByEnvelopeField("Subject")).
+			On("created", recordingHandler("created", &calls))
+
+		msg := fake.NewMessage(t)
+		msg.On("Body").Return([]byte(`{"Subject":"created","Message":"{}"}`)).Once()
+
+		assert.NoError(t, mux.Handle(ctx, msg))
+		assert.Equal(t, []string{"created"}, calls)
+	})
+
+	t.Run("Should dispatch by JSON path", func(t *testing.T) {
+		testCases := []struct {
+			name    string
+			path    string
+			body    string
+			payload string
+		}{
+			{name: "body", path: "$.detail.type", body: `{"detail":{"type":"created"}}`},
+			{name: "array", path: "events.1.type", body: `{"events":[{"type":"other"},{"type":"created"}]}`},
+			{name: "envelope message", path: "type", body: `{"Message":"..."}`, payload: `{"type":"created"}`},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				var calls []string
+				mux := loafergo.NewMux(loafergo.MuxByJSONPath(tc.path)).
+					On("created", recordingHandler("created", &calls))
+
+				msg := fake.NewMessage(t)
+				msg.On("Message").Return(tc.payload).Once()
+				if tc.payload == "" {
+					msg.On("Body").Return([]byte(tc.body)).Once()
+				}
+
+				assert.NoError(t, mux.Handle(ctx, msg))
+				assert.Equal(t, []string{"created"}, calls)
+			})
+		}
+	})
+
+	t.Run("Should fail the unknown types by default", func(t *testing.T) {
+		mux := loafergo.NewMux(loafergo.MuxByEnvelopeField("Subject"))
+
+		msg := fake.NewMessage(t)
+		msg.On("Body").Return([]byte(`{"Subject":"unknown"}`)).Once()
+
+		err := mux.Handle(ctx, msg)
+		assert.ErrorIs(t, err, loafergo.ErrUnknownMessageType)
+		assert.ErrorContains(t, err, `"unknown"`)
+	})
+
+	t.Run("Should drop the unknown types", func(t *testing.T) {
+		mux := loafergo.NewMux(loafergo.MuxByJSONPath("type"), loafergo.MuxWithDrop())
+
+		msg := fake.NewMessage(t)
+		msg.On("Message").Return("").Once()
+		msg.On("Body").Return([]byte(`not json`)).Once()
+
+		err := mux.Handle(ctx, msg)
+		assert.Equal(t, loafergo.OutcomeDrop, loafergo.OutcomeOf(err).Kind())
+		assert.False(t, loafergo.OutcomeOf(err).Failed())
+	})
+
+	t.Run("Should call the fallback with the unknown types", func(t *testing.T) {
+		mux := loafergo.NewMux(loafergo.MuxByAttribute("event_type"), loafergo.MuxWithFallback(
+			func(ctx context.Context, m loafergo.Message) error {
+				return errors.New("fallback")
+			},
+		))
+
+		msg := fake.NewMessage(t)
+		msg.On("Attribute", "event_type").Return("").Once()
+
+		assert.EqualError(t, mux.Handle(ctx, msg), "fallback")
+	})
+}
@@ -0,0 +1,296 @@
+// Package outbox implements the transactional outbox pattern for the SNS producer.
+//
+// The messages are inserted with Outbox.Insert in the same database transaction as the
+// business data they describe, so both are committed, or rolled back, together.
+// The relay created by NewRelay, registered in the loafergo.Manager like a route, then
+// publishes the pending messages and marks them sent.
+//
+// The messages are published at least once: a relay stopped between the publication and
+// the update of a row publishes it again. Set a DeduplicationID on the FIFO topics.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/aws/sns"
+	"github.com/justcodes/loafer-go/v2/codec"
+)
+
+const defaultTable = "loafer_outbox"
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Dialect is the SQL dialect of the outbox database
+type Dialect int
+
+const (
+	// SQLite uses ? placeholders, it is the default
+	SQLite Dialect = iota
+	// Postgres uses $1, $2 placeholders
+	Postgres
+	// MySQL uses ? placeholders
+	MySQL
+)
+
+func (d Dialect) idColumn() string {
+	switch d {
+	case Postgres:
+		return "BIGSERIAL PRIMARY KEY"
+	case MySQL:
+		return "BIGINT AUTO_INCREMENT PRIMARY KEY"
+	default:
+		return "INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+}
+
+// Config are a discrete set of options that are valid for loading the Outbox
+type Config struct {
+	codec   loafergo.Codec
+	table   string
+	dialect Dialect
+}
+
+// LoadConfigFunc is a type alias for Config functional config
+type LoadConfigFunc func(config *Config)
+
+// WithTable sets the table holding the messages. The default is loafer_outbox.
+func WithTable(name string) LoadConfigFunc {
+	return func(c *Config) {
+		c.table = name
+	}
+}
+
+// WithDialect sets the SQL dialect of the database. The default is SQLite.
+func WithDialect(d Dialect) LoadConfigFunc {
+	return func(c *Config) {
+		c.dialect = d
+	}
+}
+
+// WithCodec sets the codec marshaling the Payload of the inputs. The default is codec.JSON.
+func WithCodec(c loafergo.Codec) LoadConfigFunc {
+	return func(cfg *Config) {
+		cfg.codec = c
+	}
+}
+
+// Outbox stores the messages to publish in a database/sql table
+type Outbox struct {
+	db    *sql.DB
+	codec loafergo.Codec
+	now   func() time.Time
+	q     queries
+	table string
+}
+
+type queries struct {
+	create  string
+	insert  string
+	pending string
+	claim   string
+	sent    string
+	failed  string
+	release string
+	cleanup string
+}
+
+// New creates an Outbox using db
+func New(db *sql.DB, optFns ...LoadConfigFunc) (*Outbox, error) {
+	if db == nil {
+		return nil, loafergo.ErrEmptyParam
+	}
+
+	cfg := &Config{table: defaultTable, codec: codec.JSON}
+	for _, optFn := range optFns {
+		optFn(cfg)
+	}
+	if !tableName.MatchString(cfg.table) {
+		return nil, fmt.Errorf("invalid table name: %q", cfg.table)
+	}
+
+	q := func(query string) string {
+		query = strings.ReplaceAll(query, "{table}", cfg.table)
+		if cfg.dialect != Postgres {
+			return query
+		}
+		var b strings.Builder
+		n := 0
+		for _, r := range query {
+			if r == '?' {
+				n++
+				fmt.Fprintf(&b, "$%d", n)
+				continue
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+
+	return &Outbox{
+		db:    db,
+		codec: cfg.codec,
+		now:   time.Now,
+		table: cfg.table,
+		q: queries{
+			create: q(`CREATE TABLE IF NOT EXISTS {table} (
+	id ` + cfg.dialect.idColumn() + `,
+	topic_arn VARCHAR(256) NOT NULL,
+	group_id VARCHAR(128) NOT NULL,
+	deduplication_id VARCHAR(128) NOT NULL,
+	message TEXT NOT NULL,
+	attributes TEXT NOT NULL,
+	attempts INTEGER NOT NULL,
+	available_at BIGINT NOT NULL,
+	created_at BIGINT NOT NULL,
+	sent_at BIGINT,
+	last_error TEXT
+)`),
+			insert: q(`INSERT INTO {table} (topic_arn, group_id, deduplication_id, message, attributes, attempts, available_at, created_at) ` +
+				`VALUES (?, ?, ?, ?, ?, 0, ?, ?)`),
+			// the rows of a group are held back while an earlier one is leased, backing off or exhausted,
+			// so they are published in order
+			pending: q(`SELECT o.id, o.topic_arn, o.group_id, o.deduplication_id, o.message, o.attributes, o.attempts, o.created_at ` +
+				`FROM {table} o WHERE o.sent_at IS NULL AND o.attempts < ? AND o.available_at <= ? ` +
+				`AND NOT EXISTS (SELECT 1 FROM {table} p WHERE p.group_id = o.group_id AND p.group_id <> '' ` +
+				`AND p.id < o.id AND p.sent_at IS NULL AND (p.available_at > ? OR p.attempts >= ?)) ` +
+				`ORDER BY o.id LIMIT ?`),
+			claim:   q(`UPDATE {table} SET available_at = ? WHERE id = ? AND sent_at IS NULL AND available_at <= ?`),
+			sent:    q(`UPDATE {table} SET sent_at = ?, last_error = NULL WHERE id = ?`),
+			failed:  q(`UPDATE {table} SET attempts = ?, available_at = ?, last_error = ? WHERE id = ?`),
+			release: q(`UPDATE {table} SET available_at = ? WHERE id = ? AND sent_at IS NULL`),
+			cleanup: q(`DELETE FROM {table} WHERE sent_at IS NOT NULL AND sent_at <= ?`),
+		},
+	}, nil
+}
+
+// CreateTable creates the table holding the messages, when it does not exist
+func (o *Outbox) CreateTable(ctx context.Context) error {
+	_, err := o.db.ExecContext(ctx, o.q.create)
+	return err
+}
+
+// Insert adds the messages to the outbox within tx, they are published once tx is committed.
+// The Payload of an input is marshaled with the outbox codec and stored instead of its Message.
+func (o *Outbox) Insert(ctx context.Context, tx *sql.Tx, inputs ...*sns.PublishInput) error {
+	if tx == nil {
+		return loafergo.ErrEmptyParam
+	}
+
+	now := o.now().UnixMilli()
+	for _, input := range inputs {
+		if input == nil || input.TopicARN == "" {
+			return loafergo.ErrEmptyInput
+		}
+
+		message, attributes := input.Message, input.Attributes
+		if input.Payload != nil {
+			body, attrs, err := codec.Encode(o.codec, input.Payload)
+			if err != nil {
+				return err
+			}
+			for k, v := range attributes {
+				if _, ok := attrs[k]; !ok {
+					attrs[k] = v
+				}
+			}
+			message, attributes = body, attrs
+		}
+		if message == "" {
+			return loafergo.ErrEmptyInput
+		}
+
+		attrs, err := json.Marshal(attributes)
+		if err != nil {
+			return loafergo.ErrMarshal.Context(err)
+		}
+
+		_, err = tx.ExecContext(ctx, o.q.insert,
+			input.TopicARN, input.GroupID, input.DeduplicationID, message, string(attrs), now, now)
+		if err != nil {
+			return fmt.Errorf("failed to insert outbox message; topic: %s  error: %w", input.TopicARN, err)
+		}
+	}
+	return nil
+}
+
+// Cleanup deletes the messages sent more than olderThan ago and returns how many were deleted
+func (o *Outbox) Cleanup(ctx context.Context, olderThan time.Duration) (int64, error) {
+	res, err := o.db.ExecContext(ctx, o.q.cleanup, o.now().Add(-olderThan).UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// pending claims up to limit messages ready to be published, leasing them for lease
+func (o *Outbox) pending(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*record, error) {
+	now := o.now().UnixMilli()
+	rows, err := o.db.QueryContext(ctx, o.q.pending, maxAttempts, now, now, maxAttempts, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*record
+	for rows.Next() {
+		r := &record{}
+		var attrs string
+		if err = rows.Scan(&r.id, &r.topicARN, &r.groupID, &r.deduplicationID, &r.message, &attrs, &r.attempts, &r.createdAt); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		_ = json.Unmarshal([]byte(attrs), &r.attributes)
+		candidates = append(candidates, r)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// another relay can claim a row in the meantime, the later rows of its group are then skipped
+	var claimed []*record
+	skipped := map[string]bool{}
+	for _, r := range candidates {
+		if r.groupID != "" && skipped[r.groupID] {
+			continue
+		}
+
+		res, err := o.db.ExecContext(ctx, o.q.claim, o.now().Add(lease).UnixMilli(), r.id, now)
+		if err != nil {
+			return claimed, err
+		}
+		if n, err := res.RowsAffected(); err != nil || n != 1 {
+			skipped[r.groupID] = true
+			continue
+		}
+		claimed = append(claimed, r)
+	}
+	return claimed, nil
+}
+
+// markSent records the message as sent
+func (o *Outbox) markSent(ctx context.Context, id int64) error {
+	_, err := o.db.ExecContext(ctx, o.q.sent, o.now().UnixMilli(), id)
+	return err
+}
+
+// markFailed records a failed publication, the message is retried after delay until attempts reaches the maximum
+func (o *Outbox) markFailed(ctx context.Context, id int64, attempts int, delay time.Duration, cause error) error {
+	_, err := o.db.ExecContext(ctx, o.q.failed, attempts, o.now().Add(delay).UnixMilli(), cause.Error(), id)
+	return err
+}
+
+// release ends the lease of a message that was not published
+func (o *Outbox) release(ctx context.Context, id int64) error {
+	_, err := o.db.ExecContext(ctx, o.q.release, o.now().UnixMilli(), id)
+	return err
+}
+
+var errNotPublished = errors.New("not published, an earlier message of the group failed")
@@ -0,0 +1,120 @@
+package outbox_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/aws/sns"
+	"github.com/justcodes/loafer-go/v2/outbox"
+)
+
+func newOutbox(t *testing.T) (*outbox.Outbox, *sql.DB) {
+	db, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	o, err := outbox.New(db, outbox.WithTable("outbox"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, o.CreateTable(context.Background())) {
+		t.FailNow()
+	}
+	return o, db
+}
+
+func insert(t *testing.T, o *outbox.Outbox, db *sql.DB, inputs ...*sns.PublishInput) {
+	tx, err := db.Begin()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, o.Insert(context.Background(), tx, inputs...))
+	assert.NoError(t, tx.Commit())
+}
+
+func count(t *testing.T, db *sql.DB, where string) int {
+	var n int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM outbox WHERE "+where).Scan(&n))
+	return n
+}
+
+func TestNew(t *testing.T) {
+	_, err := outbox.New(nil)
+	assert.ErrorIs(t, err, loafergo.ErrEmptyParam)
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	_, err = outbox.New(db, outbox.WithTable("outbox; DROP TABLE users"))
+	assert.Error(t, err)
+
+	_, err = outbox.New(db, outbox.WithDialect(outbox.Postgres))
+	assert.NoError(t, err)
+}
+
+func TestOutbox_Insert(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should insert the messages with the transaction", func(t *testing.T) {
+		o, db := newOutbox(t)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+		assert.NoError(t, o.Insert(ctx, tx, &sns.PublishInput{TopicARN: "topic", Message: "rolled back"}))
+		assert.NoError(t, tx.Rollback())
+		assert.Zero(t, count(t, db, "1 = 1"))
+
+		insert(t, o, db,
+			&sns.PublishInput{TopicARN: "topic", Message: "hello", Attributes: map[string]string{"k": "v"}},
+			&sns.PublishInput{TopicARN: "topic", Payload: map[string]string{"hello": "world"}, GroupID: "g"},
+		)
+		assert.Equal(t, 2, count(t, db, "sent_at IS NULL"))
+
+		var message, attributes string
+		assert.NoError(t, db.QueryRow("SELECT message, attributes FROM outbox WHERE group_id = 'g'").Scan(&message, &attributes))
+		assert.JSONEq(t, `{"hello":"world"}`, message)
+		assert.JSONEq(t, `{"content-type":"application/json"}`, attributes)
+	})
+
+	t.Run("Should fail with invalid inputs", func(t *testing.T) {
+		o, db := newOutbox(t)
+
+		assert.ErrorIs(t, o.Insert(ctx, nil, &sns.PublishInput{TopicARN: "topic", Message: "hello"}), loafergo.ErrEmptyParam)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+		defer func() { _ = tx.Rollback() }()
+		assert.ErrorIs(t, o.Insert(ctx, tx, &sns.PublishInput{Message: "hello"}), loafergo.ErrEmptyInput)
+		assert.ErrorIs(t, o.Insert(ctx, tx, &sns.PublishInput{TopicARN: "topic"}), loafergo.ErrEmptyInput)
+	})
+}
+
+func TestOutbox_Cleanup(t *testing.T) {
+	ctx := context.Background()
+	o, db := newOutbox(t)
+
+	insert(t, o, db,
+		&sns.PublishInput{TopicARN: "topic", Message: "sent"},
+		&sns.PublishInput{TopicARN: "topic", Message: "pending"},
+	)
+	_, err := db.Exec("UPDATE outbox SET sent_at = ? WHERE message = 'sent'", time.Now().Add(-time.Hour).UnixMilli())
+	assert.NoError(t, err)
+
+	n, err := o.Cleanup(ctx, 2*time.Hour)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = o.Cleanup(ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, 1, count(t, db, "message = 'pending'"))
+}
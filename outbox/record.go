@@ -0,0 +1,120 @@
+package outbox
+
+import (
+	"strconv"
+	"time"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/codec"
+)
+
+// record is an outbox row handed to the Manager as a loafergo.Message
+type record struct {
+	attributes      map[string]string
+	topicARN        string
+	groupID         string
+	deduplicationID string
+	message         string
+	id              int64
+	createdAt       int64
+	attempts        int
+	backedOff       bool
+}
+
+var _ loafergo.Message = (*record)(nil)
+
+// Decode will unmarshal the message into a supplied output, using the codec named by its content-type attribute
+func (r *record) Decode(out interface{}) error {
+	return codec.Decode([]byte(r.message), r.attributes, out, nil)
+}
+
+// DecodeMessage is Decode, the outbox messages have no SNS envelope
+func (r *record) DecodeMessage(out any) error {
+	return r.Decode(out)
+}
+
+// Attribute will return the attribute of the message
+func (r *record) Attribute(key string) string {
+	return r.attributes[key]
+}
+
+// Attributes will return the attributes of the message
+func (r *record) Attributes() map[string]string {
+	a := make(map[string]string, len(r.attributes))
+	for k, v := range r.attributes {
+		a[k] = v
+	}
+	return a
+}
+
+// NativeAttributes will return the attributes of the message, as String attributes
+func (r *record) NativeAttributes() map[string]loafergo.MessageAttribute {
+	a := make(map[string]loafergo.MessageAttribute, len(r.attributes))
+	for k, v := range r.attributes {
+		a[k] = loafergo.MessageAttribute{DataType: "String", StringValue: v}
+	}
+	return a
+}
+
+// EnvelopeAttributes returns an empty map, the outbox messages have no SNS envelope
+func (r *record) EnvelopeAttributes() map[string]loafergo.MessageAttribute {
+	return map[string]loafergo.MessageAttribute{}
+}
+
+// SystemAttributeByKey will return the system attribute by key
+func (r *record) SystemAttributeByKey(key string) string {
+	return r.SystemAttributes()[key]
+}
+
+// SystemAttributes will return the topic, the group and deduplication IDs and the number of attempts
+func (r *record) SystemAttributes() map[string]string {
+	return map[string]string{
+		"TopicArn":                r.topicARN,
+		"MessageGroupId":          r.groupID,
+		"MessageDeduplicationId":  r.deduplicationID,
+		"ApproximateReceiveCount": strconv.Itoa(r.attempts + 1),
+	}
+}
+
+// Metadata will return the attributes of the message
+func (r *record) Metadata() map[string]string {
+	return r.Attributes()
+}
+
+// Identifier is the outbox row ID
+func (r *record) Identifier() string {
+	return strconv.FormatInt(r.id, 10)
+}
+
+// MessageID is the outbox row ID
+func (r *record) MessageID() string {
+	return r.Identifier()
+}
+
+// Dispatch does nothing, the relay has no handler to dispatch to
+func (r *record) Dispatch() {}
+
+// Backoff marks the message as backed off, the relay schedules the retries itself
+func (r *record) Backoff(time.Duration) {
+	r.backedOff = true
+}
+
+// BackedOff returns true if the message was backed off
+func (r *record) BackedOff() bool {
+	return r.backedOff
+}
+
+// Body returns the message as []byte
+func (r *record) Body() []byte {
+	return []byte(r.message)
+}
+
+// Message returns the message
+func (r *record) Message() string {
+	return r.message
+}
+
+// TimeStamp returns the time the message was inserted
+func (r *record) TimeStamp() time.Time {
+	return time.UnixMilli(r.createdAt)
+}
@@ -0,0 +1,346 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/aws/sns"
+	"github.com/justcodes/loafer-go/v2/claimcheck"
+)
+
+const (
+	defaultRelayMaxMessages    = 50
+	defaultRelayPollInterval   = time.Second
+	defaultRelayMaxAttempts    = 10
+	defaultRelayBackoffBase    = time.Second
+	defaultRelayBackoffMax     = 5 * time.Minute
+	defaultRelayLease          = 30 * time.Second
+	defaultRelayWorkerPoolSize = 1
+	cleanupInterval            = time.Minute
+)
+
+// RelayConfig are a discrete set of options that are valid for loading the relay
+type RelayConfig struct {
+	maxMessages    int
+	pollInterval   time.Duration
+	maxAttempts    int
+	backoffBase    time.Duration
+	backoffMax     time.Duration
+	lease          time.Duration
+	retention      time.Duration
+	workerPoolSize int32
+}
+
+func loadDefaultRelayConfig() *RelayConfig {
+	return &RelayConfig{
+		maxMessages:    defaultRelayMaxMessages,
+		pollInterval:   defaultRelayPollInterval,
+		maxAttempts:    defaultRelayMaxAttempts,
+		backoffBase:    defaultRelayBackoffBase,
+		backoffMax:     defaultRelayBackoffMax,
+		lease:          defaultRelayLease,
+		workerPoolSize: defaultRelayWorkerPoolSize,
+	}
+}
+
+// LoadRelayConfigFunc is a type alias for RelayConfig functional config
+type LoadRelayConfigFunc func(config *RelayConfig)
+
+// RelayWithMaxMessages sets how many messages are read from the outbox at once.
+// The default is 50, values lower than 1 are ignored.
+func RelayWithMaxMessages(n int) LoadRelayConfigFunc {
+	return func(c *RelayConfig) {
+		if n > 0 {
+			c.maxMessages = n
+		}
+	}
+}
+
+// RelayWithPollInterval sets how long the relay waits before reading the outbox again when it is empty.
+// The default is 1 second.
+func RelayWithPollInterval(d time.Duration) LoadRelayConfigFunc {
+	return func(c *RelayConfig) {
+		c.pollInterval = d
+	}
+}
+
+// RelayWithMaxAttempts sets how many times a message is published before it is given up.
+// A message given up stays in the outbox, with its last error, and holds back the later messages of its group.
+// The default is 10, values lower than 1 are ignored.
+func RelayWithMaxAttempts(n int) LoadRelayConfigFunc {
+	return func(c *RelayConfig) {
+		if n > 0 {
+			c.maxAttempts = n
+		}
+	}
+}
+
+// RelayWithBackoff sets the delay before publishing a failed message again.
+// It doubles at each attempt, starting from base and capped at maxDelay.
+// The default is 1s up to 5m.
+func RelayWithBackoff(base, maxDelay time.Duration) LoadRelayConfigFunc {
+	return func(c *RelayConfig) {
+		c.backoffBase = base
+		c.backoffMax = maxDelay
+	}
+}
+
+// RelayWithLease sets how long the messages read by a relay are hidden from the other relays
+// sharing the outbox. It should be longer than the time publishing them takes. The default is 30 seconds.
+func RelayWithLease(d time.Duration) LoadRelayConfigFunc {
+	return func(c *RelayConfig) {
+		if d > 0 {
+			c.lease = d
+		}
+	}
+}
+
+// RelayWithRetention makes the relay delete the messages sent more than d ago, checking every minute.
+// By default, the sent messages are kept, see Outbox.Cleanup.
+func RelayWithRetention(d time.Duration) LoadRelayConfigFunc {
+	return func(c *RelayConfig) {
+		c.retention = d
+	}
+}
+
+// RelayWithWorkerPoolSize sets the number of workers publishing the messages.
+// The messages of a group are always published by the same worker, in order.
+// The default is 1.
+func RelayWithWorkerPoolSize(n int32) LoadRelayConfigFunc {
+	return func(c *RelayConfig) {
+		if n > 0 {
+			c.workerPoolSize = n
+		}
+	}
+}
+
+func (c *RelayConfig) backoff(attempt int) time.Duration {
+	d := c.backoffBase << (attempt - 1)
+	if d <= 0 || d > c.backoffMax {
+		d = c.backoffMax
+	}
+	return d
+}
+
+// relay is a loafergo.Router publishing the outbox messages
+type relay struct {
+	outbox      *Outbox
+	producer    sns.Producer
+	cfg         *RelayConfig
+	lastCleanup time.Time
+	mu          sync.Mutex
+}
+
+// NewRelay creates a loafergo.Router publishing the messages of o through producer.
+// It is registered in the loafergo.Manager like a route.
+//
+// The relay reads the pending messages in insertion order and publishes them with ProduceBatch,
+// the messages of a group are published in order: when one fails, the later ones wait for its retry.
+// The published messages are marked sent when the Manager commits them.
+func NewRelay(o *Outbox, producer sns.Producer, optFns ...LoadRelayConfigFunc) loafergo.Router {
+	cfg := loadDefaultRelayConfig()
+	for _, optFn := range optFns {
+		optFn(cfg)
+	}
+
+	return &relay{
+		outbox:   o,
+		producer: producer,
+		cfg:      cfg,
+	}
+}
+
+// Configure checks the relay can reach the outbox database
+func (r *relay) Configure(ctx context.Context) error {
+	if r.outbox == nil || r.producer == nil {
+		return loafergo.ErrEmptyRequiredField
+	}
+	return r.outbox.db.PingContext(ctx)
+}
+
+// GetMessages reads the messages ready to be published, waiting for the poll interval when there are none
+func (r *relay) GetMessages(ctx context.Context, logger loafergo.Logger) ([]loafergo.Message, error) {
+	r.cleanup(ctx, logger)
+
+	records, err := r.outbox.pending(ctx, r.cfg.maxMessages, r.cfg.maxAttempts, r.cfg.lease)
+	if len(records) == 0 && err == nil {
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.cfg.pollInterval):
+		}
+		return nil, nil
+	}
+
+	msgs := make([]loafergo.Message, len(records))
+	for i, rec := range records {
+		msgs[i] = rec
+	}
+	return msgs, err
+}
+
+// HandlerMessage publishes a single message
+func (r *relay) HandlerMessage(ctx context.Context, msg loafergo.Message) error {
+	return r.HandlerBatch(ctx, []loafergo.Message{msg}).Err(msg)
+}
+
+// BatchMode returns true, the relay publishes the messages in batches
+func (r *relay) BatchMode(ctx context.Context) bool {
+	return true
+}
+
+// HandlerBatch publishes the messages in batches of the same topic.
+// The failed messages are scheduled for a retry, and the later messages of their groups are released unpublished.
+func (r *relay) HandlerBatch(ctx context.Context, msgs []loafergo.Message) loafergo.BatchResult {
+	result := loafergo.BatchResult{}
+	failedGroups := map[string]bool{}
+
+	var batch []*record
+	var batchSize int
+	flush := func() {
+		if len(batch) > 0 {
+			r.publish(ctx, batch, result, failedGroups)
+		}
+		batch, batchSize = nil, 0
+	}
+
+	for _, msg := range msgs {
+		rec, ok := msg.(*record)
+		if !ok {
+			continue
+		}
+
+		if rec.groupID != "" && failedGroups[groupKey(rec)] {
+			// the release is best effort, the lease expires anyway
+			_ = r.outbox.release(context.WithoutCancel(ctx), rec.id)
+			result.Fail(rec, errNotPublished)
+			continue
+		}
+
+		size := claimcheck.Size(rec.message, rec.attributes)
+		if len(batch) > 0 && (batch[0].topicARN != rec.topicARN ||
+			len(batch) == sns.DefaultMaxBatchSize ||
+			batchSize+size > sns.MaxBatchPayloadSize) {
+			flush()
+		}
+		batch = append(batch, rec)
+		batchSize += size
+	}
+	flush()
+	return result
+}
+
+// publish publishes a batch of messages of the same topic, recording the failures
+func (r *relay) publish(ctx context.Context, batch []*record, result loafergo.BatchResult, failedGroups map[string]bool) {
+	input := &sns.PublishBatchInput{TopicARN: batch[0].topicARN}
+	byID := make(map[string]*record, len(batch))
+	for _, rec := range batch {
+		input.Messages = append(input.Messages, &sns.PublishBatchEntry{
+			Attributes:      rec.attributes,
+			ID:              rec.Identifier(),
+			Message:         rec.message,
+			GroupID:         rec.groupID,
+			DeduplicationID: rec.deduplicationID,
+		})
+		byID[rec.Identifier()] = rec
+	}
+
+	output, err := r.producer.ProduceBatch(ctx, input)
+	if err != nil {
+		for _, rec := range batch {
+			r.fail(ctx, rec, err, false, result, failedGroups)
+		}
+		return
+	}
+
+	for _, failed := range output.Failed {
+		if rec, ok := byID[failed.EntryID]; ok {
+			r.fail(ctx, rec, failed.Err, failed.SenderFault, result, failedGroups)
+		}
+	}
+}
+
+// fail schedules the retry of a message, a sender fault is not retried
+func (r *relay) fail(
+	ctx context.Context,
+	rec *record,
+	err error,
+	senderFault bool,
+	result loafergo.BatchResult,
+	failedGroups map[string]bool,
+) {
+	attempts := rec.attempts + 1
+	if senderFault {
+		attempts = r.cfg.maxAttempts
+	}
+
+	result.Fail(rec, err)
+	failedGroups[groupKey(rec)] = true
+	// the failure must be recorded even when the relay is stopping
+	if markErr := r.outbox.markFailed(context.WithoutCancel(ctx), rec.id, attempts, r.cfg.backoff(attempts), err); markErr != nil {
+		result.Fail(rec, markErr)
+	}
+}
+
+// Commit marks the message as sent
+func (r *relay) Commit(ctx context.Context, m loafergo.Message) error {
+	rec, ok := m.(*record)
+	if !ok {
+		return loafergo.ErrEmptyInput
+	}
+	return r.outbox.markSent(ctx, rec.id)
+}
+
+// cleanup deletes the messages sent before the retention, at most once per cleanup interval
+func (r *relay) cleanup(ctx context.Context, logger loafergo.Logger) {
+	if r.cfg.retention <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	due := time.Since(r.lastCleanup) >= cleanupInterval
+	if due {
+		r.lastCleanup = time.Now()
+	}
+	r.mu.Unlock()
+	if !due {
+		return
+	}
+
+	if _, err := r.outbox.Cleanup(ctx, r.cfg.retention); err != nil && logger != nil {
+		loafergo.StructuredLogger(logger).WarnContext(ctx, "outbox_cleanup_failed", "queue", r.Name(ctx), "error", err)
+	}
+}
+
+// Name returns the outbox table name
+func (r *relay) Name(ctx context.Context) string {
+	if r.outbox == nil {
+		return ""
+	}
+	return r.outbox.table
+}
+
+// WorkerPoolSize returns the relay worker pool size
+func (r *relay) WorkerPoolSize(ctx context.Context) int32 {
+	return r.cfg.workerPoolSize
+}
+
+// VisibilityTimeout returns the lease, in seconds
+func (r *relay) VisibilityTimeout(ctx context.Context) int32 {
+	return int32(r.cfg.lease / time.Second)
+}
+
+// RunMode returns loafergo.PerGroupID, so the messages of a group are published by the same worker
+func (r *relay) RunMode(ctx context.Context) loafergo.Mode {
+	return loafergo.PerGroupID
+}
+
+// CustomGroupFields returns no field, the messages are grouped by their group ID
+func (r *relay) CustomGroupFields(ctx context.Context) []string {
+	return nil
+}
+
+func groupKey(rec *record) string {
+	return rec.topicARN + "|" + rec.groupID
+}
@@ -0,0 +1,227 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/aws/sns"
+	snsfake "github.com/justcodes/loafer-go/v2/aws/sns/fake"
+	"github.com/justcodes/loafer-go/v2/outbox"
+)
+
+// relayOnce reads, publishes and commits the pending messages once, as the Manager does
+func relayOnce(t *testing.T, r loafergo.Router) []loafergo.Message {
+	ctx := context.Background()
+	msgs, err := r.GetMessages(ctx, nil)
+	assert.NoError(t, err)
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	result := r.(loafergo.BatchRouter).HandlerBatch(ctx, msgs)
+	for _, msg := range msgs {
+		if result.Err(msg) == nil {
+			assert.NoError(t, r.Commit(ctx, msg))
+		}
+	}
+	return msgs
+}
+
+func published(input *sns.PublishBatchInput) []string {
+	messages := make([]string, len(input.Messages))
+	for i, entry := range input.Messages {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should publish the messages by topic and mark them sent", func(t *testing.T) {
+		o, db := newOutbox(t)
+		insert(t, o, db,
+			&sns.PublishInput{TopicARN: "a", Message: "1", Attributes: map[string]string{"k": "v"}},
+			&sns.PublishInput{TopicARN: "a", Message: "2", GroupID: "g", DeduplicationID: "d"},
+			&sns.PublishInput{TopicARN: "b", Message: "3"},
+		)
+
+		var batches [][]string
+		producer := snsfake.NewProducer(t)
+		producer.On("ProduceBatch", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				batches = append(batches, published(args.Get(1).(*sns.PublishBatchInput)))
+			}).
+			Return(&sns.PublishBatchOutput{}, nil).Twice()
+
+		r := outbox.NewRelay(o, producer, outbox.RelayWithPollInterval(time.Millisecond))
+		assert.NoError(t, r.Configure(ctx))
+		assert.True(t, r.(loafergo.BatchRouter).BatchMode(ctx))
+		assert.Equal(t, "outbox", r.(loafergo.NamedRouter).Name(ctx))
+		assert.Equal(t, loafergo.PerGroupID, r.RunMode(ctx))
+
+		msgs := relayOnce(t, r)
+		assert.Len(t, msgs, 3)
+		assert.Equal(t, "g", msgs[1].SystemAttributeByKey("MessageGroupId"))
+		assert.Equal(t, "v", msgs[0].Attribute("k"))
+		assert.Equal(t, [][]string{{"1", "2"}, {"3"}}, batches)
+		assert.Equal(t, 3, count(t, db, "sent_at IS NOT NULL"))
+
+		assert.Empty(t, relayOnce(t, r))
+	})
+
+	t.Run("Should hold back the group of a failed message until it is retried", func(t *testing.T) {
+		o, db := newOutbox(t)
+		insert(t, o, db,
+			&sns.PublishInput{TopicARN: "a", Message: "1", GroupID: "g"},
+			&sns.PublishInput{TopicARN: "a", Message: "2", GroupID: "other"},
+		)
+
+		producer := snsfake.NewProducer(t)
+		producer.On("ProduceBatch", mock.Anything, mock.Anything).
+			Return(&sns.PublishBatchOutput{
+				Failed: []*sns.PublishBatchEntryFailed{{EntryID: "1", Err: errors.New("throttled")}},
+			}, nil).Once()
+
+		r := outbox.NewRelay(o, producer,
+			outbox.RelayWithPollInterval(time.Millisecond),
+			outbox.RelayWithBackoff(50*time.Millisecond, time.Second),
+		)
+		assert.Len(t, relayOnce(t, r), 2)
+		assert.Equal(t, 1, count(t, db, "sent_at IS NULL AND attempts = 1 AND last_error = 'throttled'"))
+
+		// a later message of the group waits for the failed one
+		insert(t, o, db, &sns.PublishInput{TopicARN: "a", Message: "3", GroupID: "g"})
+		assert.Empty(t, relayOnce(t, r))
+
+		time.Sleep(60 * time.Millisecond)
+		var batches [][]string
+		producer.On("ProduceBatch", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				batches = append(batches, published(args.Get(1).(*sns.PublishBatchInput)))
+			}).
+			Return(&sns.PublishBatchOutput{}, nil).Once()
+		assert.Len(t, relayOnce(t, r), 2)
+		assert.Equal(t, [][]string{{"1", "3"}}, batches)
+		assert.Zero(t, count(t, db, "sent_at IS NULL"))
+	})
+
+	t.Run("Should release the later messages of a group that failed in the same read", func(t *testing.T) {
+		o, db := newOutbox(t)
+		insert(t, o, db,
+			&sns.PublishInput{TopicARN: "a", Message: "1", GroupID: "g"},
+			&sns.PublishInput{TopicARN: "b", Message: "2"},
+			&sns.PublishInput{TopicARN: "a", Message: "3", GroupID: "g"},
+		)
+
+		producer := snsfake.NewProducer(t)
+		producer.On("ProduceBatch", mock.Anything, mock.Anything).Return(nil, errors.New("unavailable")).Twice()
+
+		r := outbox.NewRelay(o, producer, outbox.RelayWithPollInterval(time.Millisecond))
+		msgs, err := r.GetMessages(ctx, nil)
+		assert.NoError(t, err)
+		result := r.(loafergo.BatchRouter).HandlerBatch(ctx, msgs)
+		assert.EqualError(t, result.Err(msgs[0]), "unavailable")
+		assert.EqualError(t, result.Err(msgs[1]), "unavailable")
+		assert.Error(t, result.Err(msgs[2]))
+		assert.Equal(t, 1, count(t, db, "attempts = 0 AND message = '3'"))
+	})
+
+	t.Run("Should give up a message after the max attempts", func(t *testing.T) {
+		o, db := newOutbox(t)
+		insert(t, o, db, &sns.PublishInput{TopicARN: "a", Message: "1"})
+
+		producer := snsfake.NewProducer(t)
+		producer.On("ProduceBatch", mock.Anything, mock.Anything).
+			Return(&sns.PublishBatchOutput{
+				Failed: []*sns.PublishBatchEntryFailed{{EntryID: "1", Err: errors.New("invalid"), SenderFault: true}},
+			}, nil).Once()
+
+		r := outbox.NewRelay(o, producer,
+			outbox.RelayWithPollInterval(time.Millisecond),
+			outbox.RelayWithMaxAttempts(3),
+			outbox.RelayWithBackoff(time.Millisecond, time.Millisecond),
+		)
+		assert.Len(t, relayOnce(t, r), 1)
+
+		time.Sleep(5 * time.Millisecond)
+		assert.Empty(t, relayOnce(t, r))
+		assert.Equal(t, 1, count(t, db, "sent_at IS NULL AND attempts = 3"))
+	})
+
+	t.Run("Should delete the messages sent before the retention", func(t *testing.T) {
+		o, db := newOutbox(t)
+		insert(t, o, db, &sns.PublishInput{TopicARN: "a", Message: "1"})
+		_, err := db.Exec("UPDATE outbox SET sent_at = ?", time.Now().Add(-time.Hour).UnixMilli())
+		assert.NoError(t, err)
+
+		r := outbox.NewRelay(o, snsfake.NewProducer(t),
+			outbox.RelayWithPollInterval(time.Millisecond),
+			outbox.RelayWithRetention(time.Minute),
+		)
+		assert.Empty(t, relayOnce(t, r))
+		assert.Zero(t, count(t, db, "1 = 1"))
+	})
+
+	t.Run("Should log the failed cleanups", func(t *testing.T) {
+		o, db := newOutbox(t)
+		_, err := db.Exec("DROP TABLE outbox")
+		assert.NoError(t, err)
+
+		var logged []string
+		logger := loafergo.LoggerFunc(func(args ...any) {
+			logged = append(logged, fmt.Sprint(args...))
+		})
+
+		r := outbox.NewRelay(o, snsfake.NewProducer(t), outbox.RelayWithRetention(time.Minute))
+		_, err = r.GetMessages(ctx, logger)
+		assert.Error(t, err)
+		assert.Len(t, logged, 1)
+		assert.Contains(t, logged[0], "outbox_cleanup_failed; queue: outbox; error: ")
+	})
+
+	t.Run("Should fail to configure without producer", func(t *testing.T) {
+		o, _ := newOutbox(t)
+		assert.ErrorIs(t, outbox.NewRelay(o, nil).Configure(ctx), loafergo.ErrEmptyRequiredField)
+	})
+}
+
+func TestRelay_Manager(t *testing.T) {
+	o, db := newOutbox(t)
+	insert(t, o, db,
+		&sns.PublishInput{TopicARN: "a", Message: "1", GroupID: "g"},
+		&sns.PublishInput{TopicARN: "a", Message: "2", GroupID: "g"},
+	)
+
+	done := make(chan struct{})
+	producer := snsfake.NewProducer(t)
+	producer.On("ProduceBatch", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			assert.Equal(t, []string{"1", "2"}, published(args.Get(1).(*sns.PublishBatchInput)))
+			close(done)
+		}).
+		Return(&sns.PublishBatchOutput{}, nil).Once()
+
+	manager := loafergo.NewManager(&loafergo.Config{})
+	manager.RegisterRoute(outbox.NewRelay(o, producer, outbox.RelayWithPollInterval(10*time.Millisecond)))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- manager.Run(context.Background()) }()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("messages not published")
+	}
+	assert.Eventually(t, func() bool { return count(t, db, "sent_at IS NOT NULL") == 2 }, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, manager.Shutdown(context.Background()))
+	assert.NoError(t, <-errCh)
+}
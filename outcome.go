@@ -0,0 +1,165 @@
+package loafergo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OutcomeKind tells how a handled message is settled
+type OutcomeKind int
+
+const (
+	// OutcomeAck deletes the message, it was handled successfully
+	OutcomeAck OutcomeKind = iota
+	// OutcomeNack leaves the message in the queue, it is received again once its visibility expires
+	// or following the route retry policy
+	OutcomeNack
+	// OutcomeRetryAfter leaves the message in the queue and changes its visibility, so it is received again after a delay
+	OutcomeRetryAfter
+	// OutcomeDeadLetter hands the message over to the dead-letter queue of the route, and deletes it
+	OutcomeDeadLetter
+	// OutcomeDrop deletes the message without handling it, e.g. a message no longer relevant
+	OutcomeDrop
+)
+
+// String returns the name of the outcome kind
+func (k OutcomeKind) String() string {
+	switch k {
+	case OutcomeAck:
+		return "ack"
+	case OutcomeNack:
+		return "nack"
+	case OutcomeRetryAfter:
+		return "retry_after"
+	case OutcomeDeadLetter:
+		return "dead_letter"
+	case OutcomeDrop:
+		return "drop"
+	default:
+		return "unknown"
+	}
+}
+
+// Outcome is the explicit result of handling a message, translated by the Manager and the routes
+// into a delete, a visibility change or a dead-letter forwarding.
+//
+// Outcome implements error, so a Handler returns it as is:
+//
+//	func handler(ctx context.Context, m loafergo.Message) error {
+//		if rateLimited {
+//			return loafergo.RetryAfter(time.Minute)
+//		}
+//		return loafergo.Ack()
+//	}
+//
+// A nil error is an Ack, any other error a Nack, see OutcomeOf.
+type Outcome struct {
+	err    error
+	reason string
+	delay  time.Duration
+	kind   OutcomeKind
+}
+
+// Ack settles the message as handled, it is deleted
+func Ack() *Outcome {
+	return &Outcome{kind: OutcomeAck}
+}
+
+// Nack settles the message as failed with err, it is received again
+func Nack(err error) *Outcome {
+	return &Outcome{kind: OutcomeNack, err: err}
+}
+
+// RetryAfter settles the message as failed, it is received again after d
+func RetryAfter(d time.Duration) *Outcome {
+	return &Outcome{kind: OutcomeRetryAfter, delay: d}
+}
+
+// DeadLetter settles the message as not processable for reason, it is dead-lettered
+func DeadLetter(reason string) *Outcome {
+	return &Outcome{kind: OutcomeDeadLetter, reason: reason}
+}
+
+// Drop settles the message as not to be handled, it is deleted
+func Drop() *Outcome {
+	return &Outcome{kind: OutcomeDrop}
+}
+
+// OutcomeOf returns the outcome carried by err: Ack when err is nil,
+// the *Outcome found in its chain, or a Nack of err otherwise.
+func OutcomeOf(err error) *Outcome {
+	if err == nil {
+		return Ack()
+	}
+
+	var o *Outcome
+	if errors.As(err, &o) && o != nil {
+		return o
+	}
+	return Nack(err)
+}
+
+// Kind returns the kind of the outcome
+func (o *Outcome) Kind() OutcomeKind {
+	return o.kind
+}
+
+// Delay returns the delay of a RetryAfter outcome
+func (o *Outcome) Delay() time.Duration {
+	return o.delay
+}
+
+// Reason returns the reason of a DeadLetter outcome
+func (o *Outcome) Reason() string {
+	return o.reason
+}
+
+// Failed reports whether the message stays in the queue, i.e. it is a Nack or a RetryAfter
+func (o *Outcome) Failed() bool {
+	return o.kind == OutcomeNack || o.kind == OutcomeRetryAfter
+}
+
+// Error describes the outcome
+func (o *Outcome) Error() string {
+	switch o.kind {
+	case OutcomeNack:
+		if o.err != nil {
+			return fmt.Sprintf("nack: %s", o.err.Error())
+		}
+	case OutcomeRetryAfter:
+		return fmt.Sprintf("retry after %s", o.delay)
+	case OutcomeDeadLetter:
+		if o.reason != "" {
+			return fmt.Sprintf("dead letter: %s", o.reason)
+		}
+	}
+	return o.kind.String()
+}
+
+// Unwrap returns the error of a Nack outcome
+func (o *Outcome) Unwrap() error {
+	return o.err
+}
+
+// OutcomeHandler handles a message and returns its explicit outcome, a nil outcome is an Ack
+type OutcomeHandler func(context.Context, Message) *Outcome
+
+// Handler adapts the OutcomeHandler to a Handler, to be used by the routes
+func (h OutcomeHandler) Handler() Handler {
+	return func(ctx context.Context, msg Message) error {
+		o := h(ctx, msg)
+		if o == nil || o.kind == OutcomeAck {
+			return nil
+		}
+		return o
+	}
+}
+
+// HandlerOutcome adapts a Handler to an OutcomeHandler, the errors it returns are translated by OutcomeOf
+func HandlerOutcome(h Handler) OutcomeHandler {
+	return func(ctx context.Context, msg Message) *Outcome {
+		return OutcomeOf(h(ctx, msg))
+	}
+}
@@ -0,0 +1,79 @@
+package loafergo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+func TestOutcomeOf(t *testing.T) {
+	handlerErr := errors.New("boom")
+
+	testCases := []struct {
+		name  string
+		err   error
+		kind  loafergo.OutcomeKind
+		error string
+	}{
+		{name: "nil is an ack", err: nil, kind: loafergo.OutcomeAck, error: "ack"},
+		{name: "an error is a nack", err: handlerErr, kind: loafergo.OutcomeNack, error: "nack: boom"},
+		{name: "ack", err: loafergo.Ack(), kind: loafergo.OutcomeAck, error: "ack"},
+		{name: "nack", err: loafergo.Nack(handlerErr), kind: loafergo.OutcomeNack, error: "nack: boom"},
+		{name: "retry after", err: loafergo.RetryAfter(time.Minute), kind: loafergo.OutcomeRetryAfter, error: "retry after 1m0s"},
+		{name: "dead letter", err: loafergo.DeadLetter("invalid"), kind: loafergo.OutcomeDeadLetter, error: "dead letter: invalid"},
+		{name: "drop", err: loafergo.Drop(), kind: loafergo.OutcomeDrop, error: "drop"},
+		{name: "wrapped outcome", err: fmt.Errorf("handling: %w", loafergo.Drop()), kind: loafergo.OutcomeDrop, error: "drop"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := loafergo.OutcomeOf(tc.err)
+			assert.Equal(t, tc.kind, o.Kind())
+			assert.Equal(t, tc.error, o.Error())
+		})
+	}
+
+	assert.ErrorIs(t, loafergo.Nack(handlerErr), handlerErr)
+	assert.Equal(t, time.Minute, loafergo.RetryAfter(time.Minute).Delay())
+	assert.Equal(t, "invalid", loafergo.DeadLetter("invalid").Reason())
+	assert.True(t, loafergo.RetryAfter(time.Minute).Failed())
+	assert.False(t, loafergo.Drop().Failed())
+	assert.Equal(t, "dead_letter", loafergo.OutcomeDeadLetter.String())
+}
+
+func TestOutcomeHandler(t *testing.T) {
+	ctx := context.Background()
+	msg := fake.NewMessage(t)
+
+	t.Run("Should adapt to a Handler", func(t *testing.T) {
+		var outcome *loafergo.Outcome
+		h := loafergo.OutcomeHandler(func(ctx context.Context, m loafergo.Message) *loafergo.Outcome {
+			return outcome
+		}).Handler()
+
+		assert.NoError(t, h(ctx, msg))
+		outcome = loafergo.Ack()
+		assert.NoError(t, h(ctx, msg))
+		outcome = loafergo.RetryAfter(time.Second)
+		assert.Equal(t, loafergo.OutcomeRetryAfter, loafergo.OutcomeOf(h(ctx, msg)).Kind())
+	})
+
+	t.Run("Should adapt a Handler", func(t *testing.T) {
+		var err error
+		h := loafergo.HandlerOutcome(func(ctx context.Context, m loafergo.Message) error {
+			return err
+		})
+
+		assert.Equal(t, loafergo.OutcomeAck, h(ctx, msg).Kind())
+		err = errors.New("boom")
+		assert.Equal(t, loafergo.OutcomeNack, h(ctx, msg).Kind())
+		err = loafergo.Drop()
+		assert.Equal(t, loafergo.OutcomeDrop, h(ctx, msg).Kind())
+	})
+}
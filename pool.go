@@ -0,0 +1,385 @@
+package loafergo
+
+import (
+	"cmp"
+	"context"
+	"hash/fnv"
+	"iter"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ringReplicas is the number of points each worker has on the hash ring
+const ringReplicas = 128
+
+// workerPool runs the workers of a route and hands them the received messages.
+//
+// In Parallel mode the workers share a channel, so a batch goes to the first free one. In PerGroupID mode
+// each worker has its own channel, and the group keys are assigned to the workers by a consistent hash ring:
+// resizing the pool only moves the groups of the workers added or retired.
+type workerPool struct {
+	ctx        context.Context
+	process    func(msgs []Message)
+	perGroupID bool
+	// capacity is the buffer of the worker channels
+	capacity int
+	slots    *slots
+	busy     busyMeter
+	size     atomic.Int64
+
+	// mu is held while the messages are sent to the workers and while the pool is resized
+	mu      sync.Mutex
+	shared  chan []Message
+	workers []*poolWorker
+	ring    hashRing
+	wg      sync.WaitGroup
+}
+
+type poolWorker struct {
+	id    int
+	msgCh chan []Message
+	// retire is closed to stop a worker sharing its channel
+	retire chan struct{}
+}
+
+// newWorkerPool starts size workers processing the batches they receive with process,
+// capacity is the maximum number of workers the pool grows to.
+func newWorkerPool(ctx context.Context, size, capacity int, perGroupID bool, process func(msgs []Message)) *workerPool {
+	p := &workerPool{
+		ctx:        ctx,
+		process:    process,
+		perGroupID: perGroupID,
+		capacity:   capacity,
+		slots:      newSlots(),
+		shared:     make(chan []Message, capacity),
+	}
+	p.busy.reset()
+	p.resize(size)
+	return p
+}
+
+// wait blocks until a worker is free and returns the number of free workers, or zero once ctx is done
+func (p *workerPool) wait(ctx context.Context) int {
+	free, ok := p.slots.wait(ctx, func(free, _ int) bool { return free > 0 })
+	if !ok {
+		return 0
+	}
+	return free
+}
+
+// dispatch sends the messages to the workers, see partition. Each batch holds a slot until it is processed.
+// It returns false if ctx is done before all the batches are sent.
+func (p *workerPool) dispatch(ctx context.Context, msgs []Message, batchMode bool, groupKey func(msg Message) string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for msgCh, batch := range p.partition(msgs, batchMode, groupKey) {
+		p.slots.acquire()
+		select {
+		case msgCh <- batch:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// partition splits the messages by worker channel, p.mu must be held.
+// In batch mode, a worker receives all its messages at once, otherwise each message is sent on its own.
+// In PerGroupID mode, the messages of a group keep their order and go to the same worker.
+func (p *workerPool) partition(msgs []Message, batchMode bool, groupKey func(msg Message) string) iter.Seq2[chan []Message, []Message] {
+	return func(yield func(chan []Message, []Message) bool) {
+		if len(msgs) == 0 {
+			return
+		}
+
+		if !batchMode {
+			for _, msg := range msgs {
+				msgCh := p.shared
+				if p.perGroupID {
+					msgCh = p.ring.get(groupKey(msg)).msgCh
+				}
+				if !yield(msgCh, []Message{msg}) {
+					return
+				}
+			}
+			return
+		}
+
+		if !p.perGroupID {
+			yield(p.shared, msgs)
+			return
+		}
+
+		batches := make(map[*poolWorker][]Message, len(p.workers))
+		for _, msg := range msgs {
+			w := p.ring.get(groupKey(msg))
+			batches[w] = append(batches[w], msg)
+		}
+		for _, w := range p.workers {
+			if batch, ok := batches[w]; ok && !yield(w.msgCh, batch) {
+				return
+			}
+		}
+	}
+}
+
+// scale resizes the pool to size workers. In PerGroupID mode the workers are drained first, so the
+// messages of a group moved to another worker are not handled before the ones sent to the previous one.
+// It returns false if ctx is done before the workers are drained.
+func (p *workerPool) scale(ctx context.Context, size int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.perGroupID {
+		if _, ok := p.slots.wait(ctx, func(_, used int) bool { return used == 0 }); !ok {
+			return false
+		}
+	}
+	p.resize(size)
+	return true
+}
+
+// resize starts or retires workers until the pool has size workers, p.mu must be held.
+// The workers retired are the last ones started, so the ring points of the others do not move.
+func (p *workerPool) resize(size int) {
+	for len(p.workers) < size {
+		w := &poolWorker{id: len(p.workers), msgCh: p.shared, retire: make(chan struct{})}
+		if p.perGroupID {
+			w.msgCh = make(chan []Message, p.capacity)
+		}
+		p.workers = append(p.workers, w)
+		p.wg.Go(func() {
+			p.run(w)
+		})
+	}
+
+	for len(p.workers) > size {
+		w := p.workers[len(p.workers)-1]
+		p.workers = p.workers[:len(p.workers)-1]
+		// a retired worker finishes the batch it holds, and the ones sent on its own channel
+		if p.perGroupID {
+			close(w.msgCh)
+		} else {
+			close(w.retire)
+		}
+	}
+
+	if p.perGroupID {
+		p.ring = newHashRing(p.workers)
+	}
+	p.size.Store(int64(size))
+	p.slots.resize(size)
+}
+
+// utilization returns the fraction of the time the workers spent processing batches since the previous call
+func (p *workerPool) utilization() float64 {
+	busy, elapsed := p.busy.reset()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(busy) / (float64(elapsed) * float64(p.size.Load()))
+}
+
+// close stops the workers once they processed the batches they hold
+func (p *workerPool) close() {
+	p.mu.Lock()
+	if p.perGroupID {
+		for _, w := range p.workers {
+			close(w.msgCh)
+		}
+	} else {
+		close(p.shared)
+	}
+	p.workers = nil
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// run processes the batches sent to the worker until its channel is closed or it is retired.
+// Once the pool context is done, the batches left are not processed, they are received again
+// after their visibility timeout.
+func (p *workerPool) run(w *poolWorker) {
+	for {
+		select {
+		case msgs, ok := <-w.msgCh:
+			if !ok {
+				return
+			}
+			p.handle(msgs)
+		case <-w.retire:
+			return
+		}
+	}
+}
+
+func (p *workerPool) handle(msgs []Message) {
+	defer p.slots.release()
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	p.busy.start()
+	defer p.busy.stop()
+	p.process(msgs)
+}
+
+// slots counts the batches sent to the workers of a route and not processed yet
+type slots struct {
+	mu       sync.Mutex
+	capacity int
+	used     int
+	// changed is closed and replaced each time a slot is released or the capacity changes
+	changed chan struct{}
+}
+
+func newSlots() *slots {
+	return &slots{changed: make(chan struct{})}
+}
+
+// wait blocks until cond holds for the free and the used slots and returns the free ones.
+// It returns false once ctx is done.
+func (s *slots) wait(ctx context.Context, cond func(free, used int) bool) (int, bool) {
+	for {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+
+		s.mu.Lock()
+		free, ok, changed := s.capacity-s.used, cond(s.capacity-s.used, s.used), s.changed
+		s.mu.Unlock()
+		if ok {
+			return free, true
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return 0, false
+		}
+	}
+}
+
+// acquire takes a slot, a route receiving more messages than asked for can take more slots than it has
+func (s *slots) acquire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used++
+}
+
+func (s *slots) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used--
+	s.notify()
+}
+
+func (s *slots) resize(capacity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capacity = capacity
+	s.notify()
+}
+
+// notify wakes up the waiters, s.mu must be held
+func (s *slots) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// busyMeter integrates the number of busy workers over time
+type busyMeter struct {
+	mu     sync.Mutex
+	active int
+	busy   time.Duration
+	since  time.Time
+	last   time.Time
+}
+
+func (b *busyMeter) start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.update()
+	b.active++
+}
+
+func (b *busyMeter) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.update()
+	b.active--
+}
+
+// reset returns the busy time accumulated by the workers and the time elapsed since the previous reset
+func (b *busyMeter) reset() (busy, elapsed time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.update()
+	busy, elapsed = b.busy, b.last.Sub(b.since)
+	b.busy, b.since = 0, b.last
+	return busy, elapsed
+}
+
+// update accounts the time since the last update, b.mu must be held
+func (b *busyMeter) update() {
+	now := time.Now()
+	if !b.last.IsZero() {
+		b.busy += time.Duration(b.active) * now.Sub(b.last)
+	}
+	b.last = now
+}
+
+// hashRing assigns the group keys to the workers by consistent hashing
+type hashRing struct {
+	points  []uint32
+	workers []*poolWorker
+}
+
+func newHashRing(workers []*poolWorker) hashRing {
+	type point struct {
+		hash   uint32
+		worker *poolWorker
+	}
+	points := make([]point, 0, len(workers)*ringReplicas)
+	for _, w := range workers {
+		for i := 0; i < ringReplicas; i++ {
+			points = append(points, point{hash: hash32(strconv.Itoa(w.id) + "#" + strconv.Itoa(i)), worker: w})
+		}
+	}
+	slices.SortStableFunc(points, func(a, b point) int {
+		return cmp.Compare(a.hash, b.hash)
+	})
+
+	r := hashRing{points: make([]uint32, len(points)), workers: make([]*poolWorker, len(points))}
+	for i, p := range points {
+		r.points[i], r.workers[i] = p.hash, p.worker
+	}
+	return r
+}
+
+// get returns the worker of the key, the first one clockwise from its hash
+func (r hashRing) get(key string) *poolWorker {
+	h := hash32(key)
+	i, _ := slices.BinarySearch(r.points, h)
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.workers[i]
+}
+
+// hash32 hashes s with FNV-1a, mixed with the murmur3 finalizer so the similar keys spread over the ring
+func hash32(s string) uint32 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return uint32(x >> 32)
+}
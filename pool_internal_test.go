@@ -0,0 +1,82 @@
+package loafergo
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashRing(t *testing.T) {
+	workers := func(n int) []*poolWorker {
+		ws := make([]*poolWorker, n)
+		for i := range ws {
+			ws[i] = &poolWorker{id: i}
+		}
+		return ws
+	}
+	four, five := newHashRing(workers(4)), newHashRing(workers(5))
+
+	counts := make([]int, 5)
+	for i := range 10000 {
+		key := "group-" + strconv.Itoa(i)
+		before, after := four.get(key).id, five.get(key).id
+		// growing the pool only moves groups to the worker added
+		if before != after {
+			assert.Equal(t, 4, after, key)
+		}
+		counts[after]++
+	}
+	for id, n := range counts {
+		assert.InDelta(t, 2000, n, 600, "worker %d", id)
+	}
+}
+
+func TestWorkerPool_Scale(t *testing.T) {
+	t.Run("Should drain the workers before resizing in PerGroupID mode", func(t *testing.T) {
+		ctx := context.Background()
+		release := make(chan struct{})
+		p := newWorkerPool(ctx, 2, 4, true, func([]Message) { <-release })
+		defer p.close()
+
+		assert.True(t, p.dispatch(ctx, []Message{nil}, false, func(Message) string { return "a" }))
+
+		scaled := make(chan bool)
+		go func() {
+			scaled <- p.scale(ctx, 4)
+		}()
+
+		select {
+		case <-scaled:
+			t.Fatal("scaled before the workers were drained")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		assert.True(t, <-scaled)
+		assert.Len(t, p.workers, 4)
+		assert.Equal(t, 4, p.wait(ctx))
+	})
+
+	t.Run("Should retire the workers in Parallel mode", func(t *testing.T) {
+		ctx := context.Background()
+		p := newWorkerPool(ctx, 3, 3, false, func([]Message) {})
+		assert.True(t, p.scale(ctx, 1))
+		assert.Equal(t, 1, p.wait(ctx))
+		p.close()
+	})
+
+	t.Run("Should stop waiting for the drain when ctx is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		release := make(chan struct{})
+		p := newWorkerPool(context.Background(), 1, 1, true, func([]Message) { <-release })
+
+		assert.True(t, p.dispatch(ctx, []Message{nil}, false, func(Message) string { return "a" }))
+		cancel()
+		assert.False(t, p.scale(ctx, 2))
+		close(release)
+		p.close()
+	})
+}
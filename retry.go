@@ -0,0 +1,119 @@
+package loafergo
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultRetryMultiplier = 2
+	defaultRetryJitter     = 0.5
+)
+
+// RetryPolicy computes how long a failed message waits before being received again.
+//
+// The delay grows exponentially with the receive count of the message, from the base delay
+// up to the max delay, and is jittered so the messages failing together are not retried together.
+// The errors matching a rule added with RetryWhen or RetryWhenAs use the policy of the rule instead.
+//
+// Example:
+//
+//	policy := loafergo.NewRetryPolicy(time.Second, 5*time.Minute,
+//		loafergo.RetryWhen(ErrRateLimited, loafergo.NewRetryPolicy(time.Minute, time.Hour)),
+//	)
+type RetryPolicy struct {
+	rules      []retryRule
+	base       time.Duration
+	maxDelay   time.Duration
+	multiplier float64
+	jitter     float64
+}
+
+type retryRule struct {
+	match  func(err error) bool
+	policy *RetryPolicy
+}
+
+// NewRetryPolicy creates a RetryPolicy waiting base after the first failure, doubling at each
+// failure up to maxDelay, with a jitter of half the delay.
+func NewRetryPolicy(base, maxDelay time.Duration, optFns ...func(*RetryPolicy)) *RetryPolicy {
+	p := &RetryPolicy{
+		base:       base,
+		maxDelay:   maxDelay,
+		multiplier: defaultRetryMultiplier,
+		jitter:     defaultRetryJitter,
+	}
+	for _, optFn := range optFns {
+		optFn(p)
+	}
+	return p
+}
+
+// RetryWithMultiplier sets the factor the delay grows by at each failure. The default is 2,
+// values lower than 1 are ignored.
+func RetryWithMultiplier(m float64) func(*RetryPolicy) {
+	return func(p *RetryPolicy) {
+		if m >= 1 {
+			p.multiplier = m
+		}
+	}
+}
+
+// RetryWithJitter sets the fraction of the delay that is randomized, between 0 and 1.
+// The default is 0.5, so the delay is between half and all of the computed one.
+func RetryWithJitter(fraction float64) func(*RetryPolicy) {
+	return func(p *RetryPolicy) {
+		p.jitter = math.Min(math.Max(fraction, 0), 1)
+	}
+}
+
+// RetryWhen uses policy for the errors matching target with errors.Is.
+// The rules are checked in the order they were added.
+func RetryWhen(target error, policy *RetryPolicy) func(*RetryPolicy) {
+	return func(p *RetryPolicy) {
+		p.rules = append(p.rules, retryRule{
+			match:  func(err error) bool { return errors.Is(err, target) },
+			policy: policy,
+		})
+	}
+}
+
+// RetryWhenAs uses policy for the errors matching the type E with errors.As.
+// The rules are checked in the order they were added.
+func RetryWhenAs[E error](policy *RetryPolicy) func(*RetryPolicy) {
+	return func(p *RetryPolicy) {
+		p.rules = append(p.rules, retryRule{
+			match: func(err error) bool {
+				var target E
+				return errors.As(err, &target)
+			},
+			policy: policy,
+		})
+	}
+}
+
+// Delay returns how long the message failing with err waits before being received again.
+// attempt is the number of times the message was received, starting at 1.
+func (p *RetryPolicy) Delay(err error, attempt int) time.Duration {
+	for _, rule := range p.rules {
+		if rule.match(err) {
+			return rule.policy.Delay(err, attempt)
+		}
+	}
+
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := float64(p.base) * math.Pow(p.multiplier, float64(attempt-1))
+	if d > float64(p.maxDelay) {
+		d = float64(p.maxDelay)
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	return time.Duration(d - rand.Float64()*p.jitter*d)
+}
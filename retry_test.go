@@ -0,0 +1,64 @@
+package loafergo_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+type throttledError struct{}
+
+func (throttledError) Error() string { return "throttled" }
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("Should grow the delay exponentially up to the max delay", func(t *testing.T) {
+		policy := loafergo.NewRetryPolicy(time.Second, 10*time.Second, loafergo.RetryWithJitter(0))
+		testCases := []struct {
+			attempt int
+			want    time.Duration
+		}{
+			{attempt: 0, want: time.Second},
+			{attempt: 1, want: time.Second},
+			{attempt: 2, want: 2 * time.Second},
+			{attempt: 4, want: 8 * time.Second},
+			{attempt: 5, want: 10 * time.Second},
+			{attempt: 100, want: 10 * time.Second},
+		}
+		for _, tc := range testCases {
+			assert.Equal(t, tc.want, policy.Delay(errors.New("boom"), tc.attempt), "attempt %d", tc.attempt)
+		}
+	})
+
+	t.Run("Should use the multiplier", func(t *testing.T) {
+		policy := loafergo.NewRetryPolicy(time.Second, time.Minute,
+			loafergo.RetryWithJitter(0), loafergo.RetryWithMultiplier(3))
+		assert.Equal(t, 9*time.Second, policy.Delay(nil, 3))
+	})
+
+	t.Run("Should jitter the delay", func(t *testing.T) {
+		policy := loafergo.NewRetryPolicy(time.Second, time.Minute)
+		for range 100 {
+			d := policy.Delay(nil, 3)
+			assert.GreaterOrEqual(t, d, 2*time.Second)
+			assert.LessOrEqual(t, d, 4*time.Second)
+		}
+	})
+
+	t.Run("Should classify the errors", func(t *testing.T) {
+		errRateLimited := errors.New("rate limited")
+		policy := loafergo.NewRetryPolicy(time.Second, time.Minute,
+			loafergo.RetryWithJitter(0),
+			loafergo.RetryWhen(errRateLimited, loafergo.NewRetryPolicy(time.Minute, time.Hour, loafergo.RetryWithJitter(0))),
+			loafergo.RetryWhenAs[throttledError](loafergo.NewRetryPolicy(0, 0)),
+		)
+
+		assert.Equal(t, 2*time.Minute, policy.Delay(fmt.Errorf("calling api: %w", errRateLimited), 2))
+		assert.Zero(t, policy.Delay(fmt.Errorf("calling api: %w", throttledError{}), 2))
+		assert.Equal(t, 2*time.Second, policy.Delay(errors.New("boom"), 2))
+	})
+}
@@ -0,0 +1,126 @@
+package loafergo
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	defaultScalingInterval         = 10 * time.Second
+	defaultScaleUpCooldown         = 30 * time.Second
+	defaultScaleDownCooldown       = 2 * time.Minute
+	defaultScalingBacklogPerWorker = 10
+	defaultScalingLowUtilization   = 0.3
+	defaultScalingHighUtilization  = 0.8
+)
+
+// ScalingPolicy grows and shrinks the worker pool of a route between a minimum and a maximum
+// number of workers, following the backlog of its queue and the utilization of its workers.
+//
+// Every interval, the Manager asks the policy how many workers the route needs, see Workers.
+// A scale up is not applied within the scale up cooldown of the previous scaling, and a scale
+// down within the scale down cooldown, so the pool does not flap with the load.
+//
+// Example:
+//
+//	policy := loafergo.NewScalingPolicy(2, 20,
+//		loafergo.ScaleWithBacklogPerWorker(50),
+//		loafergo.ScaleWithCooldowns(time.Minute, 5*time.Minute),
+//	)
+type ScalingPolicy struct {
+	minWorkers        int
+	maxWorkers        int
+	interval          time.Duration
+	scaleUpCooldown   time.Duration
+	scaleDownCooldown time.Duration
+	backlogPerWorker  int
+	lowUtilization    float64
+	highUtilization   float64
+}
+
+// NewScalingPolicy creates a ScalingPolicy keeping between minWorkers, at least one, and maxWorkers workers.
+// It is evaluated every 10 seconds, with a scale up cooldown of 30 seconds and a scale down cooldown of 2 minutes.
+func NewScalingPolicy(minWorkers, maxWorkers int, optFns ...func(*ScalingPolicy)) *ScalingPolicy {
+	p := &ScalingPolicy{
+		minWorkers:        max(minWorkers, 1),
+		interval:          defaultScalingInterval,
+		scaleUpCooldown:   defaultScaleUpCooldown,
+		scaleDownCooldown: defaultScaleDownCooldown,
+		backlogPerWorker:  defaultScalingBacklogPerWorker,
+		lowUtilization:    defaultScalingLowUtilization,
+		highUtilization:   defaultScalingHighUtilization,
+	}
+	p.maxWorkers = max(maxWorkers, p.minWorkers)
+	for _, optFn := range optFns {
+		optFn(p)
+	}
+	return p
+}
+
+// ScaleWithInterval sets how often the policy is evaluated. The default is 10 seconds,
+// values lower than or equal to zero are ignored.
+func ScaleWithInterval(d time.Duration) func(*ScalingPolicy) {
+	return func(p *ScalingPolicy) {
+		if d > 0 {
+			p.interval = d
+		}
+	}
+}
+
+// ScaleWithCooldowns sets how long after a scaling the pool can grow, and shrink.
+// The defaults are 30 seconds and 2 minutes.
+func ScaleWithCooldowns(up, down time.Duration) func(*ScalingPolicy) {
+	return func(p *ScalingPolicy) {
+		p.scaleUpCooldown = max(up, 0)
+		p.scaleDownCooldown = max(down, 0)
+	}
+}
+
+// ScaleWithBacklogPerWorker sets the number of messages waiting in the queue a worker is expected to absorb.
+// The default is 10, values lower than 1 are ignored.
+func ScaleWithBacklogPerWorker(n int) func(*ScalingPolicy) {
+	return func(p *ScalingPolicy) {
+		if n >= 1 {
+			p.backlogPerWorker = n
+		}
+	}
+}
+
+// ScaleWithUtilization sets the fractions of the time the workers spend handling messages
+// below which the pool shrinks, and above which it grows. The defaults are 0.3 and 0.8.
+func ScaleWithUtilization(low, high float64) func(*ScalingPolicy) {
+	return func(p *ScalingPolicy) {
+		p.lowUtilization = math.Min(math.Max(low, 0), 1)
+		p.highUtilization = math.Min(math.Max(high, p.lowUtilization), 1)
+	}
+}
+
+// Workers returns the number of workers a route running workers workers needs.
+//
+// The pool grows when the backlog exceeds what the workers absorb or when their utilization
+// reaches the high one: to the workers absorbing the backlog, and by one worker at least.
+// It shrinks by one worker when one worker less absorbs the backlog and the utilization is
+// at most the low one. A negative backlog is unknown, only the utilization is followed then.
+func (p *ScalingPolicy) Workers(workers, backlog int, utilization float64) int {
+	desired := workers
+	switch {
+	case backlog > workers*p.backlogPerWorker || utilization >= p.highUtilization:
+		desired = max(workers+1, (backlog+p.backlogPerWorker-1)/p.backlogPerWorker)
+	case backlog <= (workers-1)*p.backlogPerWorker && utilization <= p.lowUtilization:
+		desired = workers - 1
+	}
+	return p.clamp(desired)
+}
+
+// clamp returns the number of workers closest to n within the policy bounds
+func (p *ScalingPolicy) clamp(n int) int {
+	return min(max(n, p.minWorkers), p.maxWorkers)
+}
+
+// cooldown returns how long after a scaling the pool can be scaled from workers to desired
+func (p *ScalingPolicy) cooldown(workers, desired int) time.Duration {
+	if desired < workers {
+		return p.scaleDownCooldown
+	}
+	return p.scaleUpCooldown
+}
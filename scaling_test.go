@@ -0,0 +1,52 @@
+package loafergo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+func TestScalingPolicy(t *testing.T) {
+	t.Run("Should follow the backlog and the utilization", func(t *testing.T) {
+		policy := loafergo.NewScalingPolicy(2, 8)
+		testCases := []struct {
+			name        string
+			workers     int
+			backlog     int
+			utilization float64
+			want        int
+		}{
+			{name: "steady", workers: 4, backlog: 35, utilization: 0.5, want: 4},
+			{name: "backlog growing", workers: 4, backlog: 41, utilization: 0.5, want: 5},
+			{name: "backlog burst", workers: 4, backlog: 75, utilization: 0.5, want: 8},
+			{name: "backlog over the max", workers: 4, backlog: 1000, utilization: 0.5, want: 8},
+			{name: "busy workers", workers: 4, backlog: 0, utilization: 0.9, want: 5},
+			{name: "idle workers", workers: 4, backlog: 0, utilization: 0.1, want: 3},
+			{name: "idle workers with a backlog", workers: 4, backlog: 35, utilization: 0.1, want: 4},
+			{name: "idle at the min", workers: 2, backlog: 0, utilization: 0, want: 2},
+			{name: "unknown backlog", workers: 4, backlog: -1, utilization: 0.1, want: 3},
+			{name: "under the min", workers: 1, backlog: 0, utilization: 0.5, want: 2},
+		}
+		for _, tc := range testCases {
+			assert.Equal(t, tc.want, policy.Workers(tc.workers, tc.backlog, tc.utilization), tc.name)
+		}
+	})
+
+	t.Run("Should use the options", func(t *testing.T) {
+		policy := loafergo.NewScalingPolicy(1, 10,
+			loafergo.ScaleWithBacklogPerWorker(100),
+			loafergo.ScaleWithUtilization(0.5, 0.6),
+		)
+		assert.Equal(t, 3, policy.Workers(2, 250, 0.55))
+		assert.Equal(t, 3, policy.Workers(2, 0, 0.6))
+		assert.Equal(t, 1, policy.Workers(2, 100, 0.5))
+	})
+
+	t.Run("Should keep at least one worker", func(t *testing.T) {
+		policy := loafergo.NewScalingPolicy(0, 0)
+		assert.Equal(t, 1, policy.Workers(1, 0, 0))
+		assert.Equal(t, 1, policy.Workers(1, 100, 1))
+	})
+}
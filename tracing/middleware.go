@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+)
+
+// OutcomeAttribute is the span attribute holding the outcome of the handled message, see loafergo.Outcome
+const OutcomeAttribute = attribute.Key("loafer.outcome")
+
+// Middleware extracts the trace context propagated in the message attributes and starts a consumer span,
+// child of the remote producer span, around the handler. queueName names the span and its destination.
+//
+// It is registered on the route, with sqs.RouteWithMiddleware, or on every route with Config.Middlewares.
+// The span records the outcome of the message, its status is an error when the message failed.
+func Middleware(queueName string, optFns ...LoadConfigFunc) loafergo.Middleware {
+	cfg := loadConfig(optFns)
+	tracer := cfg.tracer()
+
+	return func(next loafergo.Handler) loafergo.Handler {
+		return func(ctx context.Context, msg loafergo.Message) error {
+			ctx = cfg.propagator.Extract(ctx, propagation.MapCarrier(msg.Attributes()))
+
+			attrs := []attribute.KeyValue{
+				semconv.MessagingSystemAWSSQS,
+				semconv.MessagingOperationTypeProcess,
+				semconv.MessagingOperationName("process"),
+				semconv.MessagingDestinationName(queueName),
+			}
+			if id := msg.MessageID(); id != "" {
+				attrs = append(attrs, semconv.MessagingMessageID(id))
+			}
+
+			ctx, span := tracer.Start(ctx, "process "+queueName,
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			err := next(ctx, msg)
+			outcome := loafergo.OutcomeOf(err)
+			span.SetAttributes(OutcomeAttribute.String(outcome.Kind().String()))
+			if outcome.Kind() == loafergo.OutcomeNack {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/justcodes/loafer-go/v2/aws/sns"
+	"github.com/justcodes/loafer-go/v2/aws/sqs"
+)
+
+// producerTracer starts the producer spans and injects their context in the message attributes
+type producerTracer struct {
+	cfg       *Config
+	tracer    trace.Tracer
+	system    attribute.KeyValue
+	operation string
+}
+
+func newProducerTracer(system attribute.KeyValue, operation string, optFns []LoadConfigFunc) producerTracer {
+	cfg := loadConfig(optFns)
+	return producerTracer{cfg: cfg, tracer: cfg.tracer(), system: system, operation: operation}
+}
+
+func (t producerTracer) start(ctx context.Context, destination string, count int) (context.Context, trace.Span) {
+	name := destinationName(destination)
+	attrs := []attribute.KeyValue{
+		t.system,
+		semconv.MessagingOperationTypeSend,
+		semconv.MessagingOperationName(t.operation),
+		semconv.MessagingDestinationName(name),
+	}
+	if count > 1 {
+		attrs = append(attrs, semconv.MessagingBatchMessageCount(count))
+	}
+
+	return t.tracer.Start(ctx, "send "+name,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// inject returns a copy of the attributes carrying the trace context of ctx
+func (t producerTracer) inject(ctx context.Context, attributes map[string]string) map[string]string {
+	carrier := propagation.MapCarrier(maps.Clone(attributes))
+	if carrier == nil {
+		carrier = propagation.MapCarrier{}
+	}
+	t.cfg.propagator.Inject(ctx, carrier)
+	return carrier
+}
+
+func (t producerTracer) end(span trace.Span, messageID string, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	if messageID != "" {
+		span.SetAttributes(semconv.MessagingMessageID(messageID))
+	}
+}
+
+func (t producerTracer) endBatch(span trace.Span, failed int, err error) {
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("%d messages failed", failed)
+	}
+	t.end(span, "", err)
+}
+
+type snsProducer struct {
+	next sns.Producer
+	producerTracer
+}
+
+// WrapSNSProducer returns an sns.Producer starting a producer span around every publication
+// and propagating its context in the message attributes.
+func WrapSNSProducer(p sns.Producer, optFns ...LoadConfigFunc) sns.Producer {
+	return &snsProducer{next: p, producerTracer: newProducerTracer(semconv.MessagingSystemAWSSNS, "publish", optFns)}
+}
+
+func (p *snsProducer) Produce(ctx context.Context, input *sns.PublishInput) (string, error) {
+	if input == nil {
+		return p.next.Produce(ctx, input)
+	}
+
+	ctx, span := p.start(ctx, input.TopicARN, 1)
+	defer span.End()
+
+	in := *input
+	in.Attributes = p.inject(ctx, input.Attributes)
+	id, err := p.next.Produce(ctx, &in)
+	p.end(span, id, err)
+	return id, err
+}
+
+func (p *snsProducer) ProduceBatch(ctx context.Context, input *sns.PublishBatchInput) (*sns.PublishBatchOutput, error) {
+	if input == nil {
+		return p.next.ProduceBatch(ctx, input)
+	}
+
+	ctx, span := p.start(ctx, input.TopicARN, len(input.Messages))
+	defer span.End()
+
+	in := *input
+	in.Messages = p.injectEntries(ctx, input.Messages)
+	output, err := p.next.ProduceBatch(ctx, &in)
+	p.endBatch(span, failedCount(output), err)
+	return output, err
+}
+
+func (p *snsProducer) ProduceAll(
+	ctx context.Context,
+	topicARN string,
+	entries []*sns.PublishBatchEntry,
+	optFns ...sns.LoadProduceAllConfigFunc,
+) (*sns.PublishBatchOutput, error) {
+	ctx, span := p.start(ctx, topicARN, len(entries))
+	defer span.End()
+
+	output, err := p.next.ProduceAll(ctx, topicARN, p.injectEntries(ctx, entries), optFns...)
+	p.endBatch(span, failedCount(output), err)
+	return output, err
+}
+
+func (p *snsProducer) ProduceEvent(ctx context.Context, input *sns.PublishEventInput) (string, error) {
+	if input == nil {
+		return p.next.ProduceEvent(ctx, input)
+	}
+
+	ctx, span := p.start(ctx, input.TopicARN, 1)
+	defer span.End()
+
+	in := *input
+	in.Attributes = p.inject(ctx, input.Attributes)
+	id, err := p.next.ProduceEvent(ctx, &in)
+	p.end(span, id, err)
+	return id, err
+}
+
+func (p *snsProducer) injectEntries(ctx context.Context, entries []*sns.PublishBatchEntry) []*sns.PublishBatchEntry {
+	injected := make([]*sns.PublishBatchEntry, len(entries))
+	for i, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		e := *entry
+		e.Attributes = p.inject(ctx, entry.Attributes)
+		injected[i] = &e
+	}
+	return injected
+}
+
+func failedCount(output *sns.PublishBatchOutput) int {
+	if output == nil {
+		return 0
+	}
+	return len(output.Failed)
+}
+
+type sqsProducer struct {
+	next sqs.Producer
+	producerTracer
+}
+
+// WrapSQSProducer returns an sqs.Producer starting a producer span around every send
+// and propagating its context in the message attributes.
+func WrapSQSProducer(p sqs.Producer, optFns ...LoadConfigFunc) sqs.Producer {
+	return &sqsProducer{next: p, producerTracer: newProducerTracer(semconv.MessagingSystemAWSSQS, "send", optFns)}
+}
+
+func (p *sqsProducer) Produce(ctx context.Context, input *sqs.SendInput) (string, error) {
+	if input == nil {
+		return p.next.Produce(ctx, input)
+	}
+
+	ctx, span := p.start(ctx, input.QueueURL, 1)
+	defer span.End()
+
+	in := *input
+	in.Attributes = p.inject(ctx, input.Attributes)
+	id, err := p.next.Produce(ctx, &in)
+	p.end(span, id, err)
+	return id, err
+}
+
+func (p *sqsProducer) ProduceBatch(ctx context.Context, input *sqs.SendBatchInput) (*sqs.SendBatchOutput, error) {
+	if input == nil {
+		return p.next.ProduceBatch(ctx, input)
+	}
+
+	ctx, span := p.start(ctx, input.QueueURL, len(input.Messages))
+	defer span.End()
+
+	in := *input
+	in.Messages = make([]*sqs.SendBatchEntry, len(input.Messages))
+	for i, entry := range input.Messages {
+		if entry == nil {
+			continue
+		}
+		e := *entry
+		e.Attributes = p.inject(ctx, entry.Attributes)
+		in.Messages[i] = &e
+	}
+
+	output, err := p.next.ProduceBatch(ctx, &in)
+	var failed int
+	if output != nil {
+		failed = len(output.Failed)
+	}
+	p.endBatch(span, failed, err)
+	return output, err
+}
+
+func (p *sqsProducer) ProduceEvent(ctx context.Context, input *sqs.SendEventInput) (string, error) {
+	if input == nil {
+		return p.next.ProduceEvent(ctx, input)
+	}
+
+	ctx, span := p.start(ctx, input.QueueURL, 1)
+	defer span.End()
+
+	in := *input
+	in.Attributes = p.inject(ctx, input.Attributes)
+	id, err := p.next.ProduceEvent(ctx, &in)
+	p.end(span, id, err)
+	return id, err
+}
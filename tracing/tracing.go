@@ -0,0 +1,65 @@
+// Package tracing propagates the OpenTelemetry traces from the producers to the handlers.
+//
+// The producers wrapped with WrapSNSProducer and WrapSQSProducer start a producer span and
+// inject its context, the W3C traceparent, tracestate and baggage, into the message attributes.
+// The Middleware extracts it before calling the handler, so the handler context carries the
+// remote span context, and starts a consumer span child of it.
+//
+// The spans follow the OpenTelemetry semantic conventions for messaging.
+// The propagated context takes up to three of the ten message attributes allowed by SNS and SQS.
+package tracing
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/justcodes/loafer-go/v2/tracing"
+
+// Config are a discrete set of options that are valid for loading the tracing wrappers and middleware
+type Config struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// LoadConfigFunc is a type alias for Config functional config
+type LoadConfigFunc func(config *Config)
+
+// WithTracerProvider sets the provider of the tracer starting the spans.
+// The default is the global provider, see otel.SetTracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) LoadConfigFunc {
+	return func(c *Config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithPropagator sets the propagator injecting and extracting the context in the message attributes.
+// The default propagates the W3C trace context and baggage.
+func WithPropagator(p propagation.TextMapPropagator) LoadConfigFunc {
+	return func(c *Config) {
+		c.propagator = p
+	}
+}
+
+func loadConfig(optFns []LoadConfigFunc) *Config {
+	cfg := &Config{
+		tracerProvider: otel.GetTracerProvider(),
+		propagator:     propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+	for _, optFn := range optFns {
+		optFn(cfg)
+	}
+	return cfg
+}
+
+func (c *Config) tracer() trace.Tracer {
+	return c.tracerProvider.Tracer(instrumentationName)
+}
+
+// destinationName returns the topic or queue name of an ARN or a queue URL
+func destinationName(s string) string {
+	return s[strings.LastIndexAny(s, ":/")+1:]
+}
@@ -0,0 +1,198 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/aws/sns"
+	snsfake "github.com/justcodes/loafer-go/v2/aws/sns/fake"
+	"github.com/justcodes/loafer-go/v2/aws/sqs"
+	sqsfake "github.com/justcodes/loafer-go/v2/aws/sqs/fake"
+	"github.com/justcodes/loafer-go/v2/fake"
+	"github.com/justcodes/loafer-go/v2/tracing"
+)
+
+func newTracerProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestPropagation(t *testing.T) {
+	tp, exporter := newTracerProvider()
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "http request")
+	member, _ := baggage.NewMember("tenant", "acme")
+	bag, _ := baggage.New(member)
+	ctx = baggage.ContextWithBaggage(ctx, bag)
+
+	var published *sns.PublishInput
+	next := snsfake.NewProducer(t)
+	next.On("Produce", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { published = args.Get(1).(*sns.PublishInput) }).
+		Return("message-id", nil).Once()
+
+	attributes := map[string]string{"event_type": "created"}
+	producer := tracing.WrapSNSProducer(next, tracing.WithTracerProvider(tp))
+	_, err := producer.Produce(ctx, &sns.PublishInput{
+		TopicARN:   "arn:aws:sns:us-east-1:000000000000:orders",
+		Message:    "{}",
+		Attributes: attributes,
+	})
+	assert.NoError(t, err)
+	parent.End()
+
+	assert.Len(t, attributes, 1, "the caller attributes are not modified")
+	assert.Equal(t, "created", published.Attributes["event_type"])
+	assert.NotEmpty(t, published.Attributes["traceparent"])
+	assert.Equal(t, "tenant=acme", published.Attributes["baggage"])
+
+	msg := fake.NewMessage(t)
+	msg.On("Attributes").Return(published.Attributes).Once()
+	msg.On("MessageID").Return("sqs-message-id").Once()
+
+	var handlerCtx context.Context
+	handler := tracing.Middleware("orders-queue", tracing.WithTracerProvider(tp))(
+		func(ctx context.Context, m loafergo.Message) error {
+			handlerCtx = ctx
+			return nil
+		})
+	assert.NoError(t, handler(context.Background(), msg))
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+	send, process := spans[0], spans[2]
+
+	assert.Equal(t, "send orders", send.Name)
+	assert.Equal(t, trace.SpanKindProducer, send.SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), send.Parent.SpanID())
+	assert.Equal(t, "aws.sns", spanAttribute(send, semconv.MessagingSystemKey).AsString())
+	assert.Equal(t, "orders", spanAttribute(send, semconv.MessagingDestinationNameKey).AsString())
+	assert.Equal(t, "message-id", spanAttribute(send, semconv.MessagingMessageIDKey).AsString())
+
+	assert.Equal(t, "process orders-queue", process.Name)
+	assert.Equal(t, trace.SpanKindConsumer, process.SpanKind)
+	assert.Equal(t, send.SpanContext.TraceID(), process.SpanContext.TraceID())
+	assert.Equal(t, send.SpanContext.SpanID(), process.Parent.SpanID())
+	assert.Equal(t, "aws_sqs", spanAttribute(process, semconv.MessagingSystemKey).AsString())
+	assert.Equal(t, "ack", spanAttribute(process, tracing.OutcomeAttribute).AsString())
+
+	assert.Equal(t, process.SpanContext.SpanID(), trace.SpanContextFromContext(handlerCtx).SpanID())
+	assert.Equal(t, "acme", baggage.FromContext(handlerCtx).Member("tenant").Value())
+}
+
+func TestMiddleware_Outcome(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		outcome string
+		status  codes.Code
+	}{
+		{name: "failed message", err: errors.New("boom"), outcome: "nack", status: codes.Error},
+		{name: "retried message", err: loafergo.RetryAfter(0), outcome: "retry_after", status: codes.Unset},
+		{name: "dropped message", err: loafergo.Drop(), outcome: "drop", status: codes.Unset},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp, exporter := newTracerProvider()
+			msg := fake.NewMessage(t)
+			msg.On("Attributes").Return(map[string]string{}).Once()
+			msg.On("MessageID").Return("").Once()
+
+			handler := tracing.Middleware("orders-queue", tracing.WithTracerProvider(tp))(
+				func(ctx context.Context, m loafergo.Message) error {
+					return tc.err
+				})
+			assert.Equal(t, tc.err, handler(context.Background(), msg))
+
+			spans := exporter.GetSpans()
+			if assert.Len(t, spans, 1) {
+				assert.False(t, spans[0].Parent.IsValid())
+				assert.Equal(t, tc.outcome, spanAttribute(spans[0], tracing.OutcomeAttribute).AsString())
+				assert.Equal(t, tc.status, spans[0].Status.Code)
+			}
+		})
+	}
+}
+
+func TestWrapSNSProducer_Batch(t *testing.T) {
+	tp, exporter := newTracerProvider()
+
+	next := snsfake.NewProducer(t)
+	next.On("ProduceBatch", mock.Anything, mock.MatchedBy(func(in *sns.PublishBatchInput) bool {
+		return in.Messages[0].Attributes["traceparent"] != "" && in.Messages[1].Attributes["traceparent"] != ""
+	})).Return(&sns.PublishBatchOutput{Failed: []*sns.PublishBatchEntryFailed{{EntryID: "2"}}}, nil).Once()
+	next.On("ProduceAll", mock.Anything, "orders", mock.MatchedBy(func(entries []*sns.PublishBatchEntry) bool {
+		return entries[0].Attributes["traceparent"] != ""
+	})).Return(&sns.PublishBatchOutput{}, nil).Once()
+
+	producer := tracing.WrapSNSProducer(next, tracing.WithTracerProvider(tp))
+	entries := []*sns.PublishBatchEntry{{ID: "1", Message: "a"}, {ID: "2", Message: "b"}}
+
+	_, err := producer.ProduceBatch(context.Background(), &sns.PublishBatchInput{TopicARN: "orders", Messages: entries})
+	assert.NoError(t, err)
+	_, err = producer.ProduceAll(context.Background(), "orders", entries[:1])
+	assert.NoError(t, err)
+	assert.Nil(t, entries[0].Attributes, "the caller entries are not modified")
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, int64(2), spanAttribute(spans[0], semconv.MessagingBatchMessageCountKey).AsInt64())
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, codes.Unset, spans[1].Status.Code)
+	}
+}
+
+func TestWrapSQSProducer(t *testing.T) {
+	tp, exporter := newTracerProvider()
+	sendErr := errors.New("unavailable")
+
+	next := sqsfake.NewProducer(t)
+	next.On("Produce", mock.Anything, mock.MatchedBy(func(in *sqs.SendInput) bool {
+		return in.Attributes["traceparent"] != ""
+	})).Return("", sendErr).Once()
+	next.On("ProduceBatch", mock.Anything, mock.MatchedBy(func(in *sqs.SendBatchInput) bool {
+		return in.Messages[0].Attributes["traceparent"] != ""
+	})).Return(&sqs.SendBatchOutput{}, nil).Once()
+
+	producer := tracing.WrapSQSProducer(next, tracing.WithTracerProvider(tp))
+	queueURL := "https://sqs.us-east-1.amazonaws.com/000000000000/orders-queue"
+
+	_, err := producer.Produce(context.Background(), &sqs.SendInput{QueueURL: queueURL, Message: "{}"})
+	assert.ErrorIs(t, err, sendErr)
+	_, err = producer.ProduceBatch(context.Background(), &sqs.SendBatchInput{
+		QueueURL: queueURL,
+		Messages: []*sqs.SendBatchEntry{{ID: "1", Message: "{}"}},
+	})
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "send orders-queue", spans[0].Name)
+		assert.Equal(t, "aws_sqs", spanAttribute(spans[0], semconv.MessagingSystemKey).AsString())
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Len(t, spans[0].Events, 1)
+		assert.Equal(t, codes.Unset, spans[1].Status.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package loafergo
+
+import "context"
+
+// DecodeSource selects the part of the message a TypedHandler decodes
+type DecodeSource int
+
+const (
+	// DecodeBody decodes the raw message body, see Message.Decode
+	DecodeBody DecodeSource = iota
+	// DecodeEnvelope decodes the Message field of the SNS notification envelope, see Message.DecodeMessage
+	DecodeEnvelope
+)
+
+// UndecodableFunc handles a message that could not be decoded into the handler type.
+// Its result is the handler result: returning nil deletes the message from the queue.
+type UndecodableFunc func(ctx context.Context, msg Message, err error) error
+
+// TypedHandlerConfig are a discrete set of options that are valid for loading a TypedHandler
+type TypedHandlerConfig struct {
+	undecodable UndecodableFunc
+	source      DecodeSource
+}
+
+// TypedHandlerWithSource sets the part of the message decoded into the handler type.
+// The default source is DecodeBody.
+func TypedHandlerWithSource(s DecodeSource) func(*TypedHandlerConfig) {
+	return func(c *TypedHandlerConfig) {
+		c.source = s
+	}
+}
+
+// TypedHandlerWithUndecodable sets the function called with the messages that cannot be decoded,
+// instead of the typed handler. It can be used to delete, park or retry poison messages.
+//
+// By default, the messages are dead-lettered, see DeadLetterUndecodable.
+func TypedHandlerWithUndecodable(fn UndecodableFunc) func(*TypedHandlerConfig) {
+	return func(c *TypedHandlerConfig) {
+		c.undecodable = fn
+	}
+}
+
+// DeadLetterUndecodable settles the message with a DeadLetter outcome, since decoding it again cannot succeed.
+// The reason is the decode error wrapped in ErrUndecodable. A route unable to dead-letter the message
+// leaves it in the queue, until the redrive policy of the queue moves it.
+func DeadLetterUndecodable(_ context.Context, _ Message, err error) error {
+	return DeadLetter(ErrUndecodable.Context(err).Error())
+}
+
+// RetryUndecodable returns the decode error wrapped in ErrUndecodable, a Nack, so the message is received again.
+// It suits the payloads that may become decodable, e.g. when the consumers are deployed after their producers.
+func RetryUndecodable(_ context.Context, _ Message, err error) error {
+	return ErrUndecodable.Context(err)
+}
+
+// TypedHandler adapts a function receiving the decoded payload into a Handler.
+//
+// Example:
+//
+//	sqs.NewRoute(&sqs.Config{
+//		SQSClient: sqsClient,
+//		QueueName: "orders",
+//		Handler: loafergo.TypedHandler(func(ctx context.Context, o Order, m loafergo.Message) error {
+//			return process(ctx, o)
+//		}, loafergo.TypedHandlerWithSource(loafergo.DecodeEnvelope)),
+//	})
+func TypedHandler[T any](fn func(context.Context, T, Message) error, optFns ...func(*TypedHandlerConfig)) Handler {
+	cfg := &TypedHandlerConfig{
+		source:      DecodeBody,
+		undecodable: DeadLetterUndecodable,
+	}
+	for _, optFn := range optFns {
+		optFn(cfg)
+	}
+
+	return func(ctx context.Context, msg Message) error {
+		var payload T
+
+		var err error
+		if cfg.source == DecodeEnvelope {
+			err = msg.DecodeMessage(&payload)
+		} else {
+			err = msg.Decode(&payload)
+		}
+		if err != nil {
+			return cfg.undecodable(ctx, msg, err)
+		}
+
+		return fn(ctx, payload, msg)
+	}
+}
@@ -0,0 +1,112 @@
+package loafergo_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	loafergo "github.com/justcodes/loafer-go/v2"
+	"github.com/justcodes/loafer-go/v2/fake"
+)
+
+type order struct {
+	ID string `json:"id"`
+}
+
+func decodeInto(data string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		_ = json.Unmarshal([]byte(data), args.Get(0))
+	}
+}
+
+func TestTypedHandler(t *testing.T) {
+	t.Run("Should decode the body", func(t *testing.T) {
+		message := new(fake.Message)
+		message.On("Decode", mock.Anything).Run(decodeInto(`{"id":"42"}`)).Return(nil).Once()
+
+		var got order
+		h := loafergo.TypedHandler(func(ctx context.Context, o order, m loafergo.Message) error {
+			got = o
+			return nil
+		})
+
+		assert.NoError(t, h(context.Background(), message))
+		assert.Equal(t, order{ID: "42"}, got)
+		message.AssertExpectations(t)
+	})
+
+	t.Run("Should decode the envelope message", func(t *testing.T) {
+		message := new(fake.Message)
+		message.On("DecodeMessage", mock.Anything).Run(decodeInto(`{"id":"7"}`)).Return(nil).Once()
+
+		var got order
+		h := loafergo.TypedHandler(func(ctx context.Context, o order, m loafergo.Message) error {
+			got = o
+			return nil
+		}, loafergo.TypedHandlerWithSource(loafergo.DecodeEnvelope))
+
+		assert.NoError(t, h(context.Background(), message))
+		assert.Equal(t, order{ID: "7"}, got)
+		message.AssertExpectations(t)
+	})
+
+	t.Run("Should return the handler error", func(t *testing.T) {
+		message := new(fake.Message)
+		message.On("Decode", mock.Anything).Return(nil).Once()
+
+		h := loafergo.TypedHandler(func(ctx context.Context, o order, m loafergo.Message) error {
+			return errors.New("handler error")
+		})
+
+		assert.EqualError(t, h(context.Background(), message), "handler error")
+	})
+
+	t.Run("Should dead-letter by default", func(t *testing.T) {
+		message := new(fake.Message)
+		message.On("Decode", mock.Anything).Return(errors.New("invalid character")).Once()
+
+		h := loafergo.TypedHandler(func(ctx context.Context, o order, m loafergo.Message) error {
+			t.Fatal("handler must not be called")
+			return nil
+		})
+
+		outcome := loafergo.OutcomeOf(h(context.Background(), message))
+		assert.Equal(t, loafergo.OutcomeDeadLetter, outcome.Kind())
+		assert.Equal(t, "unable to decode message: invalid character", outcome.Reason())
+	})
+
+	t.Run("Should retry when configured to", func(t *testing.T) {
+		message := new(fake.Message)
+		message.On("Decode", mock.Anything).Return(errors.New("invalid character")).Once()
+
+		h := loafergo.TypedHandler(func(ctx context.Context, o order, m loafergo.Message) error {
+			t.Fatal("handler must not be called")
+			return nil
+		}, loafergo.TypedHandlerWithUndecodable(loafergo.RetryUndecodable))
+
+		err := h(context.Background(), message)
+		assert.ErrorIs(t, err, loafergo.ErrUndecodable)
+		assert.Equal(t, loafergo.OutcomeNack, loafergo.OutcomeOf(err).Kind())
+	})
+
+	t.Run("Should call the undecodable function", func(t *testing.T) {
+		message := new(fake.Message)
+		message.On("Decode", mock.Anything).Return(errors.New("invalid character")).Once()
+
+		var undecodable error
+		h := loafergo.TypedHandler(func(ctx context.Context, o order, m loafergo.Message) error {
+			t.Fatal("handler must not be called")
+			return nil
+		}, loafergo.TypedHandlerWithUndecodable(func(ctx context.Context, m loafergo.Message, err error) error {
+			undecodable = err
+			return nil
+		}))
+
+		assert.NoError(t, h(context.Background(), message))
+		assert.EqualError(t, undecodable, "invalid character")
+	})
+}